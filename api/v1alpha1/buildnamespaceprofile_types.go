@@ -0,0 +1,161 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Namespaces",type=integer,JSONPath=`.status.onboardedNamespaces`
+
+// BuildNamespaceProfile describes the standard build setup created in every namespace matching a
+// label selector.
+type BuildNamespaceProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BuildNamespaceProfileSpec   `json:"spec,omitempty"`
+	Status BuildNamespaceProfileStatus `json:"status,omitempty"`
+}
+
+// BuildNamespaceProfileSpec defines the build setup of the selected namespaces
+type BuildNamespaceProfileSpec struct {
+
+	// NamespaceSelector selects the namespaces to onboard. Objects created in a namespace are
+	// removed when it stops matching. An empty selector matches every namespace.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// ServiceAccount is the name of the ServiceAccount running the builds of the namespace.
+	//
+	// +kubebuilder:default="shipwright-builder"
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+
+	// Editors lists the users, groups, and service accounts bound to the Shipwright aggregate edit
+	// role in the namespace.
+	//
+	// +optional
+	Editors []rbacv1.Subject `json:"editors,omitempty"`
+
+	// Viewers lists the users, groups, and service accounts bound to the Shipwright aggregate view
+	// role in the namespace.
+	//
+	// +optional
+	Viewers []rbacv1.Subject `json:"viewers,omitempty"`
+
+	// PodSecurity is the Pod Security level enforced, audited, and warned about in the namespace.
+	// The labels of the namespace are left untouched when not set.
+	//
+	// +optional
+	PodSecurity PodSecurityLevel `json:"podSecurity,omitempty"`
+
+	// Limits defines the LimitRange applied to the containers of the build pods.
+	//
+	// +optional
+	Limits *BuildLimits `json:"limits,omitempty"`
+
+	// Quota defines the hard limits of the ResourceQuota of the namespace.
+	//
+	// +optional
+	Quota corev1.ResourceList `json:"quota,omitempty"`
+
+	// InternalRegistry defines whether the ServiceAccount is allowed to push to the OpenShift
+	// internal registry with a push secret managed by the operator.
+	//
+	// +optional
+	InternalRegistry *InternalRegistry `json:"internalRegistry,omitempty"`
+
+	// Objects lists Builds and BuildStrategies created in every namespace. Their namespace is set
+	// to the onboarded namespace.
+	//
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:EmbeddedResource
+	// +optional
+	Objects []runtime.RawExtension `json:"objects,omitempty"`
+}
+
+// InternalRegistry defines the push credentials of the OpenShift internal registry
+type InternalRegistry struct {
+
+	// State defines whether the ServiceAccount is granted push rights on the image streams of the
+	// namespace, and a push secret is linked to it. Builds whose output targets the internal
+	// registry use the push secret by default. Must be one of Enabled or Disabled.
+	//
+	// +kubebuilder:default="Disabled"
+	State `json:"state"`
+}
+
+// PodSecurityLevel is a level of the Pod Security Standards
+// +kubebuilder:validation:Enum="privileged";"baseline";"restricted"
+type PodSecurityLevel string
+
+// BuildLimits defines the LimitRange of build containers
+type BuildLimits struct {
+
+	// DefaultRequests are the resources requested by containers which do not set requests.
+	//
+	// +optional
+	DefaultRequests corev1.ResourceList `json:"defaultRequests,omitempty"`
+
+	// DefaultLimits are the resource limits of containers which do not set limits.
+	//
+	// +optional
+	DefaultLimits corev1.ResourceList `json:"defaultLimits,omitempty"`
+
+	// Max are the maximum resource limits of a container.
+	//
+	// +optional
+	Max corev1.ResourceList `json:"max,omitempty"`
+}
+
+// BuildNamespaceProfileStatus defines the observed state of BuildNamespaceProfile
+type BuildNamespaceProfileStatus struct {
+
+	// Conditions holds the latest available observations of a resource's current state.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// OnboardedNamespaces is the number of namespaces set up by the profile.
+	//
+	// +optional
+	OnboardedNamespaces int32 `json:"onboardedNamespaces,omitempty"`
+
+	// Namespaces lists the namespaces set up by the profile.
+	//
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BuildNamespaceProfileList contains a list of BuildNamespaceProfile
+type BuildNamespaceProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BuildNamespaceProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BuildNamespaceProfile{}, &BuildNamespaceProfileList{})
+}
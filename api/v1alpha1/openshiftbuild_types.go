@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -25,6 +27,15 @@ import (
 const (
 	// ConditionReady object is providing service.
 	ConditionReady = "Ready"
+
+	// ConditionDegraded object is providing service with reduced availability.
+	ConditionDegraded = "Degraded"
+
+	// ConditionConflict object collides with objects managed by another installation.
+	ConditionConflict = "Conflict"
+
+	// ConditionStrategiesInUse object keeps deprecated strategies still referenced by Builds.
+	ConditionStrategiesInUse = "StrategiesInUse"
 )
 
 // State defines the desired state of a component
@@ -56,6 +67,16 @@ type OpenShiftBuild struct {
 // OpenShiftBuildSpec defines the desired state of Builds for OpenShift components.
 type OpenShiftBuildSpec struct {
 
+	// TargetNamespace is the namespace where the Shipwright Build and Shared Resource CSI Driver
+	// components are installed. Defaults to the namespace of the operator. It cannot be changed
+	// once set.
+	//
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="targetNamespace is immutable"
+	// +optional
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+
 	// Shipwright defines the desired state of Shipwright components.
 	//
 	// +kubebuilder:validation:Optional
@@ -67,6 +88,167 @@ type OpenShiftBuildSpec struct {
 	// +kubebuilder:validation:Optional
 	// +optional
 	SharedResource *SharedResource `json:"sharedResource,omitempty"`
+
+	// NetworkPolicies defines the NetworkPolicies the operator applies in the target namespace to
+	// only allow the traffic required by the components.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	NetworkPolicies *NetworkPolicies `json:"networkPolicies,omitempty"`
+
+	// BuildDefaults defines the cluster-wide defaults and overrides applied to Shipwright Builds and
+	// BuildRuns when they are created or updated.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	BuildDefaults *BuildDefaults `json:"buildDefaults,omitempty"`
+}
+
+// BuildDefaults defines the values set on Builds and BuildRuns which do not set them. Builds and
+// BuildRuns may set their own values, except for the overrides.
+type BuildDefaults struct {
+
+	// Timeout is the timeout of the Builds which do not set one.
+	//
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// ServiceAccount is the ServiceAccount running the BuildRuns which do not set one.
+	//
+	// +optional
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+
+	// NodeSelector is added to the node selector of the build pods. Keys set by the Build are kept.
+	//
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are added to the build pods, unless the Build sets a toleration with the same key.
+	//
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Env are the environment variables added to the build steps, unless the Build sets a variable
+	// with the same name.
+	//
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Retention is the retention of the BuildRuns of the Builds which do not set it.
+	//
+	// +optional
+	Retention *BuildRetention `json:"retention,omitempty"`
+
+	// ImageLabels are added to the labels of the output image, unless the Build sets a label with
+	// the same name.
+	//
+	// +optional
+	ImageLabels map[string]string `json:"imageLabels,omitempty"`
+
+	// Overrides are applied to every Build and BuildRun, replacing the values they set.
+	//
+	// +optional
+	Overrides *BuildOverrides `json:"overrides,omitempty"`
+}
+
+// BuildRetention defines how long and how many completed BuildRuns of a Build are kept
+type BuildRetention struct {
+
+	// FailedLimit is the number of failed BuildRuns kept per Build.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10000
+	// +optional
+	FailedLimit *int32 `json:"failedLimit,omitempty"`
+
+	// SucceededLimit is the number of succeeded BuildRuns kept per Build.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10000
+	// +optional
+	SucceededLimit *int32 `json:"succeededLimit,omitempty"`
+
+	// TTLAfterFailed is the time a failed BuildRun is kept.
+	//
+	// +optional
+	TTLAfterFailed *metav1.Duration `json:"ttlAfterFailed,omitempty"`
+
+	// TTLAfterSucceeded is the time a succeeded BuildRun is kept.
+	//
+	// +optional
+	TTLAfterSucceeded *metav1.Duration `json:"ttlAfterSucceeded,omitempty"`
+}
+
+// BuildOverrides defines the values enforced on every Build and BuildRun
+type BuildOverrides struct {
+
+	// NodeSelector is merged into the node selector of the build pods, replacing the values of the
+	// same keys.
+	//
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are added to the build pods, replacing the tolerations with the same key.
+	//
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// ImageLabels are set on the output image, replacing the labels with the same name.
+	//
+	// +optional
+	ImageLabels map[string]string `json:"imageLabels,omitempty"`
+}
+
+// NetworkPolicies defines the NetworkPolicies of the target namespace
+type NetworkPolicies struct {
+
+	// State defines whether the NetworkPolicies are applied. When Enabled, traffic to and from the
+	// pods of the target namespace is denied unless allowed by one of the flows. Must be one of
+	// Enabled or Disabled.
+	//
+	// +kubebuilder:default="Disabled"
+	State `json:"state"`
+
+	// Webhooks defines the flow from the API server to the admission webhooks of the operator,
+	// Shipwright Build, and the Shared Resource CSI Driver. Defaults to the host network.
+	//
+	// +optional
+	Webhooks *NetworkFlow `json:"webhooks,omitempty"`
+
+	// Metrics defines the flow from Prometheus to the metrics ports of the operator, the Shipwright
+	// Build controller, and the Shared Resource CSI Driver node plugin. Defaults to the
+	// openshift-monitoring namespace.
+	//
+	// +optional
+	Metrics *NetworkFlow `json:"metrics,omitempty"`
+
+	// Kubelet defines the flow from the kubelet to the health port of the Shared Resource CSI
+	// Driver node plugin. Defaults to the host network.
+	//
+	// +optional
+	Kubelet *NetworkFlow `json:"kubelet,omitempty"`
+
+	// APIServer defines the flow from every pod of the target namespace to the API server and to
+	// the cluster DNS. Defaults to any destination on the API server and DNS ports.
+	//
+	// +optional
+	APIServer *NetworkFlow `json:"apiServer,omitempty"`
+}
+
+// NetworkFlow defines a flow allowed by the NetworkPolicies
+type NetworkFlow struct {
+
+	// State defines whether the flow is allowed. Must be one of Enabled or Disabled.
+	//
+	// +kubebuilder:default="Enabled"
+	// +optional
+	State State `json:"state,omitempty"`
+
+	// Peers replaces the default sources of an ingress flow, or the default destinations of an
+	// egress flow.
+	//
+	// +optional
+	Peers []networkingv1.NetworkPolicyPeer `json:"peers,omitempty"`
 }
 
 // Shipwright defines the desired state of Shipwright components
@@ -87,8 +269,362 @@ type ShipwrightBuild struct {
 	//
 	// +kubebuilder:default="Enabled"
 	State `json:"state"`
+
+	// ExistingInstallPolicy defines how Shipwright Build installations managed by another party,
+	// such as the community Shipwright operator, are handled. Must be one of Refuse or Adopt.
+	//
+	// +kubebuilder:default="Refuse"
+	// +optional
+	ExistingInstallPolicy ExistingInstallPolicy `json:"existingInstallPolicy,omitempty"`
+
+	// Concurrency limits the number of BuildRuns running at the same time in the cluster and in
+	// each namespace. BuildRuns over the limits are queued until running ones complete.
+	//
+	// +optional
+	Concurrency *BuildRunConcurrency `json:"concurrency,omitempty"`
+
+	// NamespacedStrategies selects the namespaces where the ClusterBuildStrategies managed by the
+	// operator are replicated as BuildStrategies, for tenants who may not reference cluster scoped
+	// strategies. Copies are kept in sync and are removed from namespaces which stop matching. An
+	// empty selector matches every namespace.
+	//
+	// +optional
+	NamespacedStrategies *metav1.LabelSelector `json:"namespacedStrategies,omitempty"`
+
+	// OutputPolicy restricts the registries the output images of Builds and BuildRuns are pushed
+	// to. Builds and BuildRuns violating it are rejected on admission.
+	//
+	// +optional
+	OutputPolicy *BuildOutputPolicy `json:"outputPolicy,omitempty"`
+
+	// Pruner defines the periodic removal of the completed BuildRuns of every namespace, together
+	// with their TaskRuns and pods. Namespaces may override it with annotations.
+	//
+	// +optional
+	Pruner *BuildRunPruner `json:"pruner,omitempty"`
+
+	// Strategies selects the ClusterBuildStrategies installed from the catalog shipped with the
+	// operator and from bundles supplied by cluster admins. Disabled strategies are removed from
+	// the cluster.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Strategies *BuildStrategies `json:"strategies,omitempty"`
+
+	// StrategyDefaults overrides the default values of the parameters of the strategies, keyed by
+	// strategy name. Every parameter must be declared by the strategy with the matching type.
+	// The pinned versions of the strategies keep the defaults they are shipped with.
+	//
+	// +optional
+	StrategyDefaults map[string][]ParameterDefault `json:"strategyDefaults,omitempty"`
+
+	// StrategyPolicy defines the security rules enforced on the ClusterBuildStrategies and
+	// BuildStrategies created by users and on the strategy bundles. Steps can never run privileged
+	// and strategies can never mount host paths.
+	//
+	// +optional
+	StrategyPolicy *StrategyPolicy `json:"strategyPolicy,omitempty"`
+
+	// UserNamespaces defines whether the buildah-userns strategy is installed, together with a
+	// dedicated SecurityContextConstraints allowing its builds to run in a user namespace, so build
+	// service accounts need neither the privileged nor the anyuid SCC.
+	//
+	// +optional
+	UserNamespaces *UserNamespaces `json:"userNamespaces,omitempty"`
+}
+
+// BuildOutputPolicy defines the registries the output images of Builds and BuildRuns are pushed to
+type BuildOutputPolicy struct {
+
+	// AllowedRegistries lists the registries, optionally followed by a repository path, that output
+	// images must be pushed to, such as quay.io/my-org. Images without registry are pushed to
+	// docker.io. Any registry is allowed when empty.
+	//
+	// +listType=set
+	// +optional
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+
+	// Exceptions allows the namespaces matching a selector to push to additional registries.
+	//
+	// +listType=atomic
+	// +optional
+	Exceptions []BuildOutputPolicyException `json:"exceptions,omitempty"`
+}
+
+// BuildOutputPolicyException defines the additional registries allowed in some namespaces
+type BuildOutputPolicyException struct {
+
+	// NamespaceSelector selects the namespaces of the exception. An empty selector matches every
+	// namespace.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// AllowedRegistries lists the registries the output images of the namespaces may be pushed to,
+	// in addition to the ones of the policy. Any registry is allowed when empty.
+	//
+	// +listType=set
+	// +optional
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+}
+
+// BuildRunPruner defines which completed BuildRuns are deleted. A BuildRun is deleted when it is
+// older than MaxAge, or when more recent BuildRuns of the same Build exceed the limits.
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.keep) && (has(self.keepSucceeded) || has(self.keepFailed)))",message="keep cannot be combined with keepSucceeded or keepFailed"
+type BuildRunPruner struct {
+
+	// State defines whether completed BuildRuns are pruned. Must be one of Enabled or Disabled.
+	//
+	// +kubebuilder:default="Disabled"
+	State `json:"state"`
+
+	// Interval is the time between two pruning passes. Defaults to 1h.
+	//
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// MaxAge is the time a BuildRun is kept after its completion.
+	//
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+
+	// Keep is the number of completed BuildRuns kept per Build.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Keep *int32 `json:"keep,omitempty"`
+
+	// KeepSucceeded is the number of succeeded BuildRuns kept per Build.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepSucceeded *int32 `json:"keepSucceeded,omitempty"`
+
+	// KeepFailed is the number of failed BuildRuns kept per Build.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepFailed *int32 `json:"keepFailed,omitempty"`
+}
+
+// BuildRunConcurrency defines the limits on the BuildRuns running at the same time. The pods of
+// new BuildRuns are created with a scheduling gate, which is removed once they fit in the limits.
+// Queued BuildRuns are released by priority tier, then evenly across the namespaces of a tier.
+type BuildRunConcurrency struct {
+
+	// State defines whether BuildRuns are queued. Must be one of Enabled or Disabled.
+	//
+	// +kubebuilder:default="Disabled"
+	State `json:"state"`
+
+	// MaxRunning is the number of BuildRuns running at the same time in the cluster. Unlimited
+	// when not set.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxRunning *int32 `json:"maxRunning,omitempty"`
+
+	// MaxRunningPerNamespace is the number of BuildRuns running at the same time in a namespace.
+	// Unlimited when not set. Namespaces may override it with an annotation.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxRunningPerNamespace *int32 `json:"maxRunningPerNamespace,omitempty"`
+
+	// PriorityTiers defines the priority of the BuildRuns of the namespaces matching a tier, from
+	// the highest to the lowest. Namespaces matching several tiers belong to the first one, and
+	// namespaces matching none belong to an implicit tier after the last one.
+	//
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	PriorityTiers []BuildRunPriorityTier `json:"priorityTiers,omitempty"`
+
+	// QueueTimeout is the time a BuildRun may wait in the queue. It is added to the timeout of the
+	// BuildRuns created while the limits are enabled, so that the time spent in the queue does not
+	// count toward the build time. Defaults to 1h.
+	//
+	// +optional
+	QueueTimeout *metav1.Duration `json:"queueTimeout,omitempty"`
 }
 
+// BuildRunPriorityTier defines a group of namespaces whose queued BuildRuns are released together
+type BuildRunPriorityTier struct {
+
+	// Name of the priority tier
+	//
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// NamespaceSelector selects the namespaces of the tier. An empty selector matches every
+	// namespace.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+}
+
+// UserNamespaces defines the desired state of builds running in a user namespace
+// +kubebuilder:validation:XValidation:rule="self.state != 'Enabled' || has(self.namespaces)",message="namespaces is required when state is Enabled"
+type UserNamespaces struct {
+
+	// State defines whether the buildah-userns strategy and its SecurityContextConstraints are
+	// installed. Must be one of Enabled or Disabled.
+	//
+	// +kubebuilder:default="Disabled"
+	State `json:"state"`
+
+	// Namespaces selects the namespaces whose build service accounts are allowed to use the
+	// SecurityContextConstraints. Required when State is Enabled.
+	//
+	// +optional
+	Namespaces *metav1.LabelSelector `json:"namespaces,omitempty"`
+
+	// ServiceAccounts lists the build service accounts of the selected namespaces allowed to use the
+	// SecurityContextConstraints. Defaults to the pipeline and shipwright-builder service accounts.
+	//
+	// +listType=set
+	// +optional
+	ServiceAccounts []string `json:"serviceAccounts,omitempty"`
+}
+
+// StrategyPolicy defines the security rules enforced on build strategies
+type StrategyPolicy struct {
+
+	// AllowedRegistries lists the registries, optionally followed by a repository path, that step
+	// images must be pulled from, such as registry.redhat.io or quay.io/my-org. When set, step
+	// images set from a parameter are rejected. Any registry is allowed when empty.
+	//
+	// +listType=set
+	// +optional
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+
+	// AllowedCapabilities lists the Linux capabilities steps may add. Defaults to SETFCAP, which is
+	// added by the buildah strategies.
+	//
+	// +listType=set
+	// +optional
+	AllowedCapabilities []string `json:"allowedCapabilities,omitempty"`
+
+	// RequireResourceLimits requires every step to set cpu and memory limits.
+	//
+	// +optional
+	RequireResourceLimits bool `json:"requireResourceLimits,omitempty"`
+}
+
+// ParameterDefault defines the default value of a build strategy parameter
+// +kubebuilder:validation:XValidation:rule="has(self.value) != has(self.values)",message="exactly one of value or values must be set"
+type ParameterDefault struct {
+
+	// Name is the name of the parameter.
+	//
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Value is the default value of a string parameter.
+	//
+	// +optional
+	Value *string `json:"value,omitempty"`
+
+	// Values is the default value of an array parameter.
+	//
+	// +optional
+	Values []string `json:"values,omitempty"`
+}
+
+// BuildStrategies defines the state of each ClusterBuildStrategy of the catalog
+type BuildStrategies struct {
+
+	// Buildah builds images from a Dockerfile with buildah.
+	//
+	// +kubebuilder:default="Enabled"
+	// +optional
+	Buildah State `json:"buildah,omitempty"`
+
+	// SourceToImage builds images from source code with a source-to-image builder image.
+	//
+	// +kubebuilder:default="Enabled"
+	// +optional
+	SourceToImage State `json:"sourceToImage,omitempty"`
+
+	// BuildahCache builds images from a Dockerfile with buildah, caching the layers of every
+	// stage in a registry next to the output image.
+	//
+	// +kubebuilder:default="Disabled"
+	// +optional
+	BuildahCache State `json:"buildahCache,omitempty"`
+
+	// BuildahRootless builds images from a Dockerfile with buildah running as a non-root user
+	// without additional capabilities.
+	//
+	// +kubebuilder:default="Disabled"
+	// +optional
+	BuildahRootless State `json:"buildahRootless,omitempty"`
+
+	// Bundles lists ConfigMaps in the target namespace holding additional ClusterBuildStrategies
+	// to install. Every key of a ConfigMap holds one or more YAML documents. Strategies are
+	// validated and applied like the strategies of the catalog.
+	//
+	// +listType=map
+	// +listMapKey=configMap
+	// +optional
+	Bundles []StrategyBundle `json:"bundles,omitempty"`
+
+	// RemovalPolicy defines how strategies still referenced by Builds are handled when they are
+	// disabled, removed from a bundle, or dropped from the catalog by an upgrade. Block keeps them
+	// until no Build references them. Deprecate keeps them for the DeprecationPeriod and removes
+	// them afterwards. Must be one of Block or Deprecate.
+	//
+	// +kubebuilder:default="Block"
+	// +optional
+	RemovalPolicy StrategyRemovalPolicy `json:"removalPolicy,omitempty"`
+
+	// DeprecationPeriod defines how long deprecated strategies referenced by Builds are kept when
+	// the RemovalPolicy is Deprecate. Defaults to 168h.
+	//
+	// +optional
+	DeprecationPeriod *metav1.Duration `json:"deprecationPeriod,omitempty"`
+
+	// RetainedVersions is the number of previous versions of every strategy of the catalog kept
+	// after an operator upgrade. Builds pinned to a versioned strategy, such as buildah-1-1, keep
+	// building with it across upgrades. Defaults to 2.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RetainedVersions *int32 `json:"retainedVersions,omitempty"`
+}
+
+// StrategyRemovalPolicy defines how strategies referenced by Builds are removed
+// +kubebuilder:validation:Enum="Block";"Deprecate"
+type StrategyRemovalPolicy string
+
+const (
+	// Block keeps strategies referenced by Builds.
+	Block StrategyRemovalPolicy = "Block"
+
+	// Deprecate removes strategies referenced by Builds at the end of the deprecation period.
+	Deprecate StrategyRemovalPolicy = "Deprecate"
+)
+
+// StrategyBundle references a ConfigMap holding ClusterBuildStrategies
+type StrategyBundle struct {
+
+	// ConfigMap is the name of the ConfigMap in the target namespace.
+	//
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	ConfigMap string `json:"configMap"`
+}
+
+// ExistingInstallPolicy defines how objects of a component installed by another party are handled
+// +kubebuilder:validation:Enum="Refuse";"Adopt"
+type ExistingInstallPolicy string
+
+const (
+	// Refuse leaves the objects of the existing installation untouched and reports a Conflict.
+	Refuse ExistingInstallPolicy = "Refuse"
+
+	// Adopt takes ownership of the objects of the existing installation. Existing custom resources
+	// are preserved.
+	Adopt ExistingInstallPolicy = "Adopt"
+)
+
 // SharedResource defines the desired state of Shared Resource CSI Driver and components.
 type SharedResource struct {
 
@@ -97,6 +633,72 @@ type SharedResource struct {
 	//
 	// +kubebuilder:default="Enabled"
 	State `json:"state"`
+
+	// ExistingInstallPolicy defines how a Shared Resource CSI Driver installed by another party,
+	// such as the OpenShift tech preview driver, is handled. Must be one of Refuse or Adopt.
+	//
+	// +kubebuilder:default="Refuse"
+	// +optional
+	ExistingInstallPolicy ExistingInstallPolicy `json:"existingInstallPolicy,omitempty"`
+
+	// ClusterShares defines which cluster-wide resources are shared with builds. The cluster RHEL
+	// entitlement and trusted CA bundle are always shared. The use of the entitlement and pull
+	// secret shares is granted per namespace.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	ClusterShares *ClusterShares `json:"clusterShares,omitempty"`
+
+	// Config defines the configuration of the Shared Resource CSI Driver. The node plugin is
+	// restarted when the configuration changes.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Config *SharedResourceConfig `json:"config,omitempty"`
+}
+
+// SharedResourceConfig defines the configuration of the Shared Resource CSI Driver
+type SharedResourceConfig struct {
+
+	// IgnoredNamespaces lists namespaces, in addition to the OpenShift platform namespaces, whose
+	// shared resources are not watched by the driver.
+	//
+	// +kubebuilder:validation:items:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:items:MaxLength=63
+	// +listType=set
+	// +optional
+	IgnoredNamespaces []string `json:"ignoredNamespaces,omitempty"`
+
+	// RefreshPeriod defines how often the driver refreshes the data of mounted shared resources.
+	// Must be at least one minute.
+	//
+	// +optional
+	RefreshPeriod *metav1.Duration `json:"refreshPeriod,omitempty"`
+
+	// RefreshResources defines whether the driver watches shared resources and refreshes mounted
+	// data when they change. When false, data is only read when the volume is mounted. Defaults
+	// to true.
+	//
+	// +optional
+	RefreshResources *bool `json:"refreshResources,omitempty"`
+
+	// ShareRelistInterval defines how often the driver lists every SharedConfigMap and
+	// SharedSecret. Must be at least one minute. Defaults to 10m.
+	//
+	// +optional
+	ShareRelistInterval *metav1.Duration `json:"shareRelistInterval,omitempty"`
+}
+
+// ClusterShares defines the optional cluster-wide resources shared with builds
+type ClusterShares struct {
+
+	// PullSecret defines whether the cluster global pull secret is shared with builds. Only the
+	// service accounts granted the use of the share in their namespace may mount it. Must be one of
+	// Enabled or Disabled.
+	//
+	// +kubebuilder:default="Disabled"
+	// +optional
+	PullSecret State `json:"pullSecret,omitempty"`
 }
 
 // OpenShiftBuildStatus defines the observed state of OpenShiftBuild
@@ -104,6 +706,166 @@ type OpenShiftBuildStatus struct {
 
 	// Conditions holds the latest available observations of a resource's current state.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// SharedResource holds the observed state of the Shared Resource CSI Driver.
+	//
+	// +optional
+	SharedResource *SharedResourceStatus `json:"sharedResource,omitempty"`
+
+	// Strategies holds the observed state of the ClusterBuildStrategies of the catalog and of
+	// the strategy bundles.
+	//
+	// +optional
+	Strategies []StrategyStatus `json:"strategies,omitempty"`
+
+	// UserNamespaces holds the observed state of builds running in a user namespace.
+	//
+	// +optional
+	UserNamespaces *UserNamespacesStatus `json:"userNamespaces,omitempty"`
+
+	// Compliance reports whether every operand Deployment and DaemonSet meets the restricted Pod
+	// Security profile, and why the exceptions cannot.
+	//
+	// +optional
+	Compliance []WorkloadCompliance `json:"compliance,omitempty"`
+}
+
+// ComplianceState is the observed compliance of an operand workload with the restricted Pod
+// Security profile
+// +kubebuilder:validation:Enum="Compliant";"Exception";"NonCompliant"
+type ComplianceState string
+
+const (
+	// Compliant means the workload meets the restricted Pod Security profile.
+	Compliant ComplianceState = "Compliant"
+
+	// ComplianceException means the workload cannot meet the restricted Pod Security profile
+	// for a documented reason.
+	ComplianceException ComplianceState = "Exception"
+
+	// NonCompliant means the workload does not meet the restricted Pod Security profile.
+	NonCompliant ComplianceState = "NonCompliant"
+)
+
+// WorkloadCompliance defines the observed compliance of an operand workload
+type WorkloadCompliance struct {
+
+	// Kind is the kind of the workload, Deployment or DaemonSet.
+	Kind string `json:"kind"`
+
+	// Name is the name of the workload.
+	Name string `json:"name"`
+
+	// State is the observed compliance of the workload.
+	State ComplianceState `json:"state"`
+
+	// Message gives the justification of an exception, or the violations of a non compliant
+	// workload.
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// UserNamespacesStatus defines the observed state of builds running in a user namespace
+type UserNamespacesStatus struct {
+
+	// SecurityContextConstraints is the name of the SCC created for the builds running in a user
+	// namespace.
+	SecurityContextConstraints string `json:"securityContextConstraints"`
+
+	// Namespaces lists the namespaces whose build service accounts are allowed to use the SCC.
+	//
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// StrategyState is the observed state of a ClusterBuildStrategy
+// +kubebuilder:validation:Enum="Applied";"Invalid";"Conflict";"Deprecated"
+type StrategyState string
+
+const (
+	// StrategyApplied means the strategy is installed and managed by the operator.
+	StrategyApplied StrategyState = "Applied"
+
+	// StrategyInvalid means the strategy was rejected by validation and is not installed.
+	StrategyInvalid StrategyState = "Invalid"
+
+	// StrategyConflict means a strategy with the same name is managed by another party and is
+	// left untouched.
+	StrategyConflict StrategyState = "Conflict"
+
+	// StrategyDeprecated means the strategy is no longer desired but is kept because Builds
+	// reference it.
+	StrategyDeprecated StrategyState = "Deprecated"
+)
+
+// StrategyStatus defines the observed state of a ClusterBuildStrategy
+type StrategyStatus struct {
+
+	// Name is the name of the ClusterBuildStrategy. Empty when a bundle cannot be read.
+	//
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace is the namespace of a namespaced copy of the strategy, which is only reported
+	// while the copy is kept for the Builds referencing it.
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Source is "Catalog" for the strategies shipped with the operator, or the name of the
+	// bundle ConfigMap.
+	Source string `json:"source"`
+
+	// State is the observed state of the strategy.
+	State StrategyState `json:"state"`
+
+	// Version is the version of a strategy of the catalog.
+	//
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Builds is the number of Builds referencing the strategy.
+	//
+	// +optional
+	Builds int32 `json:"builds,omitempty"`
+
+	// Message is a human readable description of the state.
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// SharedResourceStatus defines the observed state of the Shared Resource CSI Driver
+type SharedResourceStatus struct {
+
+	// ExpectedNodes is the number of schedulable nodes the CSI driver node plugin should run on.
+	ExpectedNodes int32 `json:"expectedNodes"`
+
+	// RegisteredNodes is the number of nodes where the CSI driver is registered and the node
+	// plugin is running.
+	RegisteredNodes int32 `json:"registeredNodes"`
+
+	// UnhealthyNodes lists the nodes where the CSI driver is not registered or the node plugin is
+	// not running. Volumes using shared resources cannot be mounted on these nodes.
+	//
+	// +optional
+	UnhealthyNodes []NodeStatus `json:"unhealthyNodes,omitempty"`
+}
+
+// NodeStatus defines the observed state of the CSI driver on a node
+type NodeStatus struct {
+
+	// Name is the name of the node.
+	Name string `json:"name"`
+
+	// Reason is a brief CamelCase reason for the node being unhealthy.
+	Reason string `json:"reason"`
+
+	// Message is a human readable description of the node state.
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -21,10 +21,73 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildStrategies) DeepCopyInto(out *BuildStrategies) {
+	*out = *in
+	if in.Bundles != nil {
+		in, out := &in.Bundles, &out.Bundles
+		*out = make([]StrategyBundle, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeprecationPeriod != nil {
+		in, out := &in.DeprecationPeriod, &out.DeprecationPeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RetainedVersions != nil {
+		in, out := &in.RetainedVersions, &out.RetainedVersions
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildStrategies.
+func (in *BuildStrategies) DeepCopy() *BuildStrategies {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildStrategies)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterShares) DeepCopyInto(out *ClusterShares) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterShares.
+func (in *ClusterShares) DeepCopy() *ClusterShares {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterShares)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeStatus) DeepCopyInto(out *NodeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeStatus.
+func (in *NodeStatus) DeepCopy() *NodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpenShiftBuild) DeepCopyInto(out *OpenShiftBuild) {
 	*out = *in
@@ -95,7 +158,17 @@ func (in *OpenShiftBuildSpec) DeepCopyInto(out *OpenShiftBuildSpec) {
 	if in.SharedResource != nil {
 		in, out := &in.SharedResource, &out.SharedResource
 		*out = new(SharedResource)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicies != nil {
+		in, out := &in.NetworkPolicies, &out.NetworkPolicies
+		*out = new(NetworkPolicies)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BuildDefaults != nil {
+		in, out := &in.BuildDefaults, &out.BuildDefaults
+		*out = new(BuildDefaults)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -119,6 +192,26 @@ func (in *OpenShiftBuildStatus) DeepCopyInto(out *OpenShiftBuildStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SharedResource != nil {
+		in, out := &in.SharedResource, &out.SharedResource
+		*out = new(SharedResourceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Strategies != nil {
+		in, out := &in.Strategies, &out.Strategies
+		*out = make([]StrategyStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.UserNamespaces != nil {
+		in, out := &in.UserNamespaces, &out.UserNamespaces
+		*out = new(UserNamespacesStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Compliance != nil {
+		in, out := &in.Compliance, &out.Compliance
+		*out = make([]WorkloadCompliance, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenShiftBuildStatus.
@@ -131,9 +224,44 @@ func (in *OpenShiftBuildStatus) DeepCopy() *OpenShiftBuildStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterDefault) DeepCopyInto(out *ParameterDefault) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(string)
+		**out = **in
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterDefault.
+func (in *ParameterDefault) DeepCopy() *ParameterDefault {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterDefault)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SharedResource) DeepCopyInto(out *SharedResource) {
 	*out = *in
+	if in.ClusterShares != nil {
+		in, out := &in.ClusterShares, &out.ClusterShares
+		*out = new(ClusterShares)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(SharedResourceConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedResource.
@@ -146,13 +274,68 @@ func (in *SharedResource) DeepCopy() *SharedResource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedResourceConfig) DeepCopyInto(out *SharedResourceConfig) {
+	*out = *in
+	if in.IgnoredNamespaces != nil {
+		in, out := &in.IgnoredNamespaces, &out.IgnoredNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RefreshPeriod != nil {
+		in, out := &in.RefreshPeriod, &out.RefreshPeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RefreshResources != nil {
+		in, out := &in.RefreshResources, &out.RefreshResources
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ShareRelistInterval != nil {
+		in, out := &in.ShareRelistInterval, &out.ShareRelistInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedResourceConfig.
+func (in *SharedResourceConfig) DeepCopy() *SharedResourceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedResourceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedResourceStatus) DeepCopyInto(out *SharedResourceStatus) {
+	*out = *in
+	if in.UnhealthyNodes != nil {
+		in, out := &in.UnhealthyNodes, &out.UnhealthyNodes
+		*out = make([]NodeStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedResourceStatus.
+func (in *SharedResourceStatus) DeepCopy() *SharedResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Shipwright) DeepCopyInto(out *Shipwright) {
 	*out = *in
 	if in.Build != nil {
 		in, out := &in.Build, &out.Build
 		*out = new(ShipwrightBuild)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -169,6 +352,59 @@ func (in *Shipwright) DeepCopy() *Shipwright {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ShipwrightBuild) DeepCopyInto(out *ShipwrightBuild) {
 	*out = *in
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(BuildRunConcurrency)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespacedStrategies != nil {
+		in, out := &in.NamespacedStrategies, &out.NamespacedStrategies
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OutputPolicy != nil {
+		in, out := &in.OutputPolicy, &out.OutputPolicy
+		*out = new(BuildOutputPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pruner != nil {
+		in, out := &in.Pruner, &out.Pruner
+		*out = new(BuildRunPruner)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Strategies != nil {
+		in, out := &in.Strategies, &out.Strategies
+		*out = new(BuildStrategies)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StrategyDefaults != nil {
+		in, out := &in.StrategyDefaults, &out.StrategyDefaults
+		*out = make(map[string][]ParameterDefault, len(*in))
+		for key, val := range *in {
+			var outVal []ParameterDefault
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]ParameterDefault, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.StrategyPolicy != nil {
+		in, out := &in.StrategyPolicy, &out.StrategyPolicy
+		*out = new(StrategyPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UserNamespaces != nil {
+		in, out := &in.UserNamespaces, &out.UserNamespaces
+		*out = new(UserNamespaces)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShipwrightBuild.
@@ -180,3 +416,632 @@ func (in *ShipwrightBuild) DeepCopy() *ShipwrightBuild {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StrategyBundle) DeepCopyInto(out *StrategyBundle) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StrategyBundle.
+func (in *StrategyBundle) DeepCopy() *StrategyBundle {
+	if in == nil {
+		return nil
+	}
+	out := new(StrategyBundle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StrategyPolicy) DeepCopyInto(out *StrategyPolicy) {
+	*out = *in
+	if in.AllowedRegistries != nil {
+		in, out := &in.AllowedRegistries, &out.AllowedRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedCapabilities != nil {
+		in, out := &in.AllowedCapabilities, &out.AllowedCapabilities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StrategyPolicy.
+func (in *StrategyPolicy) DeepCopy() *StrategyPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StrategyPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StrategyStatus) DeepCopyInto(out *StrategyStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StrategyStatus.
+func (in *StrategyStatus) DeepCopy() *StrategyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StrategyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserNamespaces) DeepCopyInto(out *UserNamespaces) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAccounts != nil {
+		in, out := &in.ServiceAccounts, &out.ServiceAccounts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserNamespaces.
+func (in *UserNamespaces) DeepCopy() *UserNamespaces {
+	if in == nil {
+		return nil
+	}
+	out := new(UserNamespaces)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserNamespacesStatus) DeepCopyInto(out *UserNamespacesStatus) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserNamespacesStatus.
+func (in *UserNamespacesStatus) DeepCopy() *UserNamespacesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UserNamespacesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadCompliance) DeepCopyInto(out *WorkloadCompliance) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadCompliance.
+func (in *WorkloadCompliance) DeepCopy() *WorkloadCompliance {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadCompliance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkFlow) DeepCopyInto(out *NetworkFlow) {
+	*out = *in
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkFlow.
+func (in *NetworkFlow) DeepCopy() *NetworkFlow {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkFlow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkPolicies) DeepCopyInto(out *NetworkPolicies) {
+	*out = *in
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = new(NetworkFlow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(NetworkFlow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kubelet != nil {
+		in, out := &in.Kubelet, &out.Kubelet
+		*out = new(NetworkFlow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.APIServer != nil {
+		in, out := &in.APIServer, &out.APIServer
+		*out = new(NetworkFlow)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPolicies.
+func (in *NetworkPolicies) DeepCopy() *NetworkPolicies {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkPolicies)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildLimits) DeepCopyInto(out *BuildLimits) {
+	*out = *in
+	if in.DefaultRequests != nil {
+		in, out := &in.DefaultRequests, &out.DefaultRequests
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.DefaultLimits != nil {
+		in, out := &in.DefaultLimits, &out.DefaultLimits
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildLimits.
+func (in *BuildLimits) DeepCopy() *BuildLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildNamespaceProfile) DeepCopyInto(out *BuildNamespaceProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildNamespaceProfile.
+func (in *BuildNamespaceProfile) DeepCopy() *BuildNamespaceProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildNamespaceProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BuildNamespaceProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildNamespaceProfileList) DeepCopyInto(out *BuildNamespaceProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BuildNamespaceProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildNamespaceProfileList.
+func (in *BuildNamespaceProfileList) DeepCopy() *BuildNamespaceProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildNamespaceProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BuildNamespaceProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildNamespaceProfileSpec) DeepCopyInto(out *BuildNamespaceProfileSpec) {
+	*out = *in
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	if in.Editors != nil {
+		in, out := &in.Editors, &out.Editors
+		*out = make([]rbacv1.Subject, len(*in))
+		copy(*out, *in)
+	}
+	if in.Viewers != nil {
+		in, out := &in.Viewers, &out.Viewers
+		*out = make([]rbacv1.Subject, len(*in))
+		copy(*out, *in)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(BuildLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.InternalRegistry != nil {
+		in, out := &in.InternalRegistry, &out.InternalRegistry
+		*out = new(InternalRegistry)
+		**out = **in
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildNamespaceProfileSpec.
+func (in *BuildNamespaceProfileSpec) DeepCopy() *BuildNamespaceProfileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildNamespaceProfileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildNamespaceProfileStatus) DeepCopyInto(out *BuildNamespaceProfileStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildNamespaceProfileStatus.
+func (in *BuildNamespaceProfileStatus) DeepCopy() *BuildNamespaceProfileStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildNamespaceProfileStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalRegistry) DeepCopyInto(out *InternalRegistry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalRegistry.
+func (in *InternalRegistry) DeepCopy() *InternalRegistry {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalRegistry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildDefaults) DeepCopyInto(out *BuildDefaults) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(BuildRetention)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageLabels != nil {
+		in, out := &in.ImageLabels, &out.ImageLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(BuildOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildDefaults.
+func (in *BuildDefaults) DeepCopy() *BuildDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildOverrides) DeepCopyInto(out *BuildOverrides) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImageLabels != nil {
+		in, out := &in.ImageLabels, &out.ImageLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildOverrides.
+func (in *BuildOverrides) DeepCopy() *BuildOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildRetention) DeepCopyInto(out *BuildRetention) {
+	*out = *in
+	if in.FailedLimit != nil {
+		in, out := &in.FailedLimit, &out.FailedLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SucceededLimit != nil {
+		in, out := &in.SucceededLimit, &out.SucceededLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TTLAfterFailed != nil {
+		in, out := &in.TTLAfterFailed, &out.TTLAfterFailed
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.TTLAfterSucceeded != nil {
+		in, out := &in.TTLAfterSucceeded, &out.TTLAfterSucceeded
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildRetention.
+func (in *BuildRetention) DeepCopy() *BuildRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildRunPruner) DeepCopyInto(out *BuildRunPruner) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Keep != nil {
+		in, out := &in.Keep, &out.Keep
+		*out = new(int32)
+		**out = **in
+	}
+	if in.KeepSucceeded != nil {
+		in, out := &in.KeepSucceeded, &out.KeepSucceeded
+		*out = new(int32)
+		**out = **in
+	}
+	if in.KeepFailed != nil {
+		in, out := &in.KeepFailed, &out.KeepFailed
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildRunPruner.
+func (in *BuildRunPruner) DeepCopy() *BuildRunPruner {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildRunPruner)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildRunConcurrency) DeepCopyInto(out *BuildRunConcurrency) {
+	*out = *in
+	if in.MaxRunning != nil {
+		in, out := &in.MaxRunning, &out.MaxRunning
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxRunningPerNamespace != nil {
+		in, out := &in.MaxRunningPerNamespace, &out.MaxRunningPerNamespace
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PriorityTiers != nil {
+		in, out := &in.PriorityTiers, &out.PriorityTiers
+		*out = make([]BuildRunPriorityTier, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.QueueTimeout != nil {
+		in, out := &in.QueueTimeout, &out.QueueTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildRunConcurrency.
+func (in *BuildRunConcurrency) DeepCopy() *BuildRunConcurrency {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildRunConcurrency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildRunPriorityTier) DeepCopyInto(out *BuildRunPriorityTier) {
+	*out = *in
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildRunPriorityTier.
+func (in *BuildRunPriorityTier) DeepCopy() *BuildRunPriorityTier {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildRunPriorityTier)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildOutputPolicy) DeepCopyInto(out *BuildOutputPolicy) {
+	*out = *in
+	if in.AllowedRegistries != nil {
+		in, out := &in.AllowedRegistries, &out.AllowedRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exceptions != nil {
+		in, out := &in.Exceptions, &out.Exceptions
+		*out = make([]BuildOutputPolicyException, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildOutputPolicy.
+func (in *BuildOutputPolicy) DeepCopy() *BuildOutputPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildOutputPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildOutputPolicyException) DeepCopyInto(out *BuildOutputPolicyException) {
+	*out = *in
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	if in.AllowedRegistries != nil {
+		in, out := &in.AllowedRegistries, &out.AllowedRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildOutputPolicyException.
+func (in *BuildOutputPolicyException) DeepCopy() *BuildOutputPolicyException {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildOutputPolicyException)
+	in.DeepCopyInto(out)
+	return out
+}
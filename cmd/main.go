@@ -21,6 +21,8 @@ import (
 	"flag"
 	"os"
 
+	configv1 "github.com/openshift/api/config/v1"
+	securityv1 "github.com/openshift/api/security/v1"
 	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -28,17 +30,32 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	operatorv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/buildnamespace"
 	"github.com/redhat-openshift-builds/operator/internal/common"
 	"github.com/redhat-openshift-builds/operator/internal/controller"
+	"github.com/redhat-openshift-builds/operator/internal/networkpolicy"
+	"github.com/redhat-openshift-builds/operator/internal/sharedresource"
 	shipwrightbuild "github.com/redhat-openshift-builds/operator/internal/shipwright/build"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/pruner"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/queue"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/registry"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/userns"
+	operatorwebhook "github.com/redhat-openshift-builds/operator/internal/webhook"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -49,6 +66,9 @@ var (
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+	utilruntime.Must(configv1.AddToScheme(scheme))
+	utilruntime.Must(securityv1.AddToScheme(scheme))
 
 	utilruntime.Must(operatorv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(shipwrightv1alpha1.AddToScheme(scheme))
@@ -94,13 +114,45 @@ func main() {
 		tlsOpts = append(tlsOpts, disableHTTP2)
 	}
 
+	// only the pods of BuildRuns are cached, to release the queued BuildRuns
+	buildRunPods, err := labels.NewRequirement(common.BuildRunNameLabel, selection.Exists, nil)
+	if err != nil {
+		setupLog.Error(err, "unable to select build pods")
+		os.Exit(1)
+	}
+
+	// only the Roles granting the use of shares, and the RoleBindings created by the operator, are
+	// cached
+	shareGrants, err := labels.NewRequirement(common.ShareGrantLabel, selection.Exists, nil)
+	if err != nil {
+		setupLog.Error(err, "unable to select share grants")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Pod{}:         {Label: labels.NewSelector().Add(*buildRunPods)},
+				&rbacv1.Role{}:        {Label: labels.NewSelector().Add(*shareGrants)},
+				&rbacv1.RoleBinding{}: {Label: labels.SelectorFromSet(labels.Set{common.ManagedByLabel: common.ManagedByLabelValue})},
+			},
+		},
+		// ConfigMaps are read from the API server, so that the ConfigMaps of every namespace are
+		// not cached
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				DisableFor: []client.Object{&corev1.ConfigMap{}},
+			},
+		},
 		Metrics: metricsserver.Options{
 			BindAddress:   metricsAddr,
 			SecureServing: secureMetrics,
 			TLSOpts:       tlsOpts,
 		},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			TLSOpts: tlsOpts,
+		}),
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "02e52450.openshift.io",
@@ -110,14 +162,18 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Fetch the namespace and store for later use
-	namespace := common.FetchCurrentNamespaceName()
+	// Fetch the namespace and store for later use as the default target namespace
+	common.FetchCurrentNamespaceName()
 
 	// Run OpenshiftBuild controller
 	buildReconciler := &controller.OpenShiftBuildReconciler{
-		Client:     mgr.GetClient(),
-		Scheme:     mgr.GetScheme(),
-		Shipwright: shipwrightbuild.New(mgr.GetClient(), namespace),
+		APIReader:      mgr.GetAPIReader(),
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		Shipwright:     shipwrightbuild.New(mgr.GetClient()),
+		RegistryConfig: registry.New(mgr.GetClient()),
+		UserNamespace:  userns.New(mgr.GetClient()),
+		NetworkPolicy:  networkpolicy.New(mgr.GetClient()),
 	}
 
 	if err := buildReconciler.SetupWithManager(mgr); err != nil {
@@ -136,6 +192,79 @@ func main() {
 		os.Exit(1)
 	}
 
+	profileReconciler := &controller.BuildNamespaceProfileReconciler{
+		Client:     mgr.GetClient(),
+		Onboarding: buildnamespace.New(mgr.GetClient(), mgr.GetAPIReader()),
+	}
+
+	if err := profileReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BuildNamespaceProfile")
+		os.Exit(1)
+	}
+
+	prunerReconciler := &controller.BuildRunPrunerReconciler{
+		Client: mgr.GetClient(),
+		Pruner: pruner.New(mgr.GetClient(), mgr.GetAPIReader()),
+	}
+
+	if err := prunerReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BuildRunPruner")
+		os.Exit(1)
+	}
+
+	buildRunQueue := queue.New(mgr.GetClient(), mgr.GetAPIReader())
+	buildRunQueue.Recorder = mgr.GetEventRecorderFor("openshift-builds-operator")
+	queueReconciler := &controller.BuildRunQueueReconciler{
+		Client: mgr.GetClient(),
+		Queue:  buildRunQueue,
+	}
+
+	if err := queueReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BuildRunQueue")
+		os.Exit(1)
+	}
+
+	for _, kind := range sharedresource.GrantKinds {
+		grantReconciler := &controller.ShareGrantReconciler{
+			Client: mgr.GetClient(),
+			Grant:  sharedresource.NewGrant(mgr.GetClient()),
+			Kind:   kind,
+		}
+		if err := grantReconciler.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", kind.Kind+"Grant")
+			os.Exit(1)
+		}
+	}
+
+	// Reject ShipwrightBuilds competing with the one managed by the OpenShiftBuild, and build
+	// strategies violating the strategy security policy. Default the push secret of builds
+	// targeting the internal registry. Gate the pods of BuildRuns over the concurrency limits, and
+	// reject builds pushing to registries outside the output policy. The serving certificate is
+	// created by OLM, or by the OpenShift service CA when deployed from config/default.
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err := operatorwebhook.SetupShipwrightBuildWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ShipwrightBuild")
+			os.Exit(1)
+		}
+		operatorUsername := operatorwebhook.OperatorUsername(common.CurrentNamespaceName, os.Getenv("SERVICE_ACCOUNT_NAME"))
+		if err := operatorwebhook.SetupBuildStrategyWebhookWithManager(mgr, operatorUsername); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "BuildStrategy")
+			os.Exit(1)
+		}
+		if err := operatorwebhook.SetupBuildWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Build")
+			os.Exit(1)
+		}
+		if err := operatorwebhook.SetupBuildRunPodWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "BuildRunPod")
+			os.Exit(1)
+		}
+		if err := operatorwebhook.SetupBuildOutputWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "BuildOutput")
+			os.Exit(1)
+		}
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
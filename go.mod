@@ -8,7 +8,9 @@ require (
 	github.com/manifestival/manifestival v0.7.2
 	github.com/onsi/ginkgo/v2 v2.19.0
 	github.com/onsi/gomega v1.33.1
+	github.com/openshift/api v0.0.0-20240304080513-3e8192a10b13
 	github.com/openshift/service-ca-operator v0.0.0-20240621184327-1f7d6472fea3
+	github.com/prometheus/client_golang v1.19.0
 	github.com/shipwright-io/operator v0.13.0
 	github.com/tektoncd/operator v0.71.0
 	k8s.io/api v0.29.7
@@ -59,11 +61,9 @@ require (
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
 	github.com/openshift-pipelines/pipelines-as-code v0.27.0 // indirect
-	github.com/openshift/api v0.0.0-20240304080513-3e8192a10b13 // indirect
 	github.com/openshift/apiserver-library-go v0.0.0-20230816171015-6bfafa975bfb // indirect
 	github.com/openshift/client-go v0.0.0-20230926161409-848405da69e1 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/prometheus/client_golang v1.19.0 // indirect
 	github.com/prometheus/client_model v0.6.1 // indirect
 	github.com/prometheus/common v0.52.2 // indirect
 	github.com/prometheus/procfs v0.13.0 // indirect
@@ -0,0 +1,529 @@
+package buildnamespace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// podSecurityLabels are the namespace labels set to the Pod Security level of the profile
+var podSecurityLabels = []string{
+	"pod-security.kubernetes.io/enforce",
+	"pod-security.kubernetes.io/audit",
+	"pod-security.kubernetes.io/warn",
+}
+
+// podSecurityLabelSyncLabel disables the OpenShift controller synchronizing the Pod Security labels
+// of a namespace with the SCCs of its service accounts
+const podSecurityLabelSyncLabel = "security.openshift.io/scc.podSecurityLabelSync"
+
+// objectKinds are the kinds of the objects a profile may create in a namespace
+var objectKinds = []schema.GroupVersionKind{
+	{Group: "shipwright.io", Version: "v1alpha1", Kind: "Build"},
+	{Group: "shipwright.io", Version: "v1alpha1", Kind: "BuildStrategy"},
+}
+
+// serviceAccountAnnotations are the annotations naming the ServiceAccount of the dockercfg secrets
+// generated by OpenShift for the internal registry
+var serviceAccountAnnotations = []string{
+	"openshift.io/internal-registry-auth-token.service-account",
+	corev1.ServiceAccountNameKey,
+}
+
+// ErrRegistryCredentialsPending is returned when OpenShift has not yet generated the internal
+// registry credentials of the ServiceAccount of a namespace
+var ErrRegistryCredentialsPending = errors.New("internal registry credentials of the service account are not generated yet")
+
+// Onboarding type defines methods to create and delete the build setup of the namespaces selected
+// by a BuildNamespaceProfile
+type Onboarding struct {
+	Client client.Client
+	// APIReader reads the secrets of the namespaces, which are not cached by the manager
+	APIReader client.Reader
+}
+
+// New creates new instance of Onboarding type
+func New(client client.Client, apiReader client.Reader) *Onboarding {
+	return &Onboarding{
+		Client:    client,
+		APIReader: apiReader,
+	}
+}
+
+// Matches returns true if the namespace is selected by the profile. Terminating namespaces and the
+// OpenShift platform namespaces are never selected.
+func Matches(profile *openshiftv1alpha1.BuildNamespaceProfile, namespace *corev1.Namespace) (bool, error) {
+	if !namespace.DeletionTimestamp.IsZero() || common.IsPlatformNamespace(namespace.Name) {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&profile.Spec.NamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
+
+// ServiceAccountName returns the name of the ServiceAccount running the builds of the namespaces
+// selected by the profile
+func ServiceAccountName(profile *openshiftv1alpha1.BuildNamespaceProfile) string {
+	if profile.Spec.ServiceAccount != "" {
+		return profile.Spec.ServiceAccount
+	}
+	return common.BuildServiceAccountName
+}
+
+// IsInternalRegistryEnabled returns true if the profile provides push credentials of the internal
+// registry to its ServiceAccount
+func IsInternalRegistryEnabled(profile *openshiftv1alpha1.BuildNamespaceProfile) bool {
+	return profile.Spec.InternalRegistry != nil && profile.Spec.InternalRegistry.State == openshiftv1alpha1.Enabled
+}
+
+// PushSecretName returns the name of the internal registry push secret linked to the ServiceAccount
+// of the profile
+func PushSecretName(profile *openshiftv1alpha1.BuildNamespaceProfile) string {
+	return ServiceAccountName(profile) + common.InternalRegistryPushSecretSuffix
+}
+
+// Onboard labels the namespace and creates or updates the ServiceAccount, RoleBindings, LimitRange,
+// ResourceQuota, push secret, and objects of the profile in it. Objects the profile no longer
+// defines are deleted. Returns ErrRegistryCredentialsPending once everything else is set up if the
+// push secret cannot be created yet.
+func (o *Onboarding) Onboard(ctx context.Context, profile *openshiftv1alpha1.BuildNamespaceProfile, namespace *corev1.Namespace) error {
+	if err := o.labelNamespace(ctx, profile, namespace); err != nil {
+		return err
+	}
+
+	serviceAccount, err := o.applyServiceAccount(ctx, profile, namespace.Name)
+	if err != nil {
+		return err
+	}
+	keep := []client.Object{serviceAccount}
+
+	var pending error
+	if IsInternalRegistryEnabled(profile) {
+		roleBinding := &rbacv1.RoleBinding{}
+		roleBinding.SetName(profile.Name + "-image-builder")
+		roleBinding.SetNamespace(namespace.Name)
+		if err := o.apply(ctx, profile, roleBinding, func() error {
+			roleBinding.RoleRef = rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     common.ImageBuilderClusterRoleName,
+			}
+			roleBinding.Subjects = []rbacv1.Subject{{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      serviceAccount.Name,
+				Namespace: namespace.Name,
+			}}
+			return nil
+		}); err != nil {
+			return err
+		}
+		keep = append(keep, roleBinding)
+
+		pushSecret := &corev1.Secret{}
+		pushSecret.SetName(PushSecretName(profile))
+		pushSecret.SetNamespace(namespace.Name)
+		if err := o.applyPushSecret(ctx, profile, pushSecret); err != nil {
+			if !errors.Is(err, ErrRegistryCredentialsPending) {
+				return err
+			}
+			pending = err
+		}
+		keep = append(keep, pushSecret)
+	}
+
+	for _, binding := range []struct {
+		suffix   string
+		role     string
+		subjects []rbacv1.Subject
+	}{
+		{suffix: "edit", role: common.ShipwrightAggregateEditRoleName, subjects: profile.Spec.Editors},
+		{suffix: "view", role: common.ShipwrightAggregateViewRoleName, subjects: profile.Spec.Viewers},
+	} {
+		if len(binding.subjects) == 0 {
+			continue
+		}
+		roleBinding := &rbacv1.RoleBinding{}
+		roleBinding.SetName(profile.Name + "-shipwright-" + binding.suffix)
+		roleBinding.SetNamespace(namespace.Name)
+		if err := o.apply(ctx, profile, roleBinding, func() error {
+			roleBinding.RoleRef = rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     binding.role,
+			}
+			roleBinding.Subjects = binding.subjects
+			return nil
+		}); err != nil {
+			return err
+		}
+		keep = append(keep, roleBinding)
+	}
+
+	if limits := profile.Spec.Limits; limits != nil {
+		limitRange := &corev1.LimitRange{}
+		limitRange.SetName(profile.Name + "-builds")
+		limitRange.SetNamespace(namespace.Name)
+		if err := o.apply(ctx, profile, limitRange, func() error {
+			limitRange.Spec.Limits = []corev1.LimitRangeItem{{
+				Type:           corev1.LimitTypeContainer,
+				Default:        limits.DefaultLimits,
+				DefaultRequest: limits.DefaultRequests,
+				Max:            limits.Max,
+			}}
+			return nil
+		}); err != nil {
+			return err
+		}
+		keep = append(keep, limitRange)
+	}
+
+	if len(profile.Spec.Quota) > 0 {
+		quota := &corev1.ResourceQuota{}
+		quota.SetName(profile.Name + "-builds")
+		quota.SetNamespace(namespace.Name)
+		if err := o.apply(ctx, profile, quota, func() error {
+			quota.Spec.Hard = profile.Spec.Quota
+			return nil
+		}); err != nil {
+			return err
+		}
+		keep = append(keep, quota)
+	}
+
+	objects, err := Objects(profile)
+	if err != nil {
+		return err
+	}
+	for _, desired := range objects {
+		object := &unstructured.Unstructured{}
+		object.SetGroupVersionKind(desired.GroupVersionKind())
+		object.SetName(desired.GetName())
+		object.SetNamespace(namespace.Name)
+		if err := o.apply(ctx, profile, object, func() error {
+			for key, value := range desired.Object {
+				if key != "apiVersion" && key != "kind" && key != "metadata" && key != "status" {
+					object.Object[key] = value
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		keep = append(keep, object)
+	}
+
+	if err := o.deleteObjects(ctx, profile, namespace.Name, keep); err != nil {
+		return err
+	}
+	return pending
+}
+
+// Offboard deletes the objects of the profile from the namespace and removes the labels set by the
+// profile. A ServiceAccount created by users is kept, only the push secret is unlinked from it.
+func (o *Onboarding) Offboard(ctx context.Context, profile *openshiftv1alpha1.BuildNamespaceProfile, namespace *corev1.Namespace) error {
+	if err := o.deleteObjects(ctx, profile, namespace.Name, nil); err != nil {
+		return err
+	}
+
+	serviceAccount := &corev1.ServiceAccount{}
+	err := o.Client.Get(ctx, client.ObjectKey{Namespace: namespace.Name, Name: ServiceAccountName(profile)}, serviceAccount)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if err == nil && serviceAccount.Labels[common.BuildNamespaceProfileLabel] != profile.Name {
+		if err := o.linkServiceAccountSecret(ctx, serviceAccount, PushSecretName(profile), false); err != nil {
+			return err
+		}
+	}
+
+	original := namespace.DeepCopy()
+	patch := client.MergeFrom(original)
+	delete(namespace.Labels, common.BuildNamespaceProfileLabel)
+	if _, ok := namespace.Annotations[common.PodSecurityLevelAnnotation]; ok {
+		for _, label := range append(podSecurityLabels, podSecurityLabelSyncLabel) {
+			delete(namespace.Labels, label)
+		}
+		delete(namespace.Annotations, common.PodSecurityLevelAnnotation)
+	}
+	if equality.Semantic.DeepEqual(original, namespace) {
+		return nil
+	}
+	return client.IgnoreNotFound(o.Client.Patch(ctx, namespace, patch))
+}
+
+// Objects decodes the objects of the profile. Only Builds and BuildStrategies are allowed.
+func Objects(profile *openshiftv1alpha1.BuildNamespaceProfile) ([]*unstructured.Unstructured, error) {
+	objects := []*unstructured.Unstructured{}
+	for i, raw := range profile.Spec.Objects {
+		object := &unstructured.Unstructured{}
+		if err := json.Unmarshal(raw.Raw, &object.Object); err != nil {
+			return nil, fmt.Errorf("spec.objects[%d]: %w", i, err)
+		}
+		if !slices.Contains(objectKinds, object.GroupVersionKind()) {
+			return nil, fmt.Errorf("spec.objects[%d]: %s is not a Build or a BuildStrategy", i, object.GroupVersionKind())
+		}
+		if object.GetName() == "" {
+			return nil, fmt.Errorf("spec.objects[%d]: metadata.name is required", i)
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// labelNamespace marks the namespace as onboarded by the profile, and sets the Pod Security labels
+// of the profile. Labels set by a previous level of the profile are removed when it is unset. The
+// namespace is only patched when its labels change.
+func (o *Onboarding) labelNamespace(ctx context.Context, profile *openshiftv1alpha1.BuildNamespaceProfile, namespace *corev1.Namespace) error {
+	original := namespace.DeepCopy()
+	patch := client.MergeFrom(original)
+	if namespace.Labels == nil {
+		namespace.Labels = map[string]string{}
+	}
+	namespace.Labels[common.BuildNamespaceProfileLabel] = profile.Name
+
+	level := string(profile.Spec.PodSecurity)
+	_, managed := namespace.Annotations[common.PodSecurityLevelAnnotation]
+	switch {
+	case level != "":
+		for _, label := range podSecurityLabels {
+			namespace.Labels[label] = level
+		}
+		namespace.Labels[podSecurityLabelSyncLabel] = "false"
+		if namespace.Annotations == nil {
+			namespace.Annotations = map[string]string{}
+		}
+		namespace.Annotations[common.PodSecurityLevelAnnotation] = level
+	case managed:
+		for _, label := range append(podSecurityLabels, podSecurityLabelSyncLabel) {
+			delete(namespace.Labels, label)
+		}
+		delete(namespace.Annotations, common.PodSecurityLevelAnnotation)
+	}
+	if equality.Semantic.DeepEqual(original, namespace) {
+		return nil
+	}
+	return o.Client.Patch(ctx, namespace, patch)
+}
+
+// applyServiceAccount creates or updates the ServiceAccount of the profile. A ServiceAccount of the
+// same name created by users is not taken over: the push secret is linked to it, but it is neither
+// labelled nor controlled by the profile, so it is never deleted with it.
+func (o *Onboarding) applyServiceAccount(ctx context.Context, profile *openshiftv1alpha1.BuildNamespaceProfile, namespace string) (*corev1.ServiceAccount, error) {
+	key := client.ObjectKey{Namespace: namespace, Name: ServiceAccountName(profile)}
+	serviceAccount := &corev1.ServiceAccount{}
+	err := o.Client.Get(ctx, key, serviceAccount)
+	if client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+	if err == nil && serviceAccount.Labels[common.BuildNamespaceProfileLabel] != profile.Name {
+		return serviceAccount, o.linkServiceAccountSecret(ctx, serviceAccount, PushSecretName(profile), IsInternalRegistryEnabled(profile))
+	}
+
+	serviceAccount = &corev1.ServiceAccount{}
+	serviceAccount.SetName(key.Name)
+	serviceAccount.SetNamespace(key.Namespace)
+	return serviceAccount, o.apply(ctx, profile, serviceAccount, func() error {
+		linkSecret(serviceAccount, PushSecretName(profile), IsInternalRegistryEnabled(profile))
+		return nil
+	})
+}
+
+// linkServiceAccountSecret links or unlinks the secret of a ServiceAccount not managed by the
+// profile. The patch fails on conflict, so the secrets linked meanwhile by OpenShift are not lost.
+func (o *Onboarding) linkServiceAccountSecret(ctx context.Context, serviceAccount *corev1.ServiceAccount, name string, linked bool) error {
+	patch := client.MergeFromWithOptions(serviceAccount.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	if !linkSecret(serviceAccount, name, linked) {
+		return nil
+	}
+	return client.IgnoreNotFound(o.Client.Patch(ctx, serviceAccount, patch))
+}
+
+// apply creates or updates the object, labelled with the profile and controlled by it
+func (o *Onboarding) apply(ctx context.Context, profile *openshiftv1alpha1.BuildNamespaceProfile, object client.Object, mutate func() error) error {
+	_, err := ctrl.CreateOrUpdate(ctx, o.Client, object, func() error {
+		objectLabels := object.GetLabels()
+		if objectLabels == nil {
+			objectLabels = map[string]string{}
+		}
+		objectLabels[common.BuildNamespaceProfileLabel] = profile.Name
+		objectLabels[common.ManagedByLabel] = common.ManagedByLabelValue
+		object.SetLabels(objectLabels)
+		if err := mutate(); err != nil {
+			return err
+		}
+		return ctrl.SetControllerReference(profile, object, o.Client.Scheme())
+	})
+	return err
+}
+
+// applyPushSecret creates or updates the push secret of the internal registry from the dockercfg
+// secret generated by OpenShift for the ServiceAccount, so that it follows the rotations of the
+// credentials. Only the metadata of the secrets is cached by the manager, so the secrets generated
+// for the ServiceAccount are found in the cache and read with the APIReader.
+func (o *Onboarding) applyPushSecret(ctx context.Context, profile *openshiftv1alpha1.BuildNamespaceProfile, pushSecret *corev1.Secret) error {
+	secrets := &metav1.PartialObjectMetadataList{}
+	secrets.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("SecretList"))
+	if err := o.Client.List(ctx, secrets, client.InNamespace(pushSecret.Namespace)); err != nil {
+		return err
+	}
+	candidates := []*metav1.PartialObjectMetadata{}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if isServiceAccountSecret(secret, ServiceAccountName(profile)) && secret.DeletionTimestamp.IsZero() {
+			candidates = append(candidates, secret)
+		}
+	}
+	// the credentials are rotated by creating a new secret, so the newest one is copied
+	slices.SortFunc(candidates, func(a, b *metav1.PartialObjectMetadata) int {
+		return b.CreationTimestamp.Time.Compare(a.CreationTimestamp.Time)
+	})
+	var dockercfg []byte
+	for _, candidate := range candidates {
+		secret := &corev1.Secret{}
+		if err := o.APIReader.Get(ctx, client.ObjectKeyFromObject(candidate), secret); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		if secret.Type == corev1.SecretTypeDockercfg && secret.DeletionTimestamp.IsZero() {
+			dockercfg = secret.Data[corev1.DockerConfigKey]
+			break
+		}
+	}
+	if len(dockercfg) == 0 {
+		return ErrRegistryCredentialsPending
+	}
+
+	auths := map[string]json.RawMessage{}
+	if err := json.Unmarshal(dockercfg, &auths); err != nil {
+		return fmt.Errorf("failed to decode the internal registry credentials: %w", err)
+	}
+	dockerConfigJSON, err := json.Marshal(map[string]interface{}{"auths": auths})
+	if err != nil {
+		return err
+	}
+
+	err = o.APIReader.Get(ctx, client.ObjectKeyFromObject(pushSecret), pushSecret)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	exists := err == nil
+	original := pushSecret.DeepCopy()
+	if pushSecret.Labels == nil {
+		pushSecret.Labels = map[string]string{}
+	}
+	pushSecret.Labels[common.BuildNamespaceProfileLabel] = profile.Name
+	pushSecret.Labels[common.ManagedByLabel] = common.ManagedByLabelValue
+	pushSecret.Type = corev1.SecretTypeDockerConfigJson
+	pushSecret.Data = map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON}
+	if err := ctrl.SetControllerReference(profile, pushSecret, o.Client.Scheme()); err != nil {
+		return err
+	}
+	switch {
+	case !exists:
+		return o.Client.Create(ctx, pushSecret)
+	case !equality.Semantic.DeepEqual(original, pushSecret):
+		return o.Client.Update(ctx, pushSecret)
+	}
+	return nil
+}
+
+// IsRegistryCredentials returns true if the object may be the internal registry credentials
+// generated by OpenShift for a ServiceAccount
+func IsRegistryCredentials(object metav1.Object) bool {
+	return slices.ContainsFunc(serviceAccountAnnotations, func(annotation string) bool {
+		return object.GetAnnotations()[annotation] != ""
+	})
+}
+
+// isServiceAccountSecret returns true if the secret was generated for the ServiceAccount
+func isServiceAccountSecret(secret metav1.Object, serviceAccount string) bool {
+	return slices.ContainsFunc(serviceAccountAnnotations, func(annotation string) bool {
+		return secret.GetAnnotations()[annotation] == serviceAccount
+	})
+}
+
+// linkSecret adds the secret to the secrets of the ServiceAccount, or removes it when not linked,
+// and returns true if the secrets changed. Secrets linked by OpenShift or by users are left
+// untouched.
+func linkSecret(serviceAccount *corev1.ServiceAccount, name string, linked bool) bool {
+	index := slices.IndexFunc(serviceAccount.Secrets, func(reference corev1.ObjectReference) bool {
+		return reference.Name == name
+	})
+	switch {
+	case linked && index < 0:
+		serviceAccount.Secrets = append(serviceAccount.Secrets, corev1.ObjectReference{Name: name})
+	case !linked && index >= 0:
+		serviceAccount.Secrets = slices.Delete(serviceAccount.Secrets, index, index+1)
+	default:
+		return false
+	}
+	return true
+}
+
+// deleteObjects deletes the objects labelled with the profile in the namespace, except the ones
+// to keep. Only the metadata of the secrets is read, from the cache.
+func (o *Onboarding) deleteObjects(ctx context.Context, profile *openshiftv1alpha1.BuildNamespaceProfile, namespace string, keep []client.Object) error {
+	secrets := &metav1.PartialObjectMetadataList{}
+	secrets.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("SecretList"))
+	lists := []client.ObjectList{
+		&corev1.ServiceAccountList{},
+		&rbacv1.RoleBindingList{},
+		&corev1.LimitRangeList{},
+		&corev1.ResourceQuotaList{},
+		secrets,
+	}
+	for _, gvk := range objectKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		lists = append(lists, list)
+	}
+
+	for _, list := range lists {
+		err := o.Client.List(ctx, list, client.InNamespace(namespace),
+			client.MatchingLabels{common.BuildNamespaceProfileLabel: profile.Name})
+		if common.IgnoreMissing(err) != nil {
+			return err
+		}
+		if err != nil {
+			continue
+		}
+		if err := meta.EachListItem(list, func(item runtime.Object) error {
+			object := item.(client.Object)
+			if metadata, ok := object.(*metav1.PartialObjectMetadata); ok {
+				metadata.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+			}
+			if isKept(o.Client.Scheme(), object, keep) {
+				return nil
+			}
+			return client.IgnoreNotFound(o.Client.Delete(ctx, object))
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isKept returns true if an object of the same kind and name is listed
+func isKept(scheme *runtime.Scheme, object client.Object, keep []client.Object) bool {
+	kind := common.KindOf(scheme, object)
+	return slices.ContainsFunc(keep, func(kept client.Object) bool {
+		return kept.GetName() == object.GetName() && common.KindOf(scheme, kept) == kind
+	})
+}
@@ -0,0 +1,35 @@
+package buildnamespace_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var scheme *runtime.Scheme
+
+func TestBuildNamespace(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Build Namespace Suite")
+}
+
+var _ = BeforeSuite(func() {
+	scheme = runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+	// register the Shipwright builds and build strategies as unstructured objects
+	for _, kind := range []string{"BuildStrategy", "Build"} {
+		gvk := schema.GroupVersionKind{Group: "shipwright.io", Version: "v1alpha1", Kind: kind}
+		scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		gvk.Kind += "List"
+		scheme.AddKnownTypeWithName(gvk, &unstructured.UnstructuredList{})
+	}
+})
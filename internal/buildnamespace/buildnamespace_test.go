@@ -0,0 +1,270 @@
+package buildnamespace_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/redhat-openshift-builds/operator/internal/buildnamespace"
+)
+
+var _ = Describe("Onboarding", Label("buildnamespace"), func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		onboarding *buildnamespace.Onboarding
+		profile    *openshiftv1alpha1.BuildNamespaceProfile
+		namespace  *corev1.Namespace
+	)
+
+	getNamespace := func() *corev1.Namespace {
+		object := &corev1.Namespace{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(namespace), object)).To(Succeed())
+		return object
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		profile = &openshiftv1alpha1.BuildNamespaceProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "team",
+				UID:  uuid.NewUUID(),
+			},
+			Spec: openshiftv1alpha1.BuildNamespaceProfileSpec{
+				NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "true"}},
+				Editors: []rbacv1.Subject{{
+					APIGroup: rbacv1.GroupName,
+					Kind:     rbacv1.GroupKind,
+					Name:     "developers",
+				}},
+				PodSecurity: "baseline",
+				Limits: &openshiftv1alpha1.BuildLimits{
+					DefaultLimits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+				},
+				Quota: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("8")},
+				Objects: []runtime.RawExtension{{
+					Raw: []byte(`{"apiVersion":"shipwright.io/v1alpha1","kind":"Build","metadata":{"name":"sample"},"spec":{"strategy":{"name":"buildah"}}}`),
+				}},
+			},
+		}
+		namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "tenant",
+			Labels: map[string]string{"team": "true"},
+		}}
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespace).Build()
+		onboarding = buildnamespace.New(fakeClient, fakeClient)
+	})
+
+	It("should match namespaces selected by the profile only", func() {
+		Expect(buildnamespace.Matches(profile, namespace)).To(BeTrue())
+		Expect(buildnamespace.Matches(profile, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "openshift-config",
+			Labels: map[string]string{"team": "true"},
+		}})).To(BeFalse())
+		Expect(buildnamespace.Matches(profile, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: "other",
+		}})).To(BeFalse())
+	})
+
+	It("should create the build setup of the namespace", func() {
+		Expect(onboarding.Onboard(ctx, profile, namespace)).To(Succeed())
+
+		labels := getNamespace().Labels
+		Expect(labels).To(HaveKeyWithValue(common.BuildNamespaceProfileLabel, "team"))
+		Expect(labels).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "baseline"))
+
+		serviceAccount := &corev1.ServiceAccount{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: common.BuildServiceAccountName}, serviceAccount)).To(Succeed())
+		Expect(metav1.IsControlledBy(serviceAccount, profile)).To(BeTrue())
+
+		roleBinding := &rbacv1.RoleBinding{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "team-shipwright-edit"}, roleBinding)).To(Succeed())
+		Expect(roleBinding.RoleRef.Name).To(Equal(common.ShipwrightAggregateEditRoleName))
+		Expect(roleBinding.Subjects).To(Equal(profile.Spec.Editors))
+		err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "team-shipwright-view"}, &rbacv1.RoleBinding{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		limitRange := &corev1.LimitRange{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "team-builds"}, limitRange)).To(Succeed())
+		Expect(limitRange.Spec.Limits[0].Default.Memory().String()).To(Equal("2Gi"))
+		quota := &corev1.ResourceQuota{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "team-builds"}, quota)).To(Succeed())
+
+		build := &unstructured.Unstructured{}
+		build.SetAPIVersion("shipwright.io/v1alpha1")
+		build.SetKind("Build")
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "sample"}, build)).To(Succeed())
+		Expect(build.Object["spec"]).To(HaveKeyWithValue("strategy", map[string]interface{}{"name": "buildah"}))
+	})
+
+	It("should delete the objects no longer defined by the profile", func() {
+		Expect(onboarding.Onboard(ctx, profile, namespace)).To(Succeed())
+
+		profile.Spec.Quota = nil
+		profile.Spec.PodSecurity = ""
+		Expect(onboarding.Onboard(ctx, profile, getNamespace())).To(Succeed())
+
+		err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "team-builds"}, &corev1.ResourceQuota{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "team-builds"}, &corev1.LimitRange{})).To(Succeed())
+		Expect(getNamespace().Labels).NotTo(HaveKey("pod-security.kubernetes.io/enforce"))
+	})
+
+	It("should clean up a namespace which stops matching", func() {
+		Expect(onboarding.Onboard(ctx, profile, namespace)).To(Succeed())
+		Expect(onboarding.Offboard(ctx, profile, getNamespace())).To(Succeed())
+
+		Expect(getNamespace().Labels).To(Equal(map[string]string{"team": "true"}))
+		serviceAccounts := &corev1.ServiceAccountList{}
+		Expect(fakeClient.List(ctx, serviceAccounts, client.InNamespace("tenant"))).To(Succeed())
+		Expect(serviceAccounts.Items).To(BeEmpty())
+		roleBindings := &rbacv1.RoleBindingList{}
+		Expect(fakeClient.List(ctx, roleBindings, client.InNamespace("tenant"))).To(Succeed())
+		Expect(roleBindings.Items).To(BeEmpty())
+	})
+
+	It("should not write unchanged objects", func() {
+		Expect(onboarding.Onboard(ctx, profile, namespace)).To(Succeed())
+
+		writes := 0
+		countWrites := interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				writes++
+				return c.Update(ctx, obj, opts...)
+			},
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				writes++
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}
+		onboarding = buildnamespace.New(interceptor.NewClient(fakeClient.(client.WithWatch), countWrites), fakeClient)
+		Expect(onboarding.Onboard(ctx, profile, getNamespace())).To(Succeed())
+		Expect(writes).To(BeZero())
+	})
+
+	It("should keep the service account created by users", func() {
+		profile.Spec.InternalRegistry = &openshiftv1alpha1.InternalRegistry{State: openshiftv1alpha1.Enabled}
+		serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+			Name:      common.BuildServiceAccountName,
+			Namespace: "tenant",
+		}}
+		Expect(fakeClient.Create(ctx, serviceAccount)).To(Succeed())
+		Expect(fakeClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "shipwright-builder-dockercfg-x7k2p",
+				Namespace:   "tenant",
+				Annotations: map[string]string{corev1.ServiceAccountNameKey: common.BuildServiceAccountName},
+			},
+			Type: corev1.SecretTypeDockercfg,
+			Data: map[string][]byte{corev1.DockerConfigKey: []byte(`{}`)},
+		})).To(Succeed())
+
+		Expect(onboarding.Onboard(ctx, profile, namespace)).To(Succeed())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(serviceAccount), serviceAccount)).To(Succeed())
+		Expect(serviceAccount.Labels).NotTo(HaveKey(common.BuildNamespaceProfileLabel))
+		Expect(serviceAccount.OwnerReferences).To(BeEmpty())
+		Expect(serviceAccount.Secrets).To(ContainElement(corev1.ObjectReference{Name: "shipwright-builder-registry-push"}))
+
+		Expect(onboarding.Offboard(ctx, profile, getNamespace())).To(Succeed())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(serviceAccount), serviceAccount)).To(Succeed())
+		Expect(serviceAccount.Secrets).To(BeEmpty())
+	})
+
+	When("the internal registry is enabled", func() {
+		BeforeEach(func() {
+			profile.Spec.InternalRegistry = &openshiftv1alpha1.InternalRegistry{State: openshiftv1alpha1.Enabled}
+		})
+
+		It("should wait for the credentials generated by OpenShift", func() {
+			err := onboarding.Onboard(ctx, profile, namespace)
+			Expect(err).To(MatchError(buildnamespace.ErrRegistryCredentialsPending))
+
+			roleBinding := &rbacv1.RoleBinding{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "team-image-builder"}, roleBinding)).To(Succeed())
+			Expect(roleBinding.RoleRef.Name).To(Equal(common.ImageBuilderClusterRoleName))
+		})
+
+		It("should link a push secret to the service account", func() {
+			dockercfg := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "shipwright-builder-dockercfg-x7k2p",
+					Namespace:   "tenant",
+					Annotations: map[string]string{corev1.ServiceAccountNameKey: common.BuildServiceAccountName},
+				},
+				Type: corev1.SecretTypeDockercfg,
+				Data: map[string][]byte{
+					corev1.DockerConfigKey: []byte(`{"image-registry.openshift-image-registry.svc:5000":{"auth":"dG9rZW4="}}`),
+				},
+			}
+			Expect(fakeClient.Create(ctx, dockercfg)).To(Succeed())
+			Expect(onboarding.Onboard(ctx, profile, namespace)).To(Succeed())
+
+			pushSecret := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "shipwright-builder-registry-push"}, pushSecret)).To(Succeed())
+			Expect(pushSecret.Type).To(Equal(corev1.SecretTypeDockerConfigJson))
+			Expect(pushSecret.Data[corev1.DockerConfigJsonKey]).To(MatchJSON(
+				`{"auths":{"image-registry.openshift-image-registry.svc:5000":{"auth":"dG9rZW4="}}}`))
+			serviceAccount := &corev1.ServiceAccount{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: common.BuildServiceAccountName}, serviceAccount)).To(Succeed())
+			Expect(serviceAccount.Secrets).To(ContainElement(corev1.ObjectReference{Name: pushSecret.Name}))
+
+			profile.Spec.InternalRegistry.State = openshiftv1alpha1.Disabled
+			Expect(onboarding.Onboard(ctx, profile, getNamespace())).To(Succeed())
+			err := fakeClient.Get(ctx, client.ObjectKeyFromObject(pushSecret), &corev1.Secret{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(dockercfg), &corev1.Secret{})).To(Succeed())
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(serviceAccount), serviceAccount)).To(Succeed())
+			Expect(serviceAccount.Secrets).To(BeEmpty())
+		})
+		It("should copy the rotated credentials to the push secret", func() {
+			dockercfg := func(name string, created time.Time, token string) *corev1.Secret {
+				return &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              name,
+						Namespace:         "tenant",
+						CreationTimestamp: metav1.NewTime(created),
+						Annotations:       map[string]string{"openshift.io/internal-registry-auth-token.service-account": common.BuildServiceAccountName},
+					},
+					Type: corev1.SecretTypeDockercfg,
+					Data: map[string][]byte{
+						corev1.DockerConfigKey: []byte(`{"image-registry.openshift-image-registry.svc:5000":{"auth":"` + token + `"}}`),
+					},
+				}
+			}
+			now := time.Now()
+			Expect(fakeClient.Create(ctx, dockercfg("shipwright-builder-dockercfg-b7k2p", now.Add(-time.Hour), "b2xk"))).To(Succeed())
+			Expect(onboarding.Onboard(ctx, profile, namespace)).To(Succeed())
+
+			Expect(fakeClient.Create(ctx, dockercfg("shipwright-builder-dockercfg-x9d4z", now, "bmV3"))).To(Succeed())
+			Expect(onboarding.Onboard(ctx, profile, getNamespace())).To(Succeed())
+
+			pushSecret := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "tenant", Name: "shipwright-builder-registry-push"}, pushSecret)).To(Succeed())
+			Expect(pushSecret.Data[corev1.DockerConfigJsonKey]).To(MatchJSON(
+				`{"auths":{"image-registry.openshift-image-registry.svc:5000":{"auth":"bmV3"}}}`))
+		})
+	})
+
+	It("should reject objects other than Builds and BuildStrategies", func() {
+		profile.Spec.Objects = []runtime.RawExtension{{
+			Raw: []byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"token"}}`),
+		}}
+		_, err := buildnamespace.Objects(profile)
+		Expect(err).To(MatchError(ContainSubstring("is not a Build or a BuildStrategy")))
+	})
+})
@@ -7,6 +7,7 @@ const (
 	OpenShiftBuildOperatorCRDName = "openshiftbuilds.operator.openshift.io"
 	OpenShiftBuildResourceName    = "cluster"
 	OpenShiftBuildNamespaceName   = "openshift-builds"
+	OperatorNamespaceLabel        = "operator.openshift.io/openshift-builds"
 )
 
 const (
@@ -15,11 +16,26 @@ const (
 	ShipwrightBuildStrategyManifestPathEnv = "SHIPWRIGHT_BUILD_STRATEGY_MANIFEST_PATH"
 	ShipwrightWebhookServiceName           = "shp-build-webhook"
 	ShipwrightWebhookCertSecretName        = "shipwright-build-webhook-cert"
+	ShipwrightBuildControllerName          = "shipwright-build-controller"
+	ShipwrightBuildConfigManifestPathEnv   = "SHIPWRIGHT_BUILD_CONFIG_MANIFEST_PATH"
+	ClusterBuildStrategyCRDName            = "clusterbuildstrategies.shipwright.io"
+)
+
+const (
+	ManagedByLabel               = "app.kubernetes.io/managed-by"
+	ManagedByLabelValue          = "openshift-builds-operator"
+	StrategyBundleLabel          = "operator.openshift.io/strategy-bundle"
+	StrategyCatalogSource        = "Catalog"
+	StrategyDeprecatedAnnotation = "operator.openshift.io/deprecated-since"
+	StrategyLabel                = "operator.openshift.io/strategy"
+	StrategyVersionLabel         = "operator.openshift.io/strategy-version"
+	ReplicatedFromLabel          = "operator.openshift.io/replicated-from"
 )
 
 var (
 	ShipwrightBuildManifestPath         = filepath.Join("config", "shipwright", "build", "release")
 	ShipwrightBuildStrategyManifestPath = filepath.Join("config", "shipwright", "build", "strategy")
+	ShipwrightBuildConfigManifestPath   = filepath.Join("config", "shipwright", "build", "config")
 	ShipwrightBuildCRDNames             = []string{
 		"builds.shipwright.io",
 		"buildruns.shipwright.io",
@@ -28,10 +44,87 @@ var (
 	}
 )
 
+const (
+	SharedResourceCSIDriverName        = "csi.sharedresource.openshift.io"
+	SharedResourceConfigMapName        = "csi-driver-shared-resource-config"
+	SharedResourceConfigKey            = "config.yaml"
+	SharedResourceNodeDaemonSetName    = "shared-resource-csi-driver-node"
+	SharedResourceConfigHashAnnotation = "operator.openshift.io/shared-resource-config-hash"
+	SharedResourceAdoptedAnnotation    = "operator.openshift.io/adopted-by"
+	SharedResourceManagedAnnotation    = "operator.openshift.io/managed-by"
+)
+
+const (
+	ShareGrantNamespacesAnnotation        = "operator.openshift.io/grant-namespaces"
+	ShareGrantNamespaceSelectorAnnotation = "operator.openshift.io/grant-namespace-selector"
+	ShareGrantServiceAccountsAnnotation   = "operator.openshift.io/grant-service-accounts"
+	ShareGrantLabel                       = "operator.openshift.io/share-grant"
+	DefaultShareServiceAccountName        = "pipeline"
+)
+
 var (
 	SharedResourceManifestPath = filepath.Join("config", "sharedresource")
 )
 
+const (
+	EntitlementShareName = "openshift-builds-etc-pki-entitlement"
+	PullSecretShareName  = "openshift-builds-pull-secret"
+)
+
+const (
+	TrustedCABundleConfigMapName = "openshift-builds-trusted-ca-bundle"
+	TrustedCABundleInjectLabel   = "config.openshift.io/inject-trusted-cabundle"
+	TrustedCABundleVolumeName    = "trusted-ca-bundle"
+	TrustedCABundleKey           = "ca-bundle.crt"
+	TrustedCABundleMountPath     = "/etc/pki/ca-trust/extracted/pem"
+	TrustedCABundleFileName      = "tls-ca-bundle.pem"
+)
+
+const (
+	RegistriesConfigMapName   = "openshift-builds-registries-config"
+	RegistriesVolumeName      = "registries-config"
+	RegistriesConfKey         = "registries.conf"
+	RegistriesPolicyKey       = "policy.json"
+	RegistriesMountPath       = "/etc/containers/cluster"
+	RegistriesPolicyMountPath = "/etc/containers/policy.json"
+)
+
+const (
+	UserNamespaceStrategyName = "buildah-userns"
+	UserNamespaceSCCName      = "openshift-builds-user-namespace"
+	UserNamespaceRoleName     = "openshift-builds-user-namespace-scc"
+)
+
+const (
+	BuildNamespaceProfileFinalizerName = "operator.openshift.io/buildnamespaceprofiles"
+	BuildNamespaceProfileLabel         = "operator.openshift.io/build-namespace-profile"
+	PodSecurityLevelAnnotation         = "operator.openshift.io/pod-security-level"
+	BuildServiceAccountName            = "shipwright-builder"
+	ShipwrightAggregateEditRoleName    = "shipwright-build-aggregate-edit"
+	ShipwrightAggregateViewRoleName    = "shipwright-build-aggregate-view"
+)
+
+const (
+	PruneSkipAnnotation          = "operator.openshift.io/buildrun-prune-skip"
+	PruneMaxAgeAnnotation        = "operator.openshift.io/buildrun-prune-max-age"
+	PruneKeepAnnotation          = "operator.openshift.io/buildrun-prune-keep"
+	PruneKeepSucceededAnnotation = "operator.openshift.io/buildrun-prune-keep-succeeded"
+	PruneKeepFailedAnnotation    = "operator.openshift.io/buildrun-prune-keep-failed"
+	BuildRunBuildNameLabel       = "build.shipwright.io/name"
+)
+
+const (
+	BuildRunNameLabel            = "buildrun.shipwright.io/name"
+	BuildRunSchedulingGate       = "operator.openshift.io/buildrun-concurrency"
+	BuildRunMaxRunningAnnotation = "operator.openshift.io/buildrun-max-running"
+)
+
+const (
+	InternalRegistryHost             = "image-registry.openshift-image-registry.svc:5000"
+	InternalRegistryPushSecretSuffix = "-registry-push"
+	ImageBuilderClusterRoleName      = "system:image-builder"
+)
+
 var (
 	CurrentNamespaceName string
 )
@@ -0,0 +1,227 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// operandOwnerKinds are the kinds of the custom resources whose workloads are operands
+var operandOwnerKinds = []string{"OpenShiftBuild", "ShipwrightBuild"}
+
+// PodSecurityExceptions documents the operand workloads which cannot meet the restricted Pod
+// Security profile, keyed by kind and name, such as "DaemonSet/shared-resource-csi-driver-node".
+// They are left untouched by InjectRestrictedSecurityContext.
+var PodSecurityExceptions = map[string]string{
+	"DaemonSet/" + SharedResourceNodeDaemonSetName: "The CSI node plugin mounts shared resource volumes " +
+		"into build pods from the node, which requires privileged containers and host path volumes",
+}
+
+// PodSecurityException returns the documented reason why the workload cannot meet the restricted
+// Pod Security profile, or an empty string
+func PodSecurityException(kind, name string) string {
+	return PodSecurityExceptions[kind+"/"+name]
+}
+
+// Compliance lists the operand Deployments and DaemonSets of the namespace, and reports whether
+// each of them meets the restricted Pod Security profile
+func Compliance(ctx context.Context, reader client.Reader, namespace string) ([]openshiftv1alpha1.WorkloadCompliance, error) {
+	deployments := &appsv1.DeploymentList{}
+	if err := reader.List(ctx, deployments, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := reader.List(ctx, daemonSets, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	report := []openshiftv1alpha1.WorkloadCompliance{}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if isOperand(deployment) {
+			report = append(report, workloadCompliance("Deployment", deployment.Name, &deployment.Spec.Template.Spec))
+		}
+	}
+	for i := range daemonSets.Items {
+		daemonSet := &daemonSets.Items[i]
+		if isOperand(daemonSet) {
+			report = append(report, workloadCompliance("DaemonSet", daemonSet.Name, &daemonSet.Spec.Template.Spec))
+		}
+	}
+	return report, nil
+}
+
+// workloadCompliance returns the compliance of a workload with the restricted Pod Security profile
+func workloadCompliance(kind, name string, podSpec *corev1.PodSpec) openshiftv1alpha1.WorkloadCompliance {
+	compliance := openshiftv1alpha1.WorkloadCompliance{
+		Kind:  kind,
+		Name:  name,
+		State: openshiftv1alpha1.Compliant,
+	}
+	if reason := PodSecurityException(kind, name); reason != "" {
+		compliance.State = openshiftv1alpha1.ComplianceException
+		compliance.Message = reason
+		return compliance
+	}
+	if violations := RestrictedViolations(podSpec); len(violations) > 0 {
+		compliance.State = openshiftv1alpha1.NonCompliant
+		compliance.Message = strings.Join(violations, ", ")
+	}
+	return compliance
+}
+
+// isOperand returns true if the object is controlled by an OpenShiftBuild or a ShipwrightBuild
+func isOperand(object metav1.Object) bool {
+	owner := metav1.GetControllerOf(object)
+	return owner != nil && slices.Contains(operandOwnerKinds, owner.Kind)
+}
+
+// RestrictPodSpec sets the security context of the pod and of every container to meet the
+// restricted Pod Security profile and the restricted-v2 SCC. Users and groups are left to be
+// assigned from the range of the namespace.
+func RestrictPodSpec(podSpec *corev1.PodSpec) {
+	if podSpec.SecurityContext == nil {
+		podSpec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	podSpec.SecurityContext.RunAsNonRoot = ptr.To(true)
+	podSpec.SecurityContext.RunAsUser = nil
+	podSpec.SecurityContext.RunAsGroup = nil
+	if podSpec.SecurityContext.SeccompProfile == nil {
+		podSpec.SecurityContext.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+
+	for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range containers {
+			container := &containers[i]
+			if container.SecurityContext == nil {
+				container.SecurityContext = &corev1.SecurityContext{}
+			}
+			securityContext := container.SecurityContext
+			securityContext.RunAsUser = nil
+			securityContext.RunAsGroup = nil
+			securityContext.Privileged = nil
+			securityContext.AllowPrivilegeEscalation = ptr.To(false)
+			if securityContext.RunAsNonRoot != nil && !*securityContext.RunAsNonRoot {
+				securityContext.RunAsNonRoot = nil
+			}
+			if securityContext.SeccompProfile != nil && !isRestrictedSeccompProfile(securityContext.SeccompProfile) {
+				securityContext.SeccompProfile = nil
+			}
+
+			added := []corev1.Capability{}
+			if securityContext.Capabilities != nil && slices.Contains(securityContext.Capabilities.Add, "NET_BIND_SERVICE") {
+				added = append(added, "NET_BIND_SERVICE")
+			}
+			securityContext.Capabilities = &corev1.Capabilities{
+				Add:  added,
+				Drop: []corev1.Capability{"ALL"},
+			}
+			if len(added) == 0 {
+				securityContext.Capabilities.Add = nil
+			}
+		}
+	}
+}
+
+// RestrictedViolations returns the reasons why the pod does not meet the restricted Pod Security
+// profile
+func RestrictedViolations(podSpec *corev1.PodSpec) []string {
+	violations := []string{}
+	if podSpec.HostNetwork || podSpec.HostPID || podSpec.HostIPC {
+		violations = append(violations, "shares host namespaces")
+	}
+	for _, volume := range podSpec.Volumes {
+		switch {
+		case volume.HostPath != nil:
+			violations = append(violations, fmt.Sprintf("volume %s mounts a host path", volume.Name))
+		case !isRestrictedVolume(volume):
+			violations = append(violations, fmt.Sprintf("volume %s uses a volume type not allowed", volume.Name))
+		}
+	}
+
+	podContext := podSpec.SecurityContext
+	if podContext == nil {
+		podContext = &corev1.PodSecurityContext{}
+	}
+	for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for _, container := range containers {
+			securityContext := container.SecurityContext
+			if securityContext == nil {
+				securityContext = &corev1.SecurityContext{}
+			}
+			prefix := "container " + container.Name
+			if securityContext.Privileged != nil && *securityContext.Privileged {
+				violations = append(violations, prefix+" is privileged")
+			}
+			if securityContext.AllowPrivilegeEscalation == nil || *securityContext.AllowPrivilegeEscalation {
+				violations = append(violations, prefix+" allows privilege escalation")
+			}
+			if !runsAsNonRoot(podContext, securityContext) {
+				violations = append(violations, prefix+" may run as root")
+			}
+			if !hasRestrictedSeccompProfile(podContext, securityContext) {
+				violations = append(violations, prefix+" does not set the RuntimeDefault seccomp profile")
+			}
+			capabilities := securityContext.Capabilities
+			if capabilities == nil || !slices.Contains(capabilities.Drop, "ALL") {
+				violations = append(violations, prefix+" does not drop ALL capabilities")
+			}
+			if capabilities != nil && slices.ContainsFunc(capabilities.Add, func(capability corev1.Capability) bool {
+				return capability != "NET_BIND_SERVICE"
+			}) {
+				violations = append(violations, prefix+" adds capabilities")
+			}
+			for _, port := range container.Ports {
+				if port.HostPort != 0 {
+					violations = append(violations, fmt.Sprintf("%s uses host port %d", prefix, port.HostPort))
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// runsAsNonRoot returns true if the container is required to run as a non-root user
+func runsAsNonRoot(podContext *corev1.PodSecurityContext, securityContext *corev1.SecurityContext) bool {
+	runAsUser := podContext.RunAsUser
+	if securityContext.RunAsUser != nil {
+		runAsUser = securityContext.RunAsUser
+	}
+	if runAsUser != nil && *runAsUser == 0 {
+		return false
+	}
+	runAsNonRoot := podContext.RunAsNonRoot
+	if securityContext.RunAsNonRoot != nil {
+		runAsNonRoot = securityContext.RunAsNonRoot
+	}
+	return runAsNonRoot != nil && *runAsNonRoot
+}
+
+// hasRestrictedSeccompProfile returns true if the container runs with the RuntimeDefault or a
+// Localhost seccomp profile
+func hasRestrictedSeccompProfile(podContext *corev1.PodSecurityContext, securityContext *corev1.SecurityContext) bool {
+	if securityContext.SeccompProfile != nil {
+		return isRestrictedSeccompProfile(securityContext.SeccompProfile)
+	}
+	return podContext.SeccompProfile != nil && isRestrictedSeccompProfile(podContext.SeccompProfile)
+}
+
+// isRestrictedSeccompProfile returns true if the profile is allowed by the restricted profile
+func isRestrictedSeccompProfile(profile *corev1.SeccompProfile) bool {
+	return profile.Type == corev1.SeccompProfileTypeRuntimeDefault || profile.Type == corev1.SeccompProfileTypeLocalhost
+}
+
+// isRestrictedVolume returns true if the type of the volume is allowed by the restricted profile
+func isRestrictedVolume(volume corev1.Volume) bool {
+	source := volume.VolumeSource
+	return source.ConfigMap != nil || source.CSI != nil || source.DownwardAPI != nil || source.EmptyDir != nil ||
+		source.Ephemeral != nil || source.PersistentVolumeClaim != nil || source.Projected != nil || source.Secret != nil
+}
@@ -1,36 +1,46 @@
 package common
 
 import (
+	"fmt"
 	"slices"
 
 	"github.com/manifestival/manifestival"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-// RemoveRunAsUserRunAsGroup is a Manifestival transformer function that removes runAsUser and runAsGroup
-// from a Deployment container's security context
-func RemoveRunAsUserRunAsGroup(object *unstructured.Unstructured) error {
-	if object.GetKind() != "Deployment" {
-		return nil
-	}
+// BuildStrategyKinds lists the kinds of Shipwright build strategies.
+var BuildStrategyKinds = []string{"ClusterBuildStrategy", "BuildStrategy"}
 
-	deployment := &appsv1.Deployment{}
-	if err := scheme.Scheme.Convert(object, deployment, nil); err != nil {
-		return err
+// InjectRestrictedSecurityContext is a Manifestival transformer that sets the security context of
+// the pods of Deployments and DaemonSets to meet the restricted Pod Security profile. Workloads
+// listed in PodSecurityExceptions are left untouched.
+func InjectRestrictedSecurityContext(object *unstructured.Unstructured) error {
+	if PodSecurityException(object.GetKind(), object.GetName()) != "" {
+		return nil
 	}
 
-	deployment.Spec.Template.Spec.SecurityContext.RunAsUser = nil
-	deployment.Spec.Template.Spec.SecurityContext.RunAsGroup = nil
-
-	for _, container := range deployment.Spec.Template.Spec.Containers {
-		container.SecurityContext.RunAsUser = nil
-		container.SecurityContext.RunAsGroup = nil
+	switch object.GetKind() {
+	case "Deployment":
+		deployment := &appsv1.Deployment{}
+		if err := scheme.Scheme.Convert(object, deployment, nil); err != nil {
+			return err
+		}
+		RestrictPodSpec(&deployment.Spec.Template.Spec)
+		return scheme.Scheme.Convert(deployment, object, nil)
+	case "DaemonSet":
+		daemonSet := &appsv1.DaemonSet{}
+		if err := scheme.Scheme.Convert(object, daemonSet, nil); err != nil {
+			return err
+		}
+		RestrictPodSpec(&daemonSet.Spec.Template.Spec)
+		return scheme.Scheme.Convert(daemonSet, object, nil)
 	}
-
-	return scheme.Scheme.Convert(deployment, object, nil)
+	return nil
 }
 
 // InjectAnnotations is a Manifestival transformer to add given annotations in resources of provided Kinds.
@@ -59,3 +69,123 @@ func InjectFinalizer(finalizer string) manifestival.Transformer {
 		return nil
 	}
 }
+
+// InjectTrustedCABundle is a Manifestival transformer that mounts the trusted CA bundle ConfigMap
+// in every container of the named Deployments.
+func InjectTrustedCABundle(names []string) manifestival.Transformer {
+	return func(object *unstructured.Unstructured) error {
+		if object.GetKind() != "Deployment" || !slices.Contains(names, object.GetName()) {
+			return nil
+		}
+
+		deployment := &appsv1.Deployment{}
+		if err := scheme.Scheme.Convert(object, deployment, nil); err != nil {
+			return err
+		}
+
+		podSpec := &deployment.Spec.Template.Spec
+		if !slices.ContainsFunc(podSpec.Volumes, func(volume corev1.Volume) bool {
+			return volume.Name == TrustedCABundleVolumeName
+		}) {
+			podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+				Name: TrustedCABundleVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: TrustedCABundleConfigMapName,
+						},
+						Items: []corev1.KeyToPath{{
+							Key:  TrustedCABundleKey,
+							Path: TrustedCABundleFileName,
+						}},
+					},
+				},
+			})
+		}
+
+		for i := range podSpec.Containers {
+			container := &podSpec.Containers[i]
+			if slices.ContainsFunc(container.VolumeMounts, func(mount corev1.VolumeMount) bool {
+				return mount.Name == TrustedCABundleVolumeName
+			}) {
+				continue
+			}
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      TrustedCABundleVolumeName,
+				MountPath: TrustedCABundleMountPath,
+				ReadOnly:  true,
+			})
+		}
+
+		return scheme.Scheme.Convert(deployment, object, nil)
+	}
+}
+
+// InjectBuildStrategyVolume is a Manifestival transformer that adds the volume to build strategies
+// and mounts it in every build step, once per given mount.
+func InjectBuildStrategyVolume(volume corev1.Volume, mounts ...corev1.VolumeMount) manifestival.Transformer {
+	return func(object *unstructured.Unstructured) error {
+		if !slices.Contains(BuildStrategyKinds, object.GetKind()) {
+			return nil
+		}
+
+		volumeObject, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&volume)
+		if err != nil {
+			return err
+		}
+		mountObjects := []map[string]interface{}{}
+		for i := range mounts {
+			mountObject, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&mounts[i])
+			if err != nil {
+				return err
+			}
+			mountObjects = append(mountObjects, mountObject)
+		}
+
+		if err := appendItem(object.Object, volumeObject, "name", "spec", "volumes"); err != nil {
+			return err
+		}
+
+		stepsField := BuildStrategyStepsField(object)
+		steps, _, err := unstructured.NestedSlice(object.Object, "spec", stepsField)
+		if err != nil {
+			return err
+		}
+		for _, step := range steps {
+			container, ok := step.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("invalid step in %s %q", object.GetKind(), object.GetName())
+			}
+			for _, mountObject := range mountObjects {
+				if err := appendItem(container, mountObject, "mountPath", "volumeMounts"); err != nil {
+					return err
+				}
+			}
+		}
+		return unstructured.SetNestedSlice(object.Object, steps, "spec", stepsField)
+	}
+}
+
+// BuildStrategyStepsField returns the name of the field holding the steps of a build strategy,
+// which is "buildSteps" in v1alpha1 and "steps" in v1beta1.
+func BuildStrategyStepsField(object *unstructured.Unstructured) string {
+	if _, found, _ := unstructured.NestedFieldNoCopy(object.Object, "spec", "buildSteps"); found {
+		return "buildSteps"
+	}
+	return "steps"
+}
+
+// appendItem appends item to the list found at fields, unless the list already contains an item
+// with the same value for key.
+func appendItem(object map[string]interface{}, item map[string]interface{}, key string, fields ...string) error {
+	items, _, err := unstructured.NestedSlice(object, fields...)
+	if err != nil {
+		return err
+	}
+	for _, existing := range items {
+		if existing, ok := existing.(map[string]interface{}); ok && existing[key] == item[key] {
+			return nil
+		}
+	}
+	return unstructured.SetNestedSlice(object, append(items, item), fields...)
+}
@@ -16,7 +16,7 @@ import (
 var _ = Describe("Transformer", Label("transformer"), func() {
 	var object *unstructured.Unstructured
 
-	Describe("Remove RunAsUser and RunAsGroup from security context", func() {
+	Describe("Inject restricted security context", func() {
 		BeforeEach(func() {
 			object = &unstructured.Unstructured{}
 			deployment := &appsv1.Deployment{}
@@ -36,8 +36,14 @@ var _ = Describe("Transformer", Label("transformer"), func() {
 					SecurityContext: &corev1.SecurityContext{
 						RunAsUser:  ptr.To(int64(1000)),
 						RunAsGroup: ptr.To(int64(1000)),
+						Capabilities: &corev1.Capabilities{
+							Add: []corev1.Capability{"NET_ADMIN"},
+						},
 					},
 				},
+				{
+					Name: "sidecar",
+				},
 			}
 			err := scheme.Scheme.Convert(deployment, object, nil)
 			Expect(err).ShouldNot(HaveOccurred())
@@ -45,7 +51,7 @@ var _ = Describe("Transformer", Label("transformer"), func() {
 		When("runAsUser and runAsGroup are set", func() {
 			It("should remove runAsUser and runAsGroup", func() {
 				deployment := &appsv1.Deployment{}
-				err := common.RemoveRunAsUserRunAsGroup(object)
+				err := common.InjectRestrictedSecurityContext(object)
 				Expect(err).ShouldNot(HaveOccurred())
 				err = scheme.Scheme.Convert(object, deployment, nil)
 				Expect(err).ShouldNot(HaveOccurred())
@@ -55,6 +61,59 @@ var _ = Describe("Transformer", Label("transformer"), func() {
 				Expect(deployment.Spec.Template.Spec.Containers[0].SecurityContext.RunAsGroup).To(BeNil())
 			})
 		})
+		When("containers do not set a security context", func() {
+			It("should make every container meet the restricted profile", func() {
+				deployment := &appsv1.Deployment{}
+				err := common.InjectRestrictedSecurityContext(object)
+				Expect(err).ShouldNot(HaveOccurred())
+				err = scheme.Scheme.Convert(object, deployment, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+				podSpec := deployment.Spec.Template.Spec
+				Expect(podSpec.SecurityContext.RunAsNonRoot).To(Equal(ptr.To(true)))
+				Expect(podSpec.SecurityContext.SeccompProfile.Type).To(Equal(corev1.SeccompProfileTypeRuntimeDefault))
+				for _, container := range podSpec.Containers {
+					Expect(container.SecurityContext.AllowPrivilegeEscalation).To(Equal(ptr.To(false)))
+					Expect(container.SecurityContext.Capabilities.Drop).To(ConsistOf(corev1.Capability("ALL")))
+					Expect(container.SecurityContext.Capabilities.Add).To(BeEmpty())
+				}
+				Expect(common.RestrictedViolations(&podSpec)).To(BeEmpty())
+			})
+		})
+		When("the workload is a documented exception", func() {
+			It("should leave the workload untouched", func() {
+				object.SetKind("DaemonSet")
+				object.SetName(common.SharedResourceNodeDaemonSetName)
+				expected := object.DeepCopy()
+				err := common.InjectRestrictedSecurityContext(object)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(object).To(Equal(expected))
+			})
+		})
+	})
+
+	Describe("Restricted violations", func() {
+		It("should report privileged containers and host paths", func() {
+			podSpec := &corev1.PodSpec{
+				Volumes: []corev1.Volume{{
+					Name: "host",
+					VolumeSource: corev1.VolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{Path: "/var/lib"},
+					},
+				}},
+				Containers: []corev1.Container{{
+					Name: "test",
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: ptr.To(true),
+					},
+				}},
+			}
+			Expect(common.RestrictedViolations(podSpec)).To(ContainElements(
+				"volume host mounts a host path",
+				"container test is privileged",
+				"container test allows privilege escalation",
+				"container test may run as root",
+			))
+		})
 	})
 
 	Describe("Inject annotations", func() {
@@ -110,4 +169,110 @@ var _ = Describe("Transformer", Label("transformer"), func() {
 			})
 		})
 	})
+
+	Describe("Inject trusted CA bundle", func() {
+		BeforeEach(func() {
+			object = &unstructured.Unstructured{}
+			deployment := &appsv1.Deployment{}
+			deployment.SetGroupVersionKind(schema.GroupVersionKind{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			})
+			deployment.SetName("test")
+			deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "test"}}
+			err := scheme.Scheme.Convert(deployment, object, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		When("the deployment name matches", func() {
+			It("should mount the trusted CA bundle in every container", func() {
+				deployment := &appsv1.Deployment{}
+				err := common.InjectTrustedCABundle([]string{"test"})(object)
+				Expect(err).ShouldNot(HaveOccurred())
+				err = scheme.Scheme.Convert(object, deployment, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(deployment.Spec.Template.Spec.Volumes).To(HaveLen(1))
+				Expect(deployment.Spec.Template.Spec.Volumes[0].ConfigMap.Name).To(Equal(common.TrustedCABundleConfigMapName))
+				Expect(deployment.Spec.Template.Spec.Containers[0].VolumeMounts).To(ConsistOf(corev1.VolumeMount{
+					Name:      common.TrustedCABundleVolumeName,
+					MountPath: common.TrustedCABundleMountPath,
+					ReadOnly:  true,
+				}))
+			})
+			It("should not mount the trusted CA bundle twice", func() {
+				deployment := &appsv1.Deployment{}
+				transformer := common.InjectTrustedCABundle([]string{"test"})
+				Expect(transformer(object)).To(Succeed())
+				Expect(transformer(object)).To(Succeed())
+				err := scheme.Scheme.Convert(object, deployment, nil)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(deployment.Spec.Template.Spec.Volumes).To(HaveLen(1))
+				Expect(deployment.Spec.Template.Spec.Containers[0].VolumeMounts).To(HaveLen(1))
+			})
+		})
+		When("the deployment name does not match", func() {
+			It("should not change the deployment", func() {
+				expected := object.DeepCopy()
+				err := common.InjectTrustedCABundle([]string{"other"})(object)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(object).To(Equal(expected))
+			})
+		})
+	})
+
+	Describe("Inject build strategy volume", func() {
+		var volume corev1.Volume
+		var mount corev1.VolumeMount
+		BeforeEach(func() {
+			object = &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "shipwright.io/v1alpha1",
+				"kind":       "ClusterBuildStrategy",
+				"metadata":   map[string]interface{}{"name": "test"},
+				"spec": map[string]interface{}{
+					"buildSteps": []interface{}{
+						map[string]interface{}{"name": "first"},
+						map[string]interface{}{"name": "second"},
+					},
+				},
+			}}
+			volume = corev1.Volume{
+				Name:         "test-volume",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}
+			mount = corev1.VolumeMount{Name: "test-volume", MountPath: "/test"}
+		})
+		When("the object is a build strategy", func() {
+			It("should add the volume and mount it in every step", func() {
+				err := common.InjectBuildStrategyVolume(volume, mount)(object)
+				Expect(err).ShouldNot(HaveOccurred())
+				volumes, _, _ := unstructured.NestedSlice(object.Object, "spec", "volumes")
+				Expect(volumes).To(HaveLen(1))
+				steps, _, _ := unstructured.NestedSlice(object.Object, "spec", "buildSteps")
+				for _, step := range steps {
+					Expect(step).To(HaveKeyWithValue("volumeMounts", ConsistOf(HaveKeyWithValue("mountPath", "/test"))))
+				}
+			})
+			It("should mount the volume once per mount path", func() {
+				file := corev1.VolumeMount{Name: "test-volume", MountPath: "/etc/test.json", SubPath: "test.json"}
+				err := common.InjectBuildStrategyVolume(volume, mount, file, mount)(object)
+				Expect(err).ShouldNot(HaveOccurred())
+				steps, _, _ := unstructured.NestedSlice(object.Object, "spec", "buildSteps")
+				for _, step := range steps {
+					Expect(step).To(HaveKeyWithValue("volumeMounts", ConsistOf(
+						HaveKeyWithValue("mountPath", "/test"),
+						HaveKeyWithValue("mountPath", "/etc/test.json"),
+					)))
+				}
+			})
+		})
+		When("the object is not a build strategy", func() {
+			It("should not change the object", func() {
+				object.SetKind("Build")
+				expected := object.DeepCopy()
+				err := common.InjectBuildStrategyVolume(volume, mount)(object)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(object).To(Equal(expected))
+			})
+		})
+	})
 })
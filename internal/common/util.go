@@ -1,6 +1,18 @@
 package common
 
-import "os"
+import (
+	"context"
+	"os"
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
 
 // FetchCurrentNamespaceName returns namespace name by using information stored as file
 // Returns default Openshift Builds namespace on error
@@ -14,3 +26,71 @@ func FetchCurrentNamespaceName() string {
 	}
 	return CurrentNamespaceName
 }
+
+// TargetNamespace returns the namespace where the components owned by the OpenShiftBuild are
+// installed. It defaults to the namespace of the operator if the owner does not set one.
+func TargetNamespace(owner client.Object) string {
+	if openshiftBuild, ok := owner.(*openshiftv1alpha1.OpenShiftBuild); ok && openshiftBuild.Spec.TargetNamespace != "" {
+		return openshiftBuild.Spec.TargetNamespace
+	}
+	if CurrentNamespaceName != "" {
+		return CurrentNamespaceName
+	}
+	return OpenShiftBuildNamespaceName
+}
+
+// IgnoreMissing returns nil if the error is caused by a missing object or API, such as the
+// Shipwright Build APIs when Shipwright Build is disabled, or the SecurityContextConstraints API
+// outside of OpenShift
+func IgnoreMissing(err error) error {
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+		return nil
+	}
+	return err
+}
+
+// MatchingNamespaces returns the names of the active namespaces matching the selector
+func MatchingNamespaces(ctx context.Context, reader client.Reader, labelSelector *metav1.LabelSelector) ([]string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	list := &corev1.NamespaceList{}
+	if err := reader.List(ctx, list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, namespace := range list.Items {
+		if namespace.DeletionTimestamp.IsZero() {
+			names = append(names, namespace.Name)
+		}
+	}
+	return names, nil
+}
+
+// platformNamespacePrefixes are the prefixes of the namespaces reserved to the platform. Users
+// cannot request projects with these prefixes.
+var platformNamespacePrefixes = []string{"openshift-", "kube-"}
+
+// IsPlatformNamespace returns true for the namespaces of Kubernetes and OpenShift, which are never
+// onboarded, and whose objects are admitted unchanged by the webhooks
+func IsPlatformNamespace(name string) bool {
+	for _, prefix := range platformNamespacePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return name == "default" || name == "openshift"
+}
+
+// KindOf returns the kind of a typed or unstructured object
+func KindOf(scheme *runtime.Scheme, object client.Object) string {
+	if kind := object.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	gvks, _, err := scheme.ObjectKinds(object)
+	if err != nil || len(gvks) == 0 {
+		return ""
+	}
+	return gvks[0].Kind
+}
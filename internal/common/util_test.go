@@ -0,0 +1,86 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTargetNamespace(t *testing.T) {
+	RegisterFailHandler(Fail)
+	t.Run("owner sets the target namespace", func(t *testing.T) {
+		owner := &openshiftv1alpha1.OpenShiftBuild{}
+		owner.Spec.TargetNamespace = "builds-system"
+		Expect(TargetNamespace(owner)).To(Equal("builds-system"))
+	})
+	t.Run("owner does not set the target namespace", func(t *testing.T) {
+		CurrentNamespaceName = "operators"
+		defer func() { CurrentNamespaceName = "" }()
+		Expect(TargetNamespace(&openshiftv1alpha1.OpenShiftBuild{})).To(Equal("operators"))
+		Expect(TargetNamespace(&unstructured.Unstructured{})).To(Equal("operators"))
+	})
+	t.Run("operator namespace is unknown", func(t *testing.T) {
+		Expect(TargetNamespace(&openshiftv1alpha1.OpenShiftBuild{})).To(Equal(OpenShiftBuildNamespaceName))
+	})
+}
+
+func TestIgnoreMissing(t *testing.T) {
+	RegisterFailHandler(Fail)
+	Expect(IgnoreMissing(nil)).To(Succeed())
+	Expect(IgnoreMissing(apierrors.NewNotFound(schema.GroupResource{Resource: "builds"}, "app"))).To(Succeed())
+	Expect(IgnoreMissing(&meta.NoKindMatchError{GroupKind: schema.GroupKind{Kind: "Build"}})).To(Succeed())
+	Expect(IgnoreMissing(errors.New("unavailable"))).To(MatchError("unavailable"))
+}
+
+func TestMatchingNamespaces(t *testing.T) {
+	RegisterFailHandler(Fail)
+	ctx := context.Background()
+	fakeClient := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"builds": "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:              "team-c",
+			Labels:            map[string]string{"builds": "true"},
+			DeletionTimestamp: ptr.To(metav1.Now()),
+			Finalizers:        []string{"kubernetes"},
+		}},
+	).Build()
+	names, err := MatchingNamespaces(ctx, fakeClient, &metav1.LabelSelector{MatchLabels: map[string]string{"builds": "true"}})
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(names).To(ConsistOf("team-a"))
+}
+
+func TestIsPlatformNamespace(t *testing.T) {
+	RegisterFailHandler(Fail)
+	for _, name := range []string{"default", "openshift", "openshift-builds", "kube-system"} {
+		Expect(IsPlatformNamespace(name)).To(BeTrue(), name)
+	}
+	for _, name := range []string{"team-a", "openshift1", "kubevirt"} {
+		Expect(IsPlatformNamespace(name)).To(BeFalse(), name)
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	RegisterFailHandler(Fail)
+	scheme := runtime.NewScheme()
+	Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	Expect(KindOf(scheme, &corev1.ServiceAccount{})).To(Equal("ServiceAccount"))
+	build := &unstructured.Unstructured{}
+	build.SetAPIVersion("shipwright.io/v1alpha1")
+	build.SetKind("Build")
+	Expect(KindOf(scheme, build)).To(Equal("Build"))
+	Expect(KindOf(scheme, &corev1.Secret{TypeMeta: metav1.TypeMeta{Kind: "Secret"}})).To(Equal("Secret"))
+}
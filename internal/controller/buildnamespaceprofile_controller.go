@@ -0,0 +1,318 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/buildnamespace"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// registryCredentialsRequeueDelay is the delay before checking again whether OpenShift generated
+// the internal registry credentials of a build ServiceAccount
+const registryCredentialsRequeueDelay = 10 * time.Second
+
+// BuildNamespaceProfileReconciler reconciles a BuildNamespaceProfile object
+type BuildNamespaceProfileReconciler struct {
+	Client     client.Client
+	Onboarding *buildnamespace.Onboarding
+}
+
+// namespaceResult is the outcome of reconciling a namespace for a profile
+type namespaceResult int
+
+const (
+	// namespaceSkipped is a namespace the profile does not select
+	namespaceSkipped namespaceResult = iota
+	// namespaceOnboarded is a namespace onboarded by the profile
+	namespaceOnboarded
+	// namespacePending is a namespace onboarded by the profile, waiting for the internal registry
+	// credentials
+	namespacePending
+	// namespaceConflict is a namespace selected by the profile and onboarded by another profile
+	namespaceConflict
+)
+
+// Reconcile onboards the namespaces matching the profile, and offboards the namespaces which stop
+// matching it. A namespace is only onboarded by the first profile matching it. Requests with a
+// namespace only reconcile that namespace, as long as the profile is ready at its generation.
+func (r *BuildNamespaceProfileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("name", req.Name)
+
+	profile := &openshiftv1alpha1.BuildNamespaceProfile{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: req.Name}, profile); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !profile.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.HandleDeletion(ctx, profile)
+	}
+	if controllerutil.AddFinalizer(profile, common.BuildNamespaceProfileFinalizerName) {
+		if err := r.Client.Update(ctx, profile); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	ready := apimeta.FindStatusCondition(profile.Status.Conditions, openshiftv1alpha1.ConditionReady)
+	if req.Namespace != "" && ready != nil && ready.Status == metav1.ConditionTrue &&
+		ready.ObservedGeneration == profile.Generation {
+		done, result, err := r.ReconcileNamespace(ctx, profile, req.Namespace)
+		if done {
+			return result, err
+		}
+		logger.Info("Reconciling every namespace of the profile", "namespace", req.Namespace)
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := r.Client.List(ctx, namespaces); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	original := profile.Status.DeepCopy()
+	onboarded := []string{}
+	conflicts := []string{}
+	pending := false
+	var reconcileErr error
+	for i := range namespaces.Items {
+		namespace := &namespaces.Items[i]
+		result, err := r.onboard(ctx, profile, namespace)
+		if err != nil {
+			reconcileErr = err
+			continue
+		}
+		switch result {
+		case namespaceConflict:
+			conflicts = append(conflicts, namespace.Name)
+		case namespacePending:
+			pending = true
+			onboarded = append(onboarded, namespace.Name)
+		case namespaceOnboarded:
+			onboarded = append(onboarded, namespace.Name)
+		}
+	}
+
+	slices.Sort(onboarded)
+	profile.Status.Namespaces = onboarded
+	profile.Status.OnboardedNamespaces = int32(len(onboarded))
+	condition := metav1.Condition{
+		Type:    openshiftv1alpha1.ConditionReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Success",
+		Message: fmt.Sprintf("Onboarded %d namespaces", len(onboarded)),
+	}
+	switch {
+	case reconcileErr != nil:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Failed"
+		condition.Message = fmt.Sprintf("Failed to reconcile BuildNamespaceProfile: %v", reconcileErr)
+	case len(conflicts) > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Conflict"
+		condition.Message = fmt.Sprintf("Namespaces onboarded by another profile: %s", strings.Join(conflicts, ", "))
+	}
+	condition.ObservedGeneration = profile.Generation
+	apimeta.SetStatusCondition(&profile.Status.Conditions, condition)
+	if !equality.Semantic.DeepEqual(original, &profile.Status) {
+		if err := r.Client.Status().Update(ctx, profile); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if pending && reconcileErr == nil {
+		return ctrl.Result{RequeueAfter: registryCredentialsRequeueDelay}, nil
+	}
+	return ctrl.Result{}, reconcileErr
+}
+
+// ReconcileNamespace onboards or offboards a single namespace and updates the namespaces of the
+// profile status. Returns false when every namespace must be reconciled instead, because the
+// namespace cannot be onboarded or conflicts with another profile, which changes the Ready
+// condition.
+func (r *BuildNamespaceProfileReconciler) ReconcileNamespace(ctx context.Context, profile *openshiftv1alpha1.BuildNamespaceProfile, name string) (bool, ctrl.Result, error) {
+	namespace := &corev1.Namespace{}
+	result := namespaceSkipped
+	err := r.Client.Get(ctx, client.ObjectKey{Name: name}, namespace)
+	switch {
+	case err == nil:
+		if result, err = r.onboard(ctx, profile, namespace); err != nil || result == namespaceConflict {
+			return false, ctrl.Result{}, nil
+		}
+	case !apierrors.IsNotFound(err):
+		return true, ctrl.Result{}, err
+	}
+
+	original := profile.Status.DeepCopy()
+	onboarded := slices.DeleteFunc(slices.Clone(profile.Status.Namespaces), func(namespace string) bool {
+		return namespace == name
+	})
+	if result == namespaceOnboarded || result == namespacePending {
+		onboarded = append(onboarded, name)
+	}
+	slices.Sort(onboarded)
+	profile.Status.Namespaces = onboarded
+	profile.Status.OnboardedNamespaces = int32(len(onboarded))
+	apimeta.SetStatusCondition(&profile.Status.Conditions, metav1.Condition{
+		Type:               openshiftv1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Success",
+		Message:            fmt.Sprintf("Onboarded %d namespaces", len(onboarded)),
+		ObservedGeneration: profile.Generation,
+	})
+	if !equality.Semantic.DeepEqual(original, &profile.Status) {
+		if err := r.Client.Status().Update(ctx, profile); err != nil {
+			return true, ctrl.Result{}, err
+		}
+	}
+	if result == namespacePending {
+		return true, ctrl.Result{RequeueAfter: registryCredentialsRequeueDelay}, nil
+	}
+	return true, ctrl.Result{}, nil
+}
+
+// onboard onboards the namespace if the profile selects it, or offboards it if the profile
+// onboarded it and no longer selects it
+func (r *BuildNamespaceProfileReconciler) onboard(ctx context.Context, profile *openshiftv1alpha1.BuildNamespaceProfile, namespace *corev1.Namespace) (namespaceResult, error) {
+	logger := log.FromContext(ctx).WithValues("name", profile.Name, "namespace", namespace.Name)
+
+	owner := namespace.Labels[common.BuildNamespaceProfileLabel]
+	matches, err := buildnamespace.Matches(profile, namespace)
+	if err != nil {
+		return namespaceSkipped, err
+	}
+	switch {
+	case matches && owner != "" && owner != profile.Name:
+		return namespaceConflict, nil
+	case matches:
+		err := r.Onboarding.Onboard(ctx, profile, namespace)
+		if errors.Is(err, buildnamespace.ErrRegistryCredentialsPending) {
+			logger.Info("Waiting for the internal registry credentials")
+			return namespacePending, nil
+		}
+		if err != nil {
+			logger.Error(err, "Failed to onboard namespace")
+			return namespaceSkipped, err
+		}
+		return namespaceOnboarded, nil
+	case owner == profile.Name && namespace.DeletionTimestamp.IsZero():
+		if err := r.Onboarding.Offboard(ctx, profile, namespace); err != nil {
+			logger.Error(err, "Failed to offboard namespace")
+			return namespaceSkipped, err
+		}
+		logger.Info("Offboarded namespace")
+	}
+	return namespaceSkipped, nil
+}
+
+// HandleDeletion offboards every namespace of the profile and removes its finalizer. Objects
+// created in the namespaces are also garbage collected with the profile.
+func (r *BuildNamespaceProfileReconciler) HandleDeletion(ctx context.Context, profile *openshiftv1alpha1.BuildNamespaceProfile) error {
+	namespaces := &corev1.NamespaceList{}
+	if err := r.Client.List(ctx, namespaces,
+		client.MatchingLabels{common.BuildNamespaceProfileLabel: profile.Name}); err != nil {
+		return err
+	}
+	for i := range namespaces.Items {
+		if err := r.Onboarding.Offboard(ctx, profile, &namespaces.Items[i]); err != nil {
+			return err
+		}
+	}
+	if controllerutil.RemoveFinalizer(profile, common.BuildNamespaceProfileFinalizerName) {
+		return r.Client.Update(ctx, profile)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BuildNamespaceProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// the namespace of an object the profile owns is reconciled when the object is modified or
+	// deleted
+	enqueueOwnerNamespace := handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, object client.Object) []reconcile.Request {
+			owner := metav1.GetControllerOf(object)
+			if owner == nil || owner.Kind != "BuildNamespaceProfile" ||
+				owner.APIVersion != openshiftv1alpha1.GroupVersion.String() {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: object.GetNamespace(), Name: owner.Name}}}
+		},
+	)
+
+	// a namespace is reconciled by the profiles selecting or onboarding it when it is created,
+	// relabelled, or deleted. Updates are mapped from both the old and the new namespace, so that
+	// the profiles a namespace stops matching offboard it.
+	enqueueProfiles := handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, object client.Object) []reconcile.Request {
+			namespace, ok := object.(*corev1.Namespace)
+			if !ok {
+				return nil
+			}
+			profiles := &openshiftv1alpha1.BuildNamespaceProfileList{}
+			if err := r.Client.List(ctx, profiles); err != nil {
+				return nil
+			}
+			requests := []reconcile.Request{}
+			for i := range profiles.Items {
+				profile := &profiles.Items[i]
+				matches, err := buildnamespace.Matches(profile, namespace)
+				if err != nil || matches || namespace.Labels[common.BuildNamespaceProfileLabel] == profile.Name {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: client.ObjectKey{Namespace: namespace.Name, Name: profile.Name},
+					})
+				}
+			}
+			return requests
+		},
+	)
+
+	// the namespace of a profile is reconciled when the registry credentials OpenShift generates for
+	// its ServiceAccount are created, rotated, or deleted, so that the push secret is copied again,
+	// and when the push secret is modified. Only the metadata of the secrets is cached.
+	enqueueSecretProfile := handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, object client.Object) []reconcile.Request {
+			name := object.GetLabels()[common.BuildNamespaceProfileLabel]
+			if name == "" && buildnamespace.IsRegistryCredentials(object) {
+				namespace := &corev1.Namespace{}
+				if err := r.Client.Get(ctx, client.ObjectKey{Name: object.GetNamespace()}, namespace); err != nil {
+					return nil
+				}
+				name = namespace.Labels[common.BuildNamespaceProfileLabel]
+			}
+			if name == "" {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: object.GetNamespace(), Name: name}}}
+		},
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&openshiftv1alpha1.BuildNamespaceProfile{}).
+		Watches(&corev1.ServiceAccount{}, enqueueOwnerNamespace).
+		Watches(&rbacv1.RoleBinding{}, enqueueOwnerNamespace).
+		Watches(&corev1.LimitRange{}, enqueueOwnerNamespace).
+		Watches(&corev1.ResourceQuota{}, enqueueOwnerNamespace).
+		Watches(&corev1.Namespace{}, enqueueProfiles,
+			builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		WatchesMetadata(&corev1.Secret{}, enqueueSecretProfile).
+		Complete(r)
+}
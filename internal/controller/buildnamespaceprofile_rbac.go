@@ -0,0 +1,11 @@
+package controller
+
+//+kubebuilder:rbac:groups=operator.openshift.io,resources=buildnamespaceprofiles,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=operator.openshift.io,resources=buildnamespaceprofiles/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=operator.openshift.io,resources=buildnamespaceprofiles/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=core,resources=serviceaccounts;resourcequotas;limitranges,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,resourceNames=shipwright-build-aggregate-edit;shipwright-build-aggregate-view;system:image-builder,verbs=bind
+//+kubebuilder:rbac:groups=shipwright.io,resources=builds;buildstrategies,verbs=get;list;watch;create;update;patch;delete
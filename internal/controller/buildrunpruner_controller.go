@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"context"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/pruner"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// BuildRunPrunerReconciler periodically deletes the completed BuildRuns exceeding the retention
+// configured in the OpenShiftBuild
+type BuildRunPrunerReconciler struct {
+	Client client.Client
+	Pruner *pruner.Pruner
+}
+
+// Reconcile runs a pruning pass and schedules the next one
+func (r *BuildRunPrunerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("name", req.Name)
+
+	owner := &openshiftv1alpha1.OpenShiftBuild{}
+	if err := r.Client.Get(ctx, req.NamespacedName, owner); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !owner.DeletionTimestamp.IsZero() || !pruner.IsEnabled(owner) {
+		return ctrl.Result{}, nil
+	}
+
+	deleted, err := r.Pruner.Prune(ctx, owner)
+	if err != nil {
+		logger.Error(err, "Failed to prune BuildRuns")
+		return ctrl.Result{}, err
+	}
+	logger.Info("Pruned BuildRuns", "deleted", deleted)
+	return ctrl.Result{RequeueAfter: pruner.Interval(owner)}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BuildRunPrunerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("buildrun-pruner").
+		For(&openshiftv1alpha1.OpenShiftBuild{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
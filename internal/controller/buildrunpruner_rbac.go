@@ -0,0 +1,3 @@
+package controller
+
+//+kubebuilder:rbac:groups=shipwright.io,resources=buildruns,verbs=get;list;delete
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/queue"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// buildRunQueueResyncInterval is the interval at which the queued BuildRuns are released when no
+// build pod changes
+const buildRunQueueResyncInterval = time.Minute
+
+// BuildRunQueueReconciler releases the pods of the queued BuildRuns within the concurrency limits
+// of the OpenShiftBuild
+type BuildRunQueueReconciler struct {
+	Client client.Client
+	Queue  *queue.Queue
+}
+
+// Reconcile releases the queued BuildRuns fitting in the limits, or every queued BuildRun when the
+// limits are disabled or the OpenShiftBuild is deleted
+func (r *BuildRunQueueReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("name", req.Name)
+
+	owner := &openshiftv1alpha1.OpenShiftBuild{}
+	if err := r.Client.Get(ctx, req.NamespacedName, owner); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		owner = nil
+	}
+
+	released, err := r.Queue.Release(ctx, owner)
+	if err != nil {
+		logger.Error(err, "Failed to release queued BuildRuns")
+		return ctrl.Result{}, err
+	}
+	if released > 0 {
+		logger.Info("Released queued BuildRuns", "released", released)
+	}
+	if !queue.IsEnabled(owner) {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: buildRunQueueResyncInterval}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager. Every change of a build pod reconciles
+// the OpenShiftBuild, so that the queue is released as soon as a BuildRun completes.
+func (r *BuildRunQueueReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	enqueueOwner := handler.EnqueueRequestsFromMapFunc(
+		func(context.Context, client.Object) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: common.OpenShiftBuildResourceName}}}
+		},
+	)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("buildrun-queue").
+		For(&openshiftv1alpha1.OpenShiftBuild{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Pod{}, enqueueOwner, builder.WithPredicates(predicate.NewPredicateFuncs(queue.IsBuildPod))).
+		Complete(r)
+}
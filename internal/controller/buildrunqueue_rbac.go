@@ -0,0 +1,3 @@
+package controller
+
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;patch
@@ -21,28 +21,53 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	manifestivalclient "github.com/manifestival/controller-runtime-client"
 	"github.com/manifestival/manifestival"
+	configv1 "github.com/openshift/api/config/v1"
 	"github.com/redhat-openshift-builds/operator/internal/sharedresource"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
 	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/networkpolicy"
 	shipwrightbuild "github.com/redhat-openshift-builds/operator/internal/shipwright/build"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/registry"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/strategy"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/userns"
 	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
 )
 
+// sharedResourceHealthInterval is how often the health of the Shared Resource CSI Driver nodes is
+// refreshed
+const sharedResourceHealthInterval = time.Minute
+
+// strategiesResyncInterval is how often deprecated strategies kept for the Builds referencing them,
+// namespaced copies of the strategies, and the namespaces allowed to run builds in a user namespace,
+// are checked again
+const strategiesResyncInterval = 10 * time.Minute
+
 // OpenShiftBuildReconciler reconciles a OpenShiftBuild object
 type OpenShiftBuildReconciler struct {
 	APIReader      client.Reader
@@ -51,6 +76,10 @@ type OpenShiftBuildReconciler struct {
 	Logger         logr.Logger
 	SharedResource *sharedresource.SharedResource
 	Shipwright     *shipwrightbuild.ShipwrightBuild
+	BuildStrategy  *strategy.BuildStrategy
+	RegistryConfig *registry.RegistryConfig
+	UserNamespace  *userns.UserNamespace
+	NetworkPolicy  *networkpolicy.NetworkPolicy
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -90,8 +119,49 @@ func (r *OpenShiftBuildReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, r.HandleDeletion(ctx, openShiftBuild)
 	}
 
+	// Create the namespace of the components
+	if err := r.ReconcileTargetNamespace(ctx, openShiftBuild); err != nil {
+		logger.Error(err, "Failed to reconcile target namespace")
+		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
+			Type:    openshiftv1alpha1.ConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: fmt.Sprintf("Failed to reconcile OpenShiftBuild: %v", err),
+		})
+		return ctrl.Result{}, r.Client.Status().Update(ctx, openShiftBuild)
+	}
+
+	// Reconcile the NetworkPolicies of the target namespace
+	if err := r.ReconcileNetworkPolicy(ctx, openShiftBuild); err != nil {
+		logger.Error(err, "Failed to reconcile NetworkPolicies")
+		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
+			Type:    openshiftv1alpha1.ConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: fmt.Sprintf("Failed to reconcile OpenShiftBuild: %v", err),
+		})
+		return ctrl.Result{}, r.Client.Status().Update(ctx, openShiftBuild)
+	}
+
 	// Reconcile Shipwright Build
 	if err := r.ReconcileShipwrightBuild(ctx, openShiftBuild); err != nil {
+		conflictErr := &shipwrightbuild.ConflictError{}
+		if errors.As(err, &conflictErr) {
+			logger.Info("ShipwrightBuild conflicts with an existing installation", "objects", conflictErr.Objects)
+			apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
+				Type:    openshiftv1alpha1.ConditionConflict,
+				Status:  metav1.ConditionTrue,
+				Reason:  "ExistingShipwrightInstallation",
+				Message: fmt.Sprintf("%v. Set spec.shipwright.build.existingInstallPolicy to Adopt to take ownership.", err),
+			})
+			apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
+				Type:    openshiftv1alpha1.ConditionReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  "Conflict",
+				Message: "Shipwright Build conflicts with an existing installation",
+			})
+			return ctrl.Result{}, r.Client.Status().Update(ctx, openShiftBuild)
+		}
 		logger.Error(err, "Failed to reconcile ShipwrightBuild")
 		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
 			Type:    openshiftv1alpha1.ConditionReady,
@@ -104,6 +174,23 @@ func (r *OpenShiftBuildReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	// Reconcile Shared Resources
 	if err := r.ReconcileSharedResource(ctx, openShiftBuild); err != nil {
+		conflictErr := &sharedresource.ConflictError{}
+		if errors.As(err, &conflictErr) {
+			logger.Info("SharedResource conflicts with an existing installation", "objects", conflictErr.Objects)
+			apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
+				Type:    openshiftv1alpha1.ConditionConflict,
+				Status:  metav1.ConditionTrue,
+				Reason:  "ExistingInstallation",
+				Message: fmt.Sprintf("%v. Set spec.sharedResource.existingInstallPolicy to Adopt to take ownership.", err),
+			})
+			apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
+				Type:    openshiftv1alpha1.ConditionReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  "Conflict",
+				Message: "Shared Resource CSI Driver conflicts with an existing installation",
+			})
+			return ctrl.Result{}, r.Client.Status().Update(ctx, openShiftBuild)
+		}
 		logger.Error(err, "Failed to reconcile SharedResource")
 		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
 			Type:    openshiftv1alpha1.ConditionReady,
@@ -113,6 +200,79 @@ func (r *OpenShiftBuildReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		})
 		return ctrl.Result{}, r.Client.Status().Update(ctx, openShiftBuild)
 	}
+	apimeta.RemoveStatusCondition(&openShiftBuild.Status.Conditions, openshiftv1alpha1.ConditionConflict)
+
+	// Report Shared Resource CSI Driver health
+	if err := r.ReconcileSharedResourceHealth(ctx, openShiftBuild); err != nil {
+		logger.Error(err, "Failed to check SharedResource health")
+		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
+			Type:    openshiftv1alpha1.ConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: fmt.Sprintf("Failed to reconcile OpenShiftBuild: %v", err),
+		})
+		return ctrl.Result{}, r.Client.Status().Update(ctx, openShiftBuild)
+	}
+
+	// Report the compliance of the operand workloads with the restricted Pod Security profile
+	if err := r.ReconcilePodSecurity(ctx, openShiftBuild); err != nil {
+		logger.Error(err, "Failed to audit operand pod security")
+		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
+			Type:    openshiftv1alpha1.ConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: fmt.Sprintf("Failed to reconcile OpenShiftBuild: %v", err),
+		})
+		return ctrl.Result{}, r.Client.Status().Update(ctx, openShiftBuild)
+	}
+
+	// Reconcile cluster registry configuration for build strategies
+	if err := r.ReconcileRegistryConfig(ctx, openShiftBuild); err != nil {
+		logger.Error(err, "Failed to reconcile registry configuration")
+		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
+			Type:    openshiftv1alpha1.ConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: fmt.Sprintf("Failed to reconcile OpenShiftBuild: %v", err),
+		})
+		return ctrl.Result{}, r.Client.Status().Update(ctx, openShiftBuild)
+	}
+
+	// Reconcile the SecurityContextConstraints of builds running in a user namespace
+	if err := r.ReconcileUserNamespace(ctx, openShiftBuild); err != nil {
+		logger.Error(err, "Failed to reconcile user namespace SecurityContextConstraints")
+		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
+			Type:    openshiftv1alpha1.ConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: fmt.Sprintf("Failed to reconcile OpenShiftBuild: %v", err),
+		})
+		return ctrl.Result{}, r.Client.Status().Update(ctx, openShiftBuild)
+	}
+
+	// Reconcile Shipwright Build strategies
+	requeue, err := r.ReconcileBuildStrategy(ctx, openShiftBuild)
+	if err != nil {
+		logger.Error(err, "Failed to reconcile build strategies")
+		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
+			Type:    openshiftv1alpha1.ConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: fmt.Sprintf("Failed to reconcile OpenShiftBuild: %v", err),
+		})
+		return ctrl.Result{}, r.Client.Status().Update(ctx, openShiftBuild)
+	}
+	if requeue {
+		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
+			Type:    openshiftv1alpha1.ConditionReady,
+			Status:  metav1.ConditionUnknown,
+			Reason:  "Waiting",
+			Message: "Waiting for Shipwright Build APIs to be installed",
+		})
+		return ctrl.Result{Requeue: true}, r.Client.Status().Update(ctx, openShiftBuild)
+	}
+
+	inUse := r.ReportStrategiesInUse(openShiftBuild)
 
 	// Update status
 	apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
@@ -127,6 +287,13 @@ func (r *OpenShiftBuildReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	}
 
 	logger.Info("Finished reconciliation")
+	if openShiftBuild.Spec.SharedResource.State == openshiftv1alpha1.Enabled {
+		return ctrl.Result{RequeueAfter: sharedResourceHealthInterval}, nil
+	}
+	if inUse || strategy.NamespacedStrategiesSelector(openShiftBuild) != nil ||
+		userns.NamespaceSelector(openShiftBuild) != nil {
+		return ctrl.Result{RequeueAfter: strategiesResyncInterval}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -155,6 +322,9 @@ func (r *OpenShiftBuildReconciler) BootstrapOpenShiftBuild(ctx context.Context,
 func (r *OpenShiftBuildReconciler) CreateOrUpdate(ctx context.Context, client client.Client, object *openshiftv1alpha1.OpenShiftBuild) (controllerutil.OperationResult, error) {
 	return ctrl.CreateOrUpdate(ctx, client, object, func() error {
 		controllerutil.AddFinalizer(object, common.OpenShiftBuildFinalizerName)
+		if object.Spec.TargetNamespace == "" {
+			object.Spec.TargetNamespace = common.TargetNamespace(object)
+		}
 		if object.Spec.Shipwright == nil {
 			object.Spec.Shipwright = &openshiftv1alpha1.Shipwright{
 				Build: &openshiftv1alpha1.ShipwrightBuild{
@@ -176,7 +346,7 @@ func (r *OpenShiftBuildReconciler) ReconcileSharedResource(ctx context.Context,
 	logger := log.FromContext(ctx).WithValues("name", openshiftBuild.ObjectMeta.Name)
 
 	logger.Info("Reconciling SharedResource...")
-	if err := r.SharedResource.Reconcile(openshiftBuild); err != nil {
+	if err := r.SharedResource.Reconcile(ctx, openshiftBuild); err != nil {
 		logger.Error(err, "Failed reconciling SharedResource...")
 		return err
 	}
@@ -184,6 +354,63 @@ func (r *OpenShiftBuildReconciler) ReconcileSharedResource(ctx context.Context,
 	return nil
 }
 
+// ReconcileSharedResourceHealth reports the registration of the Shared Resource CSI Driver on
+// every node, and sets the Degraded condition when too many nodes are unhealthy
+func (r *OpenShiftBuildReconciler) ReconcileSharedResourceHealth(ctx context.Context, openshiftBuild *openshiftv1alpha1.OpenShiftBuild) error {
+	if openshiftBuild.Spec.SharedResource.State != openshiftv1alpha1.Enabled {
+		openshiftBuild.Status.SharedResource = nil
+		apimeta.RemoveStatusCondition(&openshiftBuild.Status.Conditions, openshiftv1alpha1.ConditionDegraded)
+		return nil
+	}
+
+	status, err := sharedresource.NodeHealth(ctx, r.APIReader, common.TargetNamespace(openshiftBuild))
+	if err != nil {
+		return err
+	}
+	openshiftBuild.Status.SharedResource = status
+
+	if !sharedresource.IsDegraded(status) {
+		apimeta.SetStatusCondition(&openshiftBuild.Status.Conditions, metav1.Condition{
+			Type:    openshiftv1alpha1.ConditionDegraded,
+			Status:  metav1.ConditionFalse,
+			Reason:  "AsExpected",
+			Message: fmt.Sprintf("Shared Resource CSI Driver is registered on %d of %d nodes", status.RegisteredNodes, status.ExpectedNodes),
+		})
+		return nil
+	}
+	nodes := []string{}
+	for _, node := range status.UnhealthyNodes {
+		nodes = append(nodes, fmt.Sprintf("%s (%s)", node.Name, node.Reason))
+	}
+	apimeta.SetStatusCondition(&openshiftBuild.Status.Conditions, metav1.Condition{
+		Type:   openshiftv1alpha1.ConditionDegraded,
+		Status: metav1.ConditionTrue,
+		Reason: "NodesUnhealthy",
+		Message: fmt.Sprintf("Shared Resource CSI Driver is not available on %d of %d nodes: %s",
+			len(status.UnhealthyNodes), status.ExpectedNodes, strings.Join(nodes, ", ")),
+	})
+	return nil
+}
+
+// ReconcilePodSecurity records in the status whether every operand Deployment and DaemonSet meets
+// the restricted Pod Security profile, and the documented exceptions
+func (r *OpenShiftBuildReconciler) ReconcilePodSecurity(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	logger := log.FromContext(ctx).WithValues("name", owner.Name)
+
+	report, err := common.Compliance(ctx, r.APIReader, common.TargetNamespace(owner))
+	if err != nil {
+		return err
+	}
+	for _, workload := range report {
+		if workload.State == openshiftv1alpha1.NonCompliant {
+			logger.Info("Operand does not meet the restricted Pod Security profile",
+				"kind", workload.Kind, "workload", workload.Name, "violations", workload.Message)
+		}
+	}
+	owner.Status.Compliance = report
+	return nil
+}
+
 // BootStrapSharedResource initializes the manifestival to apply Shared Resources
 func (r *OpenShiftBuildReconciler) setupSharedResource(mgr ctrl.Manager) error {
 	// Initialize Manifestival
@@ -203,21 +430,171 @@ func (r *OpenShiftBuildReconciler) setupSharedResource(mgr ctrl.Manager) error {
 	}
 
 	// Initialize Shared Resource
-	r.SharedResource = sharedresource.New(sharedManifest)
+	r.SharedResource = sharedresource.New(mgr.GetClient(), sharedManifest)
+	return nil
+}
+
+// setupBuildStrategy initializes the manifestival to apply Shipwright Build strategies
+func (r *OpenShiftBuildReconciler) setupBuildStrategy(mgr ctrl.Manager) error {
+	// Initialize Manifestival
+	manifestivalOptions := []manifestival.Option{
+		manifestival.UseLogger(r.Logger),
+		manifestival.UseClient(manifestivalclient.NewClient(mgr.GetClient())),
+	}
+
+	// Shipwright Build strategies manifests
+	strategyManifestPath := common.ShipwrightBuildStrategyManifestPath
+	if path, ok := os.LookupEnv(common.ShipwrightBuildStrategyManifestPathEnv); ok {
+		strategyManifestPath = path
+	}
+	strategyManifest, err := manifestival.NewManifest(strategyManifestPath, manifestivalOptions...)
+	if err != nil {
+		return err
+	}
+
+	// Initialize Build Strategy
+	r.BuildStrategy = strategy.New(mgr.GetClient(), strategyManifest)
+	r.BuildStrategy.Logger = r.Logger
+	r.BuildStrategy.Recorder = mgr.GetEventRecorderFor("openshift-builds-operator")
+	return nil
+}
+
+// ReconcileBuildStrategy applies or deletes the Shipwright Build strategies based on the
+// Shipwright Build state. Returns true if a requeue is required.
+func (r *OpenShiftBuildReconciler) ReconcileBuildStrategy(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) (bool, error) {
+	logger := log.FromContext(ctx).WithValues("name", owner.Name)
+
+	logger.Info("Reconciling build strategies...")
+	switch owner.Spec.Shipwright.Build.State {
+	case openshiftv1alpha1.Enabled:
+		return r.BuildStrategy.Reconcile(ctx, owner)
+	case openshiftv1alpha1.Disabled:
+		return false, r.BuildStrategy.Remove(ctx, owner)
+	default:
+		return false, errors.New("unknown component state")
+	}
+}
+
+// ReportStrategiesInUse sets the StrategiesInUse condition when deprecated strategies are kept
+// because Builds reference them. Returns true if the condition is set.
+func (r *OpenShiftBuildReconciler) ReportStrategiesInUse(owner *openshiftv1alpha1.OpenShiftBuild) bool {
+	names := []string{}
+	for _, status := range owner.Status.Strategies {
+		if status.State != openshiftv1alpha1.StrategyDeprecated {
+			continue
+		}
+		if status.Namespace != "" {
+			names = append(names, status.Namespace+"/"+status.Name)
+		} else {
+			names = append(names, status.Name)
+		}
+	}
+	if len(names) == 0 {
+		apimeta.RemoveStatusCondition(&owner.Status.Conditions, openshiftv1alpha1.ConditionStrategiesInUse)
+		return false
+	}
+	apimeta.SetStatusCondition(&owner.Status.Conditions, metav1.Condition{
+		Type:   openshiftv1alpha1.ConditionStrategiesInUse,
+		Status: metav1.ConditionTrue,
+		Reason: "DeprecatedStrategiesReferenced",
+		Message: fmt.Sprintf("Deprecated strategies are still referenced by Builds: %s. "+
+			"See status.strategies for details.", strings.Join(names, ", ")),
+	})
+	return true
+}
+
+// ReconcileRegistryConfig renders or deletes the cluster registry configuration mounted by the
+// build strategies based on the Shipwright Build state
+func (r *OpenShiftBuildReconciler) ReconcileRegistryConfig(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	logger := log.FromContext(ctx).WithValues("name", owner.Name)
+
+	switch owner.Spec.Shipwright.Build.State {
+	case openshiftv1alpha1.Enabled:
+		result, err := r.RegistryConfig.CreateOrUpdate(ctx, owner)
+		if err != nil {
+			return err
+		}
+		logger.Info("Registry configuration", "result", result)
+	case openshiftv1alpha1.Disabled:
+		if err := r.RegistryConfig.Delete(ctx, owner); err != nil {
+			return err
+		}
+		logger.Info("Registry configuration", "result", "deleted")
+	default:
+		return errors.New("unknown component state")
+	}
+
+	return nil
+}
+
+// ReconcileUserNamespace creates or deletes the SecurityContextConstraints of builds running in a
+// user namespace, and its binding to the build service accounts, based on the user namespaces state
+func (r *OpenShiftBuildReconciler) ReconcileUserNamespace(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	logger := log.FromContext(ctx).WithValues("name", owner.Name)
+
+	if owner.Spec.Shipwright.Build.State == openshiftv1alpha1.Enabled && userns.IsEnabled(owner) {
+		status, err := r.UserNamespace.CreateOrUpdate(ctx, owner)
+		if err != nil {
+			return err
+		}
+		owner.Status.UserNamespaces = status
+		logger.Info("User namespace SecurityContextConstraints", "result", "applied", "namespaces", status.Namespaces)
+		return nil
+	}
+	if err := r.UserNamespace.Delete(ctx); err != nil {
+		return err
+	}
+	owner.Status.UserNamespaces = nil
+	logger.Info("User namespace SecurityContextConstraints", "result", "deleted")
+	return nil
+}
+
+// ReconcileNetworkPolicy applies or deletes the NetworkPolicies of the target namespace based on
+// the NetworkPolicies state
+func (r *OpenShiftBuildReconciler) ReconcileNetworkPolicy(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	logger := log.FromContext(ctx).WithValues("name", owner.Name)
+
+	if networkpolicy.IsEnabled(owner) {
+		if err := r.NetworkPolicy.CreateOrUpdate(ctx, owner); err != nil {
+			return err
+		}
+		logger.Info("NetworkPolicies", "result", "applied")
+		return nil
+	}
+	if err := r.NetworkPolicy.Delete(ctx, owner); err != nil {
+		return err
+	}
+	logger.Info("NetworkPolicies", "result", "deleted")
 	return nil
 }
 
 // HandleDeletion deletes objects created by the controller
 func (r *OpenShiftBuildReconciler) HandleDeletion(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
 	logger := log.FromContext(ctx).WithValues("name", owner.Name)
+	if err := r.BuildStrategy.Delete(ctx, owner); err != nil {
+		logger.Error(err, "Failed to delete build strategies")
+		return err
+	}
+	if err := r.RegistryConfig.Delete(ctx, owner); err != nil {
+		logger.Error(err, "Failed to delete registry configuration")
+		return err
+	}
+	if err := r.UserNamespace.Delete(ctx); err != nil {
+		logger.Error(err, "Failed to delete user namespace SecurityContextConstraints")
+		return err
+	}
 	if err := r.Shipwright.Delete(ctx, owner); err != nil && !apierrors.IsNotFound(err) {
 		logger.Error(err, "Failed to delete Shipwright Build")
 		return err
 	}
-	if err := r.SharedResource.Reconcile(owner); err != nil {
+	if err := r.SharedResource.Reconcile(ctx, owner); err != nil {
 		logger.Error(err, "Failed to delete SharedResource")
 		return err
 	}
+	if err := r.NetworkPolicy.Delete(ctx, owner); err != nil {
+		logger.Error(err, "Failed to delete NetworkPolicies")
+		return err
+	}
 	if controllerutil.ContainsFinalizer(owner, common.OpenShiftBuildFinalizerName) {
 		if ok := controllerutil.RemoveFinalizer(owner, common.OpenShiftBuildFinalizerName); ok {
 			return r.Client.Update(ctx, owner)
@@ -226,12 +603,59 @@ func (r *OpenShiftBuildReconciler) HandleDeletion(ctx context.Context, owner *op
 	return nil
 }
 
+// ReconcileTargetNamespace creates the namespace of the components if it does not exist. The
+// namespace is not deleted with the OpenShiftBuild, as it may hold objects created by users. The
+// namespaces of the operator and of the components are labeled so that the webhooks skip them.
+func (r *OpenShiftBuildReconciler) ReconcileTargetNamespace(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	logger := log.FromContext(ctx).WithValues("name", owner.Name)
+
+	for _, name := range []string{common.CurrentNamespaceName, common.TargetNamespace(owner)} {
+		if name == "" {
+			continue
+		}
+		namespace := &corev1.Namespace{}
+		namespace.SetName(name)
+		err := r.Client.Get(ctx, client.ObjectKeyFromObject(namespace), namespace)
+		switch {
+		case apierrors.IsNotFound(err):
+			namespace.SetLabels(map[string]string{common.OperatorNamespaceLabel: "true"})
+			if err := r.Client.Create(ctx, namespace); err != nil && !apierrors.IsAlreadyExists(err) {
+				return err
+			}
+			logger.Info("Target namespace", "namespace", namespace.Name, "result", "created")
+		case err != nil:
+			return err
+		case namespace.Labels[common.OperatorNamespaceLabel] != "true":
+			patch := client.MergeFrom(namespace.DeepCopy())
+			metav1.SetMetaDataLabel(&namespace.ObjectMeta, common.OperatorNamespaceLabel, "true")
+			if err := r.Client.Patch(ctx, namespace, patch); err != nil {
+				return err
+			}
+			logger.Info("Target namespace", "namespace", namespace.Name, "result", "labeled")
+		}
+	}
+	return nil
+}
+
 // ReconcileShipwrightBuild creates or deletes ShipwrightBuild object
 func (r *OpenShiftBuildReconciler) ReconcileShipwrightBuild(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
 	logger := log.FromContext(ctx).WithValues("name", owner.Name)
 
 	switch owner.Spec.Shipwright.Build.State {
 	case openshiftv1alpha1.Enabled:
+		conflicts, err := r.Shipwright.Conflicts(ctx, owner)
+		if err != nil {
+			return err
+		}
+		if !conflicts.IsEmpty() && owner.Spec.Shipwright.Build.ExistingInstallPolicy == openshiftv1alpha1.Adopt {
+			logger.Info("Adopting existing Shipwright Build installation")
+			if conflicts, err = r.Shipwright.Adopt(ctx, owner, conflicts); err != nil {
+				return err
+			}
+		}
+		if !conflicts.IsEmpty() {
+			return shipwrightbuild.NewConflictError(conflicts)
+		}
 		result, err := r.Shipwright.CreateOrUpdate(ctx, owner)
 		if err != nil {
 			return err
@@ -257,11 +681,87 @@ func (r *OpenShiftBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	// bootstrap Shipwright Build strategies
+	if err := r.setupBuildStrategy(mgr); err != nil {
+		return err
+	}
+
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&openshiftv1alpha1.OpenShiftBuild{}).
-		Owns(&shipwrightv1alpha1.ShipwrightBuild{}).
+		Owns(&networkingv1.NetworkPolicy{})
+
+	// re-render the registry configuration when the cluster image configuration changes
+	enqueueOpenShiftBuild := handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, object client.Object) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: common.OpenShiftBuildResourceName}}}
+		},
+	)
+	// foreign ShipwrightBuilds are watched to detect conflicting installations
+	builder = builder.Watches(&shipwrightv1alpha1.ShipwrightBuild{}, enqueueOpenShiftBuild)
+
+	// re-apply the strategy bundles when their ConfigMaps change. Only the metadata of ConfigMaps
+	// is cached, the bundles are read from the API server.
+	builder = builder.WatchesMetadata(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, object client.Object) []reconcile.Request {
+			owner := &openshiftv1alpha1.OpenShiftBuild{}
+			if err := r.Client.Get(ctx, client.ObjectKey{Name: common.OpenShiftBuildResourceName}, owner); err != nil {
+				return nil
+			}
+			if object.GetNamespace() != common.TargetNamespace(owner) ||
+				!slices.Contains(strategy.BundleNames(owner), object.GetName()) {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(owner)}}
+		},
+	))
+
+	// replicate the strategies, and bind the user namespace SecurityContextConstraints, when
+	// namespaces start or stop matching, and correct namespaced copies modified by users
+	enqueueForNamespacedStrategies := handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, object client.Object) []reconcile.Request {
+			owner := &openshiftv1alpha1.OpenShiftBuild{}
+			if err := r.Client.Get(ctx, client.ObjectKey{Name: common.OpenShiftBuildResourceName}, owner); err != nil {
+				return nil
+			}
+			if strategy.NamespacedStrategiesSelector(owner) == nil && userns.NamespaceSelector(owner) == nil {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(owner)}}
+		},
+	)
+	builder = builder.Watches(&corev1.Namespace{}, enqueueForNamespacedStrategies)
+	namespacedStrategy := &unstructured.Unstructured{}
+	namespacedStrategy.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "shipwright.io",
+		Version: "v1alpha1",
+		Kind:    "BuildStrategy",
+	})
+	if isServed(mgr, namespacedStrategy) {
+		builder = builder.Watches(namespacedStrategy, enqueueForNamespacedStrategies,
+			ctrlbuilder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+				_, ok := object.GetLabels()[common.ReplicatedFromLabel]
+				return ok
+			})))
+	}
+
+	for _, object := range []client.Object{
+		&configv1.Image{},
+		&configv1.ImageDigestMirrorSet{},
+		&configv1.ImageTagMirrorSet{},
+	} {
+		if isServed(mgr, object) {
+			builder = builder.Watches(object, enqueueOpenShiftBuild)
+		}
+	}
+
+	return builder.
 		WithEventFilter(predicate.Funcs{
 			UpdateFunc: func(e event.UpdateEvent) bool {
+				// ConfigMaps and Namespaces have no generation
+				switch e.ObjectNew.(type) {
+				case *metav1.PartialObjectMetadata, *corev1.Namespace:
+					return e.ObjectOld.GetResourceVersion() != e.ObjectNew.GetResourceVersion()
+				}
 				return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
 			},
 			DeleteFunc: func(e event.DeleteEvent) bool {
@@ -270,3 +770,13 @@ func (r *OpenShiftBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		}).
 		Complete(r)
 }
+
+// isServed returns true if the API of the object is served by the cluster
+func isServed(mgr ctrl.Manager, object client.Object) bool {
+	gvk, err := apiutil.GVKForObject(object, mgr.GetScheme())
+	if err != nil {
+		return false
+	}
+	_, err = mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	return err == nil
+}
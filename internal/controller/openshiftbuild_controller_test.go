@@ -20,8 +20,11 @@ import (
 	"context"
 
 	shipwrightbuild "github.com/redhat-openshift-builds/operator/internal/shipwright/build"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/registry"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/userns"
 
 	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/networkpolicy"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -42,16 +45,17 @@ var _ = Describe("OpenShiftBuild Controller", Label("controller", "openshiftbuil
 	var (
 		reconciler *OpenShiftBuildReconciler
 		ctx        context.Context
-		namespace  string
 	)
 
 	BeforeEach(func() {
-		namespace = common.OpenShiftBuildNamespaceName
 		reconciler = &OpenShiftBuildReconciler{
-			APIReader:  k8sClient,
-			Client:     k8sClient,
-			Scheme:     k8sClient.Scheme(),
-			Shipwright: shipwrightbuild.New(k8sClient, namespace),
+			APIReader:      k8sClient,
+			Client:         k8sClient,
+			Scheme:         k8sClient.Scheme(),
+			Shipwright:     shipwrightbuild.New(k8sClient),
+			RegistryConfig: registry.New(k8sClient),
+			UserNamespace:  userns.New(k8sClient),
+			NetworkPolicy:  networkpolicy.New(k8sClient),
 		}
 		ctx = context.Background()
 	})
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/redhat-openshift-builds/operator/internal/sharedresource"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// shareAPIPollInterval is the interval at which the operator checks whether the share APIs are
+// served, when the Shared Resource CSI Driver is not installed yet
+const shareAPIPollInterval = 30 * time.Second
+
+// ShareGrantReconciler reconciles the Roles and RoleBindings granting the use of the SharedSecrets
+// or SharedConfigMaps annotated with the namespaces allowed to use them
+type ShareGrantReconciler struct {
+	Client client.Client
+	Grant  *sharedresource.Grant
+	// Kind is the kind of the shares, either SharedSecret or SharedConfigMap
+	Kind schema.GroupVersionKind
+}
+
+// Reconcile creates or updates the grants of the share in the namespaces it is granted to, and
+// deletes the others. Grants are garbage collected with the share.
+func (r *ShareGrantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("kind", r.Kind.Kind, "name", req.Name)
+
+	share := &unstructured.Unstructured{}
+	share.SetGroupVersionKind(r.Kind)
+	if err := r.Client.Get(ctx, req.NamespacedName, share); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !share.GetDeletionTimestamp().IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	namespaces, err := r.Grant.CreateOrUpdate(ctx, share)
+	if err != nil {
+		logger.Error(err, "Failed to reconcile share grants")
+		return ctrl.Result{}, err
+	}
+	if sharedresource.IsGranted(share) {
+		logger.Info("Granted share", "namespaces", namespaces)
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager. The share APIs are installed with the
+// Shared Resource CSI Driver, so the controller is only started once they are served.
+func (r *ShareGrantReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.isServed(mgr) {
+		return r.setup(mgr)
+	}
+	return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		err := wait.PollUntilContextCancel(ctx, shareAPIPollInterval, false, func(context.Context) (bool, error) {
+			return r.isServed(mgr), nil
+		})
+		if err != nil {
+			// the manager is stopping
+			return nil
+		}
+		return r.setup(mgr)
+	}))
+}
+
+// isServed returns true if the share API is served by the cluster
+func (r *ShareGrantReconciler) isServed(mgr ctrl.Manager) bool {
+	_, err := mgr.GetRESTMapper().RESTMapping(r.Kind.GroupKind(), r.Kind.Version)
+	return err == nil
+}
+
+// setup registers the controller
+func (r *ShareGrantReconciler) setup(mgr ctrl.Manager) error {
+	// a share is reconciled when a namespace it grants is created, relabelled, or deleted. Updates
+	// are mapped from both the old and the new namespace, so that the grants of the namespaces no
+	// longer granted are deleted.
+	enqueueShares := handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, object client.Object) []reconcile.Request {
+			namespace, ok := object.(*corev1.Namespace)
+			if !ok {
+				return nil
+			}
+			shares := &unstructured.UnstructuredList{}
+			shares.SetGroupVersionKind(r.Kind.GroupVersion().WithKind(r.Kind.Kind + "List"))
+			if err := r.Client.List(ctx, shares); err != nil {
+				return nil
+			}
+			requests := []reconcile.Request{}
+			for i := range shares.Items {
+				granted, err := sharedresource.IsGrantedTo(&shares.Items[i], namespace)
+				if err != nil || granted {
+					requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&shares.Items[i])})
+				}
+			}
+			return requests
+		},
+	)
+
+	share := &unstructured.Unstructured{}
+	share.SetGroupVersionKind(r.Kind)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(strings.ToLower(r.Kind.Kind)+"-grant").
+		For(share).
+		Owns(&rbacv1.Role{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Watches(&corev1.Namespace{}, enqueueShares,
+			builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Complete(r)
+}
@@ -60,10 +60,22 @@ func (r *ShipwrightBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
-	// Remove runAsUser and runAsGroup from a Deployment container's security context
+	// Operand configuration manifests, such as the trusted CA bundle
+	manifestPath = common.ShipwrightBuildConfigManifestPath
+	if path, ok := os.LookupEnv(common.ShipwrightBuildConfigManifestPathEnv); ok {
+		manifestPath = path
+	}
+	configManifest, err := manifestival.NewManifest(manifestPath, manifestivalOptions...)
+	if err != nil {
+		return err
+	}
+	r.Manifest = r.Manifest.Append(configManifest)
+
+	// Restrict the security context of the Deployments to the restricted Pod Security profile
 	// Insert Openshift Service CA annotations in service and CRD
+	// Mount the trusted CA bundle in the build controller
 	if r.Manifest, err = r.Manifest.Transform(
-		common.RemoveRunAsUserRunAsGroup,
+		common.InjectRestrictedSecurityContext,
 		common.InjectAnnotations(
 			[]string{"Service"},
 			[]string{common.ShipwrightWebhookServiceName},
@@ -78,16 +90,14 @@ func (r *ShipwrightBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				openshiftserviceca.InjectCABundleAnnotationName: "true",
 			},
 		),
+		common.InjectTrustedCABundle([]string{common.ShipwrightBuildControllerName}),
 	); err != nil {
 		return err
 	}
 
-	// Shipwright Build strategies manifests
-	manifestPath = common.ShipwrightBuildStrategyManifestPath
-	if path, ok := os.LookupEnv(common.ShipwrightBuildStrategyManifestPathEnv); ok {
-		manifestPath = path
-	}
-	if r.BuildStrategyManifest, err = manifestival.NewManifest(manifestPath, manifestivalOptions...); err != nil {
+	// ClusterBuildStrategies are reconciled by the OpenShiftBuild controller, as they depend on
+	// the OpenShiftBuild spec.
+	if r.BuildStrategyManifest, err = manifestival.ManifestFrom(manifestival.Slice{}, manifestivalOptions...); err != nil {
 		return err
 	}
 
@@ -0,0 +1,265 @@
+package networkpolicy
+
+import (
+	"context"
+	"slices"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultDenyName is the name of the NetworkPolicy denying the traffic not allowed by a flow
+const DefaultDenyName = "openshift-builds-default-deny"
+
+// apiServerName is the name of the NetworkPolicy allowing the traffic to the API server and DNS
+const apiServerName = "openshift-builds-allow-api-server"
+
+// hostNetworkLabel selects the namespaces of the pods running in the host network on OpenShift,
+// such as the API server and the kubelet
+const hostNetworkLabel = "policy-group.network.openshift.io/host-network"
+
+// monitoringNamespaceName is the namespace of the platform Prometheus
+const monitoringNamespaceName = "openshift-monitoring"
+
+// flow identifies a flow of the OpenShiftBuild NetworkPolicies spec
+type flow string
+
+const (
+	webhooksFlow flow = "webhooks"
+	metricsFlow  flow = "metrics"
+	kubeletFlow  flow = "kubelet"
+)
+
+// ingress defines the ports of a component receiving a flow
+type ingress struct {
+	flow  flow
+	ports []int32
+}
+
+// component defines the pods of an operand and the flows they receive
+type component struct {
+	name     string
+	selector map[string]string
+	ingress  []ingress
+}
+
+// components lists the pods of the target namespace and the ingress flows they require
+var components = []component{
+	{
+		name:     "openshift-builds-operator",
+		selector: map[string]string{"control-plane": "controller-manager"},
+		ingress: []ingress{
+			{flow: webhooksFlow, ports: []int32{9443}},
+			{flow: metricsFlow, ports: []int32{8443}},
+		},
+	},
+	{
+		name:     "openshift-builds-shipwright-build-controller",
+		selector: map[string]string{"name": "shipwright-build"},
+		ingress: []ingress{
+			{flow: metricsFlow, ports: []int32{8383}},
+		},
+	},
+	{
+		name:     "openshift-builds-shipwright-build-webhook",
+		selector: map[string]string{"name": "shp-build-webhook"},
+		ingress: []ingress{
+			{flow: webhooksFlow, ports: []int32{8443}},
+		},
+	},
+	{
+		name:     "openshift-builds-shared-resource-webhook",
+		selector: map[string]string{"name": "shared-resource-csi-driver-webhook"},
+		ingress: []ingress{
+			{flow: webhooksFlow, ports: []int32{8443}},
+		},
+	},
+	{
+		name:     "openshift-builds-shared-resource-node",
+		selector: map[string]string{"app": common.SharedResourceNodeDaemonSetName},
+		ingress: []ingress{
+			{flow: metricsFlow, ports: []int32{6000}},
+			{flow: kubeletFlow, ports: []int32{9898}},
+		},
+	},
+}
+
+// NetworkPolicy type defines methods to apply and delete the NetworkPolicies of the target
+// namespace
+type NetworkPolicy struct {
+	Client client.Client
+}
+
+// New creates new instance of NetworkPolicy type
+func New(client client.Client) *NetworkPolicy {
+	return &NetworkPolicy{
+		Client: client,
+	}
+}
+
+// IsEnabled returns true if the OpenShiftBuild enables the NetworkPolicies
+func IsEnabled(owner *openshiftv1alpha1.OpenShiftBuild) bool {
+	return owner.Spec.NetworkPolicies != nil && owner.Spec.NetworkPolicies.State == openshiftv1alpha1.Enabled
+}
+
+// CreateOrUpdate applies the NetworkPolicy denying all traffic in the target namespace and the
+// NetworkPolicies allowing the enabled flows. NetworkPolicies of disabled flows are deleted.
+func (np *NetworkPolicy) CreateOrUpdate(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	desired := Policies(owner)
+	for i := range desired {
+		policy := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{
+			Name:      desired[i].Name,
+			Namespace: desired[i].Namespace,
+		}}
+		if _, err := ctrl.CreateOrUpdate(ctx, np.Client, policy, func() error {
+			policy.Labels = desired[i].Labels
+			policy.Spec = desired[i].Spec
+			return ctrl.SetControllerReference(owner, policy, np.Client.Scheme())
+		}); err != nil {
+			return err
+		}
+	}
+
+	names := []string{}
+	for _, policy := range desired {
+		names = append(names, policy.Name)
+	}
+	return np.deleteExcept(ctx, owner, names)
+}
+
+// Delete deletes the NetworkPolicies of the target namespace
+func (np *NetworkPolicy) Delete(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	return np.deleteExcept(ctx, owner, nil)
+}
+
+// deleteExcept deletes the NetworkPolicies managed by the operator whose name is not listed
+func (np *NetworkPolicy) deleteExcept(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, names []string) error {
+	list := &networkingv1.NetworkPolicyList{}
+	if err := np.Client.List(ctx, list, client.InNamespace(common.TargetNamespace(owner)),
+		client.MatchingLabels{common.ManagedByLabel: common.ManagedByLabelValue}); err != nil {
+		return err
+	}
+	for i := range list.Items {
+		if slices.Contains(names, list.Items[i].Name) {
+			continue
+		}
+		if err := np.Client.Delete(ctx, &list.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Policies returns the NetworkPolicies of the target namespace: one denying all ingress and egress
+// traffic, one allowing the traffic to the API server and DNS, and one per component allowing the
+// enabled flows it receives
+func Policies(owner *openshiftv1alpha1.OpenShiftBuild) []networkingv1.NetworkPolicy {
+	namespace := common.TargetNamespace(owner)
+	policies := []networkingv1.NetworkPolicy{
+		newPolicy(DefaultDenyName, namespace, networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		}),
+	}
+
+	spec := owner.Spec.NetworkPolicies
+	if apiServer := spec.APIServer; apiServer == nil || apiServer.State != openshiftv1alpha1.Disabled {
+		rule := networkingv1.NetworkPolicyEgressRule{
+			Ports: append(ports(corev1.ProtocolTCP, 443, 6443, 53, 5353), ports(corev1.ProtocolUDP, 53, 5353)...),
+		}
+		if apiServer != nil {
+			rule.To = apiServer.Peers
+		}
+		policies = append(policies, newPolicy(apiServerName, namespace, networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress:      []networkingv1.NetworkPolicyEgressRule{rule},
+		}))
+	}
+
+	for _, component := range components {
+		rules := []networkingv1.NetworkPolicyIngressRule{}
+		for _, ingress := range component.ingress {
+			peers, enabled := flowPeers(spec, ingress.flow)
+			if !enabled {
+				continue
+			}
+			rules = append(rules, networkingv1.NetworkPolicyIngressRule{
+				From:  peers,
+				Ports: ports(corev1.ProtocolTCP, ingress.ports...),
+			})
+		}
+		if len(rules) == 0 {
+			continue
+		}
+		policies = append(policies, newPolicy(component.name, namespace, networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: component.selector},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     rules,
+		}))
+	}
+	return policies
+}
+
+// flowPeers returns the sources of an ingress flow, and false if the flow is disabled
+func flowPeers(spec *openshiftv1alpha1.NetworkPolicies, name flow) ([]networkingv1.NetworkPolicyPeer, bool) {
+	var custom *openshiftv1alpha1.NetworkFlow
+	defaults := []networkingv1.NetworkPolicyPeer{{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{hostNetworkLabel: ""}},
+	}}
+	switch name {
+	case webhooksFlow:
+		custom = spec.Webhooks
+	case metricsFlow:
+		custom = spec.Metrics
+		defaults = []networkingv1.NetworkPolicyPeer{{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{
+				corev1.LabelMetadataName: monitoringNamespaceName,
+			}},
+		}}
+	case kubeletFlow:
+		custom = spec.Kubelet
+	}
+
+	if custom == nil {
+		return defaults, true
+	}
+	if custom.State == openshiftv1alpha1.Disabled {
+		return nil, false
+	}
+	if len(custom.Peers) > 0 {
+		return custom.Peers, true
+	}
+	return defaults, true
+}
+
+// newPolicy returns a NetworkPolicy labelled as managed by the operator
+func newPolicy(name, namespace string, spec networkingv1.NetworkPolicySpec) networkingv1.NetworkPolicy {
+	return networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{common.ManagedByLabel: common.ManagedByLabelValue},
+		},
+		Spec: spec,
+	}
+}
+
+// ports returns the NetworkPolicy ports of the protocol
+func ports(protocol corev1.Protocol, numbers ...int32) []networkingv1.NetworkPolicyPort {
+	result := []networkingv1.NetworkPolicyPort{}
+	for _, number := range numbers {
+		result = append(result, networkingv1.NetworkPolicyPort{
+			Protocol: ptr.To(protocol),
+			Port:     ptr.To(intstr.FromInt32(number)),
+		})
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+package networkpolicy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/redhat-openshift-builds/operator/internal/networkpolicy"
+)
+
+var _ = Describe("NetworkPolicy", Label("networkpolicy"), func() {
+	var (
+		ctx           context.Context
+		fakeClient    client.Client
+		networkPolicy *networkpolicy.NetworkPolicy
+		owner         *openshiftv1alpha1.OpenShiftBuild
+	)
+
+	list := func() map[string]networkingv1.NetworkPolicy {
+		policies := &networkingv1.NetworkPolicyList{}
+		Expect(fakeClient.List(ctx, policies, client.InNamespace(common.TargetNamespace(owner)))).To(Succeed())
+		byName := map[string]networkingv1.NetworkPolicy{}
+		for _, policy := range policies.Items {
+			byName[policy.Name] = policy
+		}
+		return byName
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		owner = &openshiftv1alpha1.OpenShiftBuild{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: common.OpenShiftBuildResourceName,
+				UID:  uuid.NewUUID(),
+			},
+			Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+				TargetNamespace: "openshift-builds",
+				NetworkPolicies: &openshiftv1alpha1.NetworkPolicies{State: openshiftv1alpha1.Enabled},
+			},
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+		networkPolicy = networkpolicy.New(fakeClient)
+	})
+
+	It("should deny all traffic not allowed by a flow", func() {
+		Expect(networkPolicy.CreateOrUpdate(ctx, owner)).To(Succeed())
+
+		policies := list()
+		deny, ok := policies[networkpolicy.DefaultDenyName]
+		Expect(ok).To(BeTrue())
+		Expect(metav1.IsControlledBy(&deny, owner)).To(BeTrue())
+		Expect(deny.Spec.PodSelector).To(Equal(metav1.LabelSelector{}))
+		Expect(deny.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress))
+		Expect(deny.Spec.Ingress).To(BeEmpty())
+		Expect(deny.Spec.Egress).To(BeEmpty())
+		Expect(policies).To(HaveKey("openshift-builds-allow-api-server"))
+	})
+
+	It("should allow the API server to reach the webhooks", func() {
+		Expect(networkPolicy.CreateOrUpdate(ctx, owner)).To(Succeed())
+
+		webhook := list()["openshift-builds-shipwright-build-webhook"]
+		Expect(webhook.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{"name": "shp-build-webhook"}))
+		Expect(webhook.Spec.Ingress).To(HaveLen(1))
+		Expect(*webhook.Spec.Ingress[0].Ports[0].Port).To(Equal(intstr.FromInt32(8443)))
+		Expect(webhook.Spec.Ingress[0].From[0].NamespaceSelector.MatchLabels).
+			To(HaveKey("policy-group.network.openshift.io/host-network"))
+	})
+
+	It("should replace the default peers of a customised flow", func() {
+		peers := []networkingv1.NetworkPolicyPeer{{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"monitoring": "custom"}},
+		}}
+		owner.Spec.NetworkPolicies.Metrics = &openshiftv1alpha1.NetworkFlow{
+			State: openshiftv1alpha1.Enabled,
+			Peers: peers,
+		}
+		Expect(networkPolicy.CreateOrUpdate(ctx, owner)).To(Succeed())
+
+		controller := list()["openshift-builds-shipwright-build-controller"]
+		Expect(controller.Spec.Ingress).To(HaveLen(1))
+		Expect(controller.Spec.Ingress[0].From).To(Equal(peers))
+	})
+
+	It("should delete the NetworkPolicies of disabled flows", func() {
+		Expect(networkPolicy.CreateOrUpdate(ctx, owner)).To(Succeed())
+		Expect(list()).To(HaveKey("openshift-builds-shipwright-build-controller"))
+
+		owner.Spec.NetworkPolicies.Metrics = &openshiftv1alpha1.NetworkFlow{State: openshiftv1alpha1.Disabled}
+		Expect(networkPolicy.CreateOrUpdate(ctx, owner)).To(Succeed())
+
+		policies := list()
+		Expect(policies).NotTo(HaveKey("openshift-builds-shipwright-build-controller"))
+		node := policies["openshift-builds-shared-resource-node"]
+		Expect(node.Spec.Ingress).To(HaveLen(1))
+		Expect(*node.Spec.Ingress[0].Ports[0].Port).To(Equal(intstr.FromInt32(9898)))
+	})
+
+	It("should delete every NetworkPolicy", func() {
+		Expect(networkPolicy.CreateOrUpdate(ctx, owner)).To(Succeed())
+		Expect(networkPolicy.Delete(ctx, owner)).To(Succeed())
+		Expect(list()).To(BeEmpty())
+	})
+})
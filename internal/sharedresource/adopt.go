@@ -0,0 +1,103 @@
+package sharedresource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/manifestival/manifestival"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// shareKinds are the kinds of the user shares preserved on adoption
+var shareKinds = []schema.GroupVersionKind{
+	{Group: "sharedresource.openshift.io", Version: "v1alpha1", Kind: "SharedConfigMapList"},
+	{Group: "sharedresource.openshift.io", Version: "v1alpha1", Kind: "SharedSecretList"},
+}
+
+// ConflictError reports the objects of a Shared Resource CSI Driver installed by another party
+type ConflictError struct {
+	Objects []string
+}
+
+// Error implements the error interface
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("Shared Resource CSI Driver objects are managed by another installation: %s",
+		strings.Join(e.Objects, ", "))
+}
+
+// conflicts returns the CSIDriver and CRDs of the manifest which already exist on the cluster
+// and are not managed by the owner
+func (sr *SharedResource) conflicts(manifest *manifestival.Manifest, owner *openshiftv1alpha1.OpenShiftBuild) ([]*unstructured.Unstructured, error) {
+	result := []*unstructured.Unstructured{}
+	for _, res := range manifest.Filter(manifestival.Any(manifestival.ByKind("CSIDriver"), manifestival.CRDs)).Resources() {
+		obj, err := sr.Manifest.Client.Get(&res)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !metav1.IsControlledBy(obj, owner) && !isManagedCRD(obj, owner) {
+			result = append(result, obj)
+		}
+	}
+	return result, nil
+}
+
+// adopt takes over the conflicting objects. The CSIDriver is recreated as its spec is immutable,
+// and CRDs are updated in place so that existing shares are preserved. CRDs and user shares are
+// annotated, not owned, so that they are never garbage collected with the OpenShiftBuild.
+func (sr *SharedResource) adopt(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, conflicts []*unstructured.Unstructured) error {
+	mfc := sr.Manifest.Client
+	for _, obj := range conflicts {
+		sr.Logger.Info("Adopting Shared Resource CSI Driver object", "kind", obj.GetKind(), "name", obj.GetName())
+		if obj.GetKind() == "CSIDriver" {
+			if err := mfc.Delete(obj); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			continue
+		}
+		setAdoptedAnnotation(obj, owner)
+		obj.SetOwnerReferences(nil)
+		if err := mfc.Update(obj); err != nil {
+			return err
+		}
+	}
+
+	for _, gvk := range shareKinds {
+		shares := &unstructured.UnstructuredList{}
+		shares.SetGroupVersionKind(gvk)
+		if err := sr.Client.List(ctx, shares); err != nil {
+			return err
+		}
+		for i := range shares.Items {
+			share := &shares.Items[i]
+			if _, ok := share.GetAnnotations()[common.SharedResourceAdoptedAnnotation]; ok {
+				continue
+			}
+			patch := client.MergeFrom(share.DeepCopy())
+			setAdoptedAnnotation(share, owner)
+			if err := sr.Client.Patch(ctx, share, patch); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setAdoptedAnnotation records that the object was adopted by the owner
+func setAdoptedAnnotation(obj *unstructured.Unstructured, owner *openshiftv1alpha1.OpenShiftBuild) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[common.SharedResourceAdoptedAnnotation] = owner.Name
+	obj.SetAnnotations(annotations)
+}
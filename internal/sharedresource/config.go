@@ -0,0 +1,111 @@
+package sharedresource
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/manifestival/manifestival"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// minimumInterval is the shortest refresh period and share relist interval accepted
+const minimumInterval = time.Minute
+
+// driverConfig is the configuration file of the Shared Resource CSI Driver
+type driverConfig struct {
+	IgnoredNamespaces   []string `json:"ignoredNamespaces,omitempty"`
+	RefreshPeriod       string   `json:"refreshPeriod,omitempty"`
+	RefreshResources    bool     `json:"refreshResources"`
+	ShareRelistInterval string   `json:"shareRelistInterval,omitempty"`
+}
+
+// ValidateConfig returns an error describing every invalid field of the driver configuration
+func ValidateConfig(config *openshiftv1alpha1.SharedResourceConfig) error {
+	if config == nil {
+		return nil
+	}
+
+	errs := []error{}
+	for _, namespace := range config.IgnoredNamespaces {
+		for _, message := range validation.IsDNS1123Label(namespace) {
+			errs = append(errs, fmt.Errorf("invalid ignored namespace %q: %s", namespace, message))
+		}
+	}
+	if err := validateInterval("refreshPeriod", config.RefreshPeriod); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateInterval("shareRelistInterval", config.ShareRelistInterval); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// validateInterval returns an error if the interval is set and shorter than minimumInterval
+func validateInterval(field string, interval *metav1.Duration) error {
+	if interval == nil || interval.Duration >= minimumInterval {
+		return nil
+	}
+	return fmt.Errorf("invalid %s %q: must be at least %s", field, interval.Duration, minimumInterval)
+}
+
+// renderConfig merges the configuration into the default driver configuration shipped in the
+// manifest, and returns the content of the configuration file
+func renderConfig(manifest manifestival.Manifest, config *openshiftv1alpha1.SharedResourceConfig) (string, error) {
+	result := driverConfig{RefreshResources: true}
+	for _, resource := range manifest.Filter(manifestival.ByKind("ConfigMap"), manifestival.ByName(common.SharedResourceConfigMapName)).Resources() {
+		data, _, err := unstructured.NestedString(resource.Object, "data", common.SharedResourceConfigKey)
+		if err != nil {
+			return "", err
+		}
+		if err := yaml.Unmarshal([]byte(data), &result); err != nil {
+			return "", err
+		}
+	}
+
+	if config != nil {
+		for _, namespace := range config.IgnoredNamespaces {
+			if !slices.Contains(result.IgnoredNamespaces, namespace) {
+				result.IgnoredNamespaces = append(result.IgnoredNamespaces, namespace)
+			}
+		}
+		if config.RefreshPeriod != nil {
+			result.RefreshPeriod = config.RefreshPeriod.Duration.String()
+		}
+		if config.RefreshResources != nil {
+			result.RefreshResources = *config.RefreshResources
+		}
+		if config.ShareRelistInterval != nil {
+			result.ShareRelistInterval = config.ShareRelistInterval.Duration.String()
+		}
+	}
+
+	data, err := yaml.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// injectConfig is a Manifestival transformer that sets the driver configuration file and
+// annotates the node plugin pods with its hash, so that they are restarted when it changes
+func injectConfig(data string) manifestival.Transformer {
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(data)))
+	return func(object *unstructured.Unstructured) error {
+		switch {
+		case object.GetKind() == "ConfigMap" && object.GetName() == common.SharedResourceConfigMapName:
+			return unstructured.SetNestedField(object.Object, data, "data", common.SharedResourceConfigKey)
+		case object.GetKind() == "DaemonSet" && object.GetName() == common.SharedResourceNodeDaemonSetName:
+			return unstructured.SetNestedField(object.Object, hash,
+				"spec", "template", "metadata", "annotations", common.SharedResourceConfigHashAnnotation)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,243 @@
+package sharedresource
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/buildnamespace"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GrantKinds are the kinds of the shares whose use can be granted to build namespaces
+var GrantKinds = []schema.GroupVersionKind{
+	{Group: "sharedresource.openshift.io", Version: "v1alpha1", Kind: "SharedConfigMap"},
+	{Group: "sharedresource.openshift.io", Version: "v1alpha1", Kind: "SharedSecret"},
+}
+
+// Grant type defines methods to create and delete the Roles and RoleBindings allowing the build
+// ServiceAccounts of namespaces to use a share
+type Grant struct {
+	Client client.Client
+}
+
+// NewGrant creates new instance of Grant type
+func NewGrant(client client.Client) *Grant {
+	return &Grant{
+		Client: client,
+	}
+}
+
+// IsGranted returns true if the share is annotated with namespaces allowed to use it
+func IsGranted(share client.Object) bool {
+	annotations := share.GetAnnotations()
+	return annotations[common.ShareGrantNamespacesAnnotation] != "" ||
+		annotations[common.ShareGrantNamespaceSelectorAnnotation] != ""
+}
+
+// GrantName returns the name of the Role and RoleBinding allowing the use of the share
+func GrantName(share client.Object) string {
+	return strings.ToLower(share.GetObjectKind().GroupVersionKind().Kind) + "-" + share.GetName()
+}
+
+// CreateOrUpdate creates or updates the Role and RoleBinding of the share in every namespace granted
+// by its annotations, and deletes them from the namespaces no longer granted. Returns the names of
+// the granted namespaces.
+func (g *Grant) CreateOrUpdate(ctx context.Context, share *unstructured.Unstructured) ([]string, error) {
+	namespaces, err := g.grantedNamespaces(ctx, share)
+	if err != nil {
+		return nil, err
+	}
+
+	granted := []string{}
+	for i := range namespaces {
+		namespace := &namespaces[i]
+		serviceAccounts, err := g.serviceAccounts(ctx, share, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		role := &rbacv1.Role{}
+		role.SetName(GrantName(share))
+		role.SetNamespace(namespace.Name)
+		if err := g.apply(ctx, share, role, func() error {
+			role.Rules = []rbacv1.PolicyRule{{
+				APIGroups:     []string{share.GroupVersionKind().Group},
+				Resources:     []string{strings.ToLower(share.GetKind()) + "s"},
+				ResourceNames: []string{share.GetName()},
+				Verbs:         []string{"use"},
+			}}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+
+		binding := &rbacv1.RoleBinding{}
+		binding.SetName(GrantName(share))
+		binding.SetNamespace(namespace.Name)
+		if err := g.apply(ctx, share, binding, func() error {
+			binding.RoleRef = rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "Role",
+				Name:     role.Name,
+			}
+			binding.Subjects = []rbacv1.Subject{}
+			for _, serviceAccount := range serviceAccounts {
+				binding.Subjects = append(binding.Subjects, rbacv1.Subject{
+					Kind:      rbacv1.ServiceAccountKind,
+					Name:      serviceAccount,
+					Namespace: namespace.Name,
+				})
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		granted = append(granted, namespace.Name)
+	}
+
+	return granted, g.deleteGrants(ctx, share, granted)
+}
+
+// Delete deletes the Roles and RoleBindings of the share from every namespace
+func (g *Grant) Delete(ctx context.Context, share *unstructured.Unstructured) error {
+	return g.deleteGrants(ctx, share, nil)
+}
+
+// IsGrantedTo returns true if the namespace is listed in the namespaces annotation of the share or
+// matches its namespace selector annotation. Terminating namespaces are never granted.
+func IsGrantedTo(share client.Object, namespace *corev1.Namespace) (bool, error) {
+	if !namespace.DeletionTimestamp.IsZero() {
+		return false, nil
+	}
+	annotations := share.GetAnnotations()
+	for _, name := range strings.Split(annotations[common.ShareGrantNamespacesAnnotation], ",") {
+		if strings.TrimSpace(name) == namespace.Name {
+			return true, nil
+		}
+	}
+	value := annotations[common.ShareGrantNamespaceSelectorAnnotation]
+	if value == "" {
+		return false, nil
+	}
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
+
+// grantedNamespaces returns the active namespaces granted by the annotations of the share
+func (g *Grant) grantedNamespaces(ctx context.Context, share *unstructured.Unstructured) ([]corev1.Namespace, error) {
+	list := &corev1.NamespaceList{}
+	if err := g.Client.List(ctx, list); err != nil {
+		return nil, err
+	}
+	namespaces := []corev1.Namespace{}
+	for i := range list.Items {
+		granted, err := IsGrantedTo(share, &list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		if granted {
+			namespaces = append(namespaces, list.Items[i])
+		}
+	}
+	return namespaces, nil
+}
+
+// serviceAccounts returns the build ServiceAccounts of the namespace allowed to use the share: the
+// ones listed in the service accounts annotation of the share, else the ServiceAccount of the
+// BuildNamespaceProfile which onboarded the namespace, else the pipeline ServiceAccount
+func (g *Grant) serviceAccounts(ctx context.Context, share *unstructured.Unstructured, namespace *corev1.Namespace) ([]string, error) {
+	if value := share.GetAnnotations()[common.ShareGrantServiceAccountsAnnotation]; value != "" {
+		names := []string{}
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+		return names, nil
+	}
+
+	if profileName := namespace.Labels[common.BuildNamespaceProfileLabel]; profileName != "" {
+		profile := &openshiftv1alpha1.BuildNamespaceProfile{}
+		err := g.Client.Get(ctx, client.ObjectKey{Name: profileName}, profile)
+		if err == nil {
+			return []string{buildnamespace.ServiceAccountName(profile)}, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return []string{common.DefaultShareServiceAccountName}, nil
+}
+
+// apply creates or updates the object, labelled as a grant and controlled by the share, so that it
+// is garbage collected with the share. Objects of the same name which are not grants are never
+// modified. The manager only caches grants, so they are reported when their creation conflicts.
+func (g *Grant) apply(ctx context.Context, share *unstructured.Unstructured, object client.Object, mutate func() error) error {
+	kind := common.KindOf(g.Client.Scheme(), object)
+	_, err := ctrl.CreateOrUpdate(ctx, g.Client, object, func() error {
+		if object.GetResourceVersion() != "" && object.GetLabels()[common.ShareGrantLabel] == "" {
+			return fmt.Errorf("%s %s/%s exists and is not a share grant", kind, object.GetNamespace(), object.GetName())
+		}
+		objectLabels := object.GetLabels()
+		if objectLabels == nil {
+			objectLabels = map[string]string{}
+		}
+		objectLabels[common.ShareGrantLabel] = strings.ToLower(share.GetKind())
+		objectLabels[common.ManagedByLabel] = common.ManagedByLabelValue
+		object.SetLabels(objectLabels)
+		if err := mutate(); err != nil {
+			return err
+		}
+		return ctrl.SetControllerReference(share, object, g.Client.Scheme())
+	})
+	if apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("%s %s/%s exists and is not a share grant", kind, object.GetNamespace(), object.GetName())
+	}
+	return err
+}
+
+// deleteGrants deletes the Roles and RoleBindings controlled by the share, except the ones of the
+// namespaces to keep
+func (g *Grant) deleteGrants(ctx context.Context, share *unstructured.Unstructured, keep []string) error {
+	selector := client.MatchingLabels{common.ShareGrantLabel: strings.ToLower(share.GetKind())}
+	roles := &rbacv1.RoleList{}
+	if err := g.Client.List(ctx, roles, selector); err != nil {
+		return err
+	}
+	bindings := &rbacv1.RoleBindingList{}
+	if err := g.Client.List(ctx, bindings, selector); err != nil {
+		return err
+	}
+
+	objects := []client.Object{}
+	for i := range roles.Items {
+		objects = append(objects, &roles.Items[i])
+	}
+	for i := range bindings.Items {
+		objects = append(objects, &bindings.Items[i])
+	}
+	for _, object := range objects {
+		if !metav1.IsControlledBy(object, share) || slices.Contains(keep, object.GetNamespace()) {
+			continue
+		}
+		if err := g.Client.Delete(ctx, object); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
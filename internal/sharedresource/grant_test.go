@@ -0,0 +1,145 @@
+package sharedresource_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/redhat-openshift-builds/operator/internal/sharedresource"
+)
+
+var _ = Describe("Grant", Label("sharedresource", "grant"), func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		grant      *sharedresource.Grant
+		share      *unstructured.Unstructured
+	)
+
+	namespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+
+	getBinding := func(namespace string) (*rbacv1.RoleBinding, error) {
+		binding := &rbacv1.RoleBinding{}
+		err := fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: "sharedsecret-maven-settings"}, binding)
+		return binding, err
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		share = &unstructured.Unstructured{}
+		share.SetAPIVersion("sharedresource.openshift.io/v1alpha1")
+		share.SetKind("SharedSecret")
+		share.SetName("maven-settings")
+		share.SetUID(uuid.NewUUID())
+		share.SetAnnotations(map[string]string{
+			common.ShareGrantNamespacesAnnotation:        "team-a",
+			common.ShareGrantNamespaceSelectorAnnotation: "builds=maven",
+		})
+		profile := &openshiftv1alpha1.BuildNamespaceProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "java"},
+			Spec:       openshiftv1alpha1.BuildNamespaceProfileSpec{ServiceAccount: "java-builder"},
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			profile,
+			namespace("team-a", nil),
+			namespace("team-b", map[string]string{"builds": "maven", common.BuildNamespaceProfileLabel: "java"}),
+			namespace("team-c", nil),
+		).Build()
+		grant = sharedresource.NewGrant(fakeClient)
+	})
+
+	It("should allow the build service accounts of the granted namespaces to use the share", func() {
+		namespaces, err := grant.CreateOrUpdate(ctx, share)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(namespaces).To(ConsistOf("team-a", "team-b"))
+
+		role := &rbacv1.Role{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "team-a", Name: "sharedsecret-maven-settings"}, role)).To(Succeed())
+		Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{{
+			APIGroups:     []string{"sharedresource.openshift.io"},
+			Resources:     []string{"sharedsecrets"},
+			ResourceNames: []string{"maven-settings"},
+			Verbs:         []string{"use"},
+		}}))
+		Expect(metav1.IsControlledBy(role, share)).To(BeTrue())
+
+		binding, err := getBinding("team-a")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(binding.Subjects).To(ConsistOf(HaveField("Name", common.DefaultShareServiceAccountName)))
+		binding, err = getBinding("team-b")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(binding.Subjects).To(ConsistOf(HaveField("Name", "java-builder")))
+		_, err = getBinding("team-c")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should bind the service accounts listed by the share", func() {
+		annotations := share.GetAnnotations()
+		annotations[common.ShareGrantServiceAccountsAnnotation] = "builder, deployer"
+		share.SetAnnotations(annotations)
+		_, err := grant.CreateOrUpdate(ctx, share)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		binding, err := getBinding("team-b")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(binding.Subjects).To(ConsistOf(HaveField("Name", "builder"), HaveField("Name", "deployer")))
+	})
+
+	It("should remove the grants withdrawn from a namespace", func() {
+		_, err := grant.CreateOrUpdate(ctx, share)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		share.SetAnnotations(map[string]string{common.ShareGrantNamespacesAnnotation: "team-b"})
+		namespaces, err := grant.CreateOrUpdate(ctx, share)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(namespaces).To(ConsistOf("team-b"))
+		_, err = getBinding("team-a")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		err = fakeClient.Get(ctx, client.ObjectKey{Namespace: "team-a", Name: "sharedsecret-maven-settings"}, &rbacv1.Role{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		share.SetAnnotations(nil)
+		Expect(sharedresource.IsGranted(share)).To(BeFalse())
+		namespaces, err = grant.CreateOrUpdate(ctx, share)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(namespaces).To(BeEmpty())
+		_, err = getBinding("team-b")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should not modify a role of the same name which is not a grant", func() {
+		role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "sharedsecret-maven-settings"}}
+		Expect(fakeClient.Create(ctx, role)).To(Succeed())
+
+		_, err := grant.CreateOrUpdate(ctx, share)
+		Expect(err).To(MatchError(ContainSubstring("Role team-a/sharedsecret-maven-settings exists and is not a share grant")))
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(role), role)).To(Succeed())
+		Expect(role.Rules).To(BeEmpty())
+		Expect(role.OwnerReferences).To(BeEmpty())
+	})
+
+	It("should match the namespaces granted by the share", func() {
+		Expect(sharedresource.IsGrantedTo(share, namespace("team-a", nil))).To(BeTrue())
+		Expect(sharedresource.IsGrantedTo(share, namespace("team-c", map[string]string{"builds": "maven"}))).To(BeTrue())
+		Expect(sharedresource.IsGrantedTo(share, namespace("team-c", nil))).To(BeFalse())
+	})
+
+	It("should reject an invalid namespace selector", func() {
+		share.SetAnnotations(map[string]string{common.ShareGrantNamespaceSelectorAnnotation: "builds in maven"})
+		_, err := grant.CreateOrUpdate(ctx, share)
+		Expect(err).Should(HaveOccurred())
+	})
+})
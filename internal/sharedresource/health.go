@@ -0,0 +1,171 @@
+package sharedresource
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// UnhealthyNodesThreshold is the percentage of unhealthy nodes above which the CSI driver is
+// considered degraded. It tolerates nodes restarting the node plugin during a rollout.
+const UnhealthyNodesThreshold = 10
+
+// nodePluginLabel selects the pods of the CSI driver node plugin
+var nodePluginLabel = client.MatchingLabels{"app": common.SharedResourceNodeDaemonSetName}
+
+// daemonSetTaints are the taints tolerated by every DaemonSet pod, as the node plugin sets no
+// toleration of its own
+var daemonSetTaints = []string{
+	corev1.TaintNodeNotReady,
+	corev1.TaintNodeUnreachable,
+	corev1.TaintNodeUnschedulable,
+	corev1.TaintNodeMemoryPressure,
+	corev1.TaintNodeDiskPressure,
+	corev1.TaintNodePIDPressure,
+	corev1.TaintNodeNetworkUnavailable,
+}
+
+// NodeHealth inspects the nodes, the CSINode objects and the node plugin pods, and returns the CSI
+// driver registration state of every schedulable node the node plugin should run on. Nodes without
+// a node plugin pod are reported as unhealthy.
+func NodeHealth(ctx context.Context, reader client.Reader, namespace string) (*openshiftv1alpha1.SharedResourceStatus, error) {
+	nodes := &corev1.NodeList{}
+	if err := reader.List(ctx, nodes); err != nil {
+		return nil, err
+	}
+	pods := &corev1.PodList{}
+	if err := reader.List(ctx, pods, client.InNamespace(namespace), nodePluginLabel); err != nil {
+		return nil, err
+	}
+	csiNodes := &storagev1.CSINodeList{}
+	if err := reader.List(ctx, csiNodes); err != nil {
+		return nil, err
+	}
+
+	registered := map[string]bool{}
+	for _, csiNode := range csiNodes.Items {
+		registered[csiNode.Name] = slices.ContainsFunc(csiNode.Spec.Drivers, func(driver storagev1.CSINodeDriver) bool {
+			return driver.Name == common.SharedResourceCSIDriverName
+		})
+	}
+	nodePods := map[string][]*corev1.Pod{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if nodeName := podNodeName(pod); nodeName != "" {
+			nodePods[nodeName] = append(nodePods[nodeName], pod)
+		}
+	}
+
+	status := &openshiftv1alpha1.SharedResourceStatus{}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if !isPluginNode(node) {
+			continue
+		}
+		status.ExpectedNodes++
+
+		reason, message := "NodePluginMissing", fmt.Sprintf("no node plugin pod is scheduled on node %s", node.Name)
+		for _, pod := range nodePods[node.Name] {
+			// a node is healthy if one of its pods is, such as during a rollout
+			if reason, message = podFailure(pod); reason == "" {
+				break
+			}
+		}
+		switch {
+		case reason != "":
+			status.UnhealthyNodes = append(status.UnhealthyNodes, openshiftv1alpha1.NodeStatus{
+				Name:    node.Name,
+				Reason:  reason,
+				Message: message,
+			})
+		case !registered[node.Name]:
+			status.UnhealthyNodes = append(status.UnhealthyNodes, openshiftv1alpha1.NodeStatus{
+				Name:    node.Name,
+				Reason:  "NotRegistered",
+				Message: fmt.Sprintf("CSI driver %s is not registered in the CSINode", common.SharedResourceCSIDriverName),
+			})
+		default:
+			status.RegisteredNodes++
+		}
+	}
+
+	sort.Slice(status.UnhealthyNodes, func(i, j int) bool {
+		return status.UnhealthyNodes[i].Name < status.UnhealthyNodes[j].Name
+	})
+	return status, nil
+}
+
+// isPluginNode returns true if the node is schedulable and the node plugin tolerates its taints
+func isPluginNode(node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !slices.Contains(daemonSetTaints, taint.Key) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsDegraded returns true if the percentage of unhealthy nodes is above UnhealthyNodesThreshold
+func IsDegraded(status *openshiftv1alpha1.SharedResourceStatus) bool {
+	if status == nil || status.ExpectedNodes == 0 {
+		return false
+	}
+	return len(status.UnhealthyNodes)*100 > int(status.ExpectedNodes)*UnhealthyNodesThreshold
+}
+
+// podNodeName returns the node the pod runs on, or the node the DaemonSet targets when the pod
+// is not scheduled yet
+func podNodeName(pod *corev1.Pod) string {
+	if pod.Spec.NodeName != "" {
+		return pod.Spec.NodeName
+	}
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, field := range term.MatchFields {
+			if field.Key == "metadata.name" && len(field.Values) == 1 {
+				return field.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
+// podFailure returns the reason and message explaining why the node plugin pod is not running and
+// ready, or an empty reason if it is
+func podFailure(pod *corev1.Pod) (string, string) {
+	for _, container := range pod.Status.ContainerStatuses {
+		if waiting := container.State.Waiting; waiting != nil && waiting.Reason != "" && waiting.Reason != "ContainerCreating" {
+			message := fmt.Sprintf("container %s is waiting", container.Name)
+			if waiting.Message != "" {
+				message += ": " + waiting.Message
+			}
+			return waiting.Reason, message
+		}
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return "NodePluginNotRunning", fmt.Sprintf("node plugin pod %s is %s", pod.Name, pod.Status.Phase)
+	}
+	ready := slices.ContainsFunc(pod.Status.Conditions, func(condition corev1.PodCondition) bool {
+		return condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue
+	})
+	if !ready {
+		return "NodePluginNotReady", fmt.Sprintf("node plugin pod %s is not ready", pod.Name)
+	}
+	return "", ""
+}
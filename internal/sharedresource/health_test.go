@@ -0,0 +1,137 @@
+package sharedresource_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/redhat-openshift-builds/operator/internal/sharedresource"
+)
+
+// nodePluginPod returns a node plugin pod running on the node
+func nodePluginPod(node string, phase corev1.PodPhase, waitingReason string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      common.SharedResourceNodeDaemonSetName + "-" + node,
+			Namespace: common.OpenShiftBuildNamespaceName,
+			Labels:    map[string]string{"app": common.SharedResourceNodeDaemonSetName},
+		},
+		Spec:   corev1.PodSpec{NodeName: node},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+	if phase == corev1.PodRunning && waitingReason == "" {
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	}
+	if waitingReason != "" {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "hostpath",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: waitingReason}},
+		}}
+	}
+	return pod
+}
+
+// node returns a schedulable node with the given taints
+func node(name string, taints ...corev1.Taint) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Taints: taints},
+	}
+}
+
+// csiNode returns the CSINode of the node with the given drivers registered
+func csiNode(node string, drivers ...string) *storagev1.CSINode {
+	object := &storagev1.CSINode{ObjectMeta: metav1.ObjectMeta{Name: node}}
+	for _, driver := range drivers {
+		object.Spec.Drivers = append(object.Spec.Drivers, storagev1.CSINodeDriver{Name: driver, NodeID: node})
+	}
+	return object
+}
+
+var _ = Describe("NodeHealth", Label("sharedresource", "health"), func() {
+	var (
+		ctx     context.Context
+		objects []client.Object
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		objects = []client.Object{
+			node("healthy"),
+			nodePluginPod("healthy", corev1.PodRunning, ""),
+			csiNode("healthy", common.SharedResourceCSIDriverName),
+		}
+	})
+
+	nodeHealth := func() *openshiftv1alpha1.SharedResourceStatus {
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		status, err := sharedresource.NodeHealth(ctx, reader, common.OpenShiftBuildNamespaceName)
+		Expect(err).ShouldNot(HaveOccurred())
+		return status
+	}
+
+	It("should count the nodes where the driver is registered", func() {
+		status := nodeHealth()
+		Expect(status.ExpectedNodes).To(BeEquivalentTo(1))
+		Expect(status.RegisteredNodes).To(BeEquivalentTo(1))
+		Expect(status.UnhealthyNodes).To(BeEmpty())
+		Expect(sharedresource.IsDegraded(status)).To(BeFalse())
+	})
+
+	It("should report the nodes where the driver is missing or crash looping", func() {
+		objects = append(objects,
+			node("unregistered"),
+			node("crashing"),
+			nodePluginPod("unregistered", corev1.PodRunning, ""),
+			csiNode("unregistered", "other.csi.example.com"),
+			nodePluginPod("crashing", corev1.PodRunning, "CrashLoopBackOff"),
+			csiNode("crashing", common.SharedResourceCSIDriverName),
+		)
+		status := nodeHealth()
+		Expect(status.ExpectedNodes).To(BeEquivalentTo(3))
+		Expect(status.RegisteredNodes).To(BeEquivalentTo(1))
+		Expect(status.UnhealthyNodes).To(Equal([]openshiftv1alpha1.NodeStatus{
+			{Name: "crashing", Reason: "CrashLoopBackOff", Message: "container hostpath is waiting"},
+			{Name: "unregistered", Reason: "NotRegistered", Message: "CSI driver csi.sharedresource.openshift.io is not registered in the CSINode"},
+		}))
+		Expect(sharedresource.IsDegraded(status)).To(BeTrue())
+	})
+
+	It("should report the schedulable nodes without a ready node plugin pod", func() {
+		cordoned := node("cordoned")
+		cordoned.Spec.Unschedulable = true
+		objects = append(objects,
+			node("missing"),
+			node("not-ready", corev1.Taint{Key: corev1.TaintNodeNotReady, Effect: corev1.TaintEffectNoExecute}),
+			node("control-plane", corev1.Taint{Key: "node-role.kubernetes.io/master", Effect: corev1.TaintEffectNoSchedule}),
+			cordoned,
+		)
+		status := nodeHealth()
+		Expect(status.ExpectedNodes).To(BeEquivalentTo(3))
+		Expect(status.RegisteredNodes).To(BeEquivalentTo(1))
+		Expect(status.UnhealthyNodes).To(ConsistOf(
+			HaveField("Name", "missing"),
+			HaveField("Name", "not-ready"),
+		))
+		Expect(status.UnhealthyNodes[0].Reason).To(Equal("NodePluginMissing"))
+	})
+
+	It("should tolerate unhealthy nodes below the threshold", func() {
+		status := &openshiftv1alpha1.SharedResourceStatus{
+			ExpectedNodes:   20,
+			RegisteredNodes: 18,
+			UnhealthyNodes:  []openshiftv1alpha1.NodeStatus{{Name: "a"}, {Name: "b"}},
+		}
+		Expect(sharedresource.IsDegraded(status)).To(BeFalse())
+		status.UnhealthyNodes = append(status.UnhealthyNodes, openshiftv1alpha1.NodeStatus{Name: "c"})
+		Expect(sharedresource.IsDegraded(status)).To(BeTrue())
+	})
+})
@@ -0,0 +1,55 @@
+package sharedresource
+
+import (
+	"strings"
+
+	"github.com/manifestival/manifestival"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// injectNamespaceReferences is a Manifestival transformer that points the references to the
+// operator namespace, which manifestival.InjectNamespace does not know about, to the namespace:
+// the sources of the shares and the metrics service name verified by Prometheus
+func injectNamespaceReferences(namespace string) manifestival.Transformer {
+	return func(object *unstructured.Unstructured) error {
+		switch object.GetKind() {
+		case "SharedConfigMap":
+			return setRefNamespace(object, namespace, "spec", "configMapRef")
+		case "SharedSecret":
+			return setRefNamespace(object, namespace, "spec", "secretRef")
+		case "ServiceMonitor":
+			endpoints, _, err := unstructured.NestedSlice(object.Object, "spec", "endpoints")
+			if err != nil {
+				return err
+			}
+			for _, endpoint := range endpoints {
+				endpoint, ok := endpoint.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				serverName, found, err := unstructured.NestedString(endpoint, "tlsConfig", "serverName")
+				if err != nil || !found {
+					continue
+				}
+				service, _, _ := strings.Cut(serverName, ".")
+				if err := unstructured.SetNestedField(endpoint, service+"."+namespace+".svc", "tlsConfig", "serverName"); err != nil {
+					return err
+				}
+			}
+			return unstructured.SetNestedSlice(object.Object, endpoints, "spec", "endpoints")
+		}
+		return nil
+	}
+}
+
+// setRefNamespace sets the namespace of the share source when it is the operator namespace.
+// Shares of objects in other namespaces, such as the cluster entitlement, are left untouched.
+func setRefNamespace(object *unstructured.Unstructured, namespace string, fields ...string) error {
+	fields = append(fields, "namespace")
+	refNamespace, _, err := unstructured.NestedString(object.Object, fields...)
+	if err != nil || refNamespace != common.OpenShiftBuildNamespaceName {
+		return err
+	}
+	return unstructured.SetNestedField(object.Object, namespace, fields...)
+}
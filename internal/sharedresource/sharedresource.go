@@ -1,38 +1,59 @@
 package sharedresource
 
 import (
+	"context"
+
 	"github.com/go-logr/logr"
 	"github.com/manifestival/manifestival"
 	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
 	"github.com/redhat-openshift-builds/operator/internal/common"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // SharedResource type defines methods to Get, Create v1alpha1.SharedResource resource
 type SharedResource struct {
+	Client   client.Client
 	Logger   logr.Logger
 	Manifest manifestival.Manifest
 	State    openshiftv1alpha1.State
 }
 
 // New creates new instance of SharedResource type
-func New(manifest manifestival.Manifest) *SharedResource {
+func New(client client.Client, manifest manifestival.Manifest) *SharedResource {
 	return &SharedResource{
+		Client:   client,
 		Manifest: manifest,
 	}
 }
 
 // UpdateSharedResource transforms the manifests, and applies or deletes them based on SharedResource.State.
-func (sr *SharedResource) Reconcile(owner *openshiftv1alpha1.OpenShiftBuild) error {
+func (sr *SharedResource) Reconcile(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
 	logger := sr.Logger.WithValues("name", owner.Name)
 	sr.State = owner.Spec.SharedResource.State
 
 	// Applying transformers
 	transformerfuncs := []manifestival.Transformer{}
-	transformerfuncs = append(transformerfuncs, manifestival.InjectOwner(owner))
-	transformerfuncs = append(transformerfuncs, manifestival.InjectNamespace(common.OpenShiftBuildNamespaceName))
+	transformerfuncs = append(transformerfuncs, exceptCRDs(manifestival.InjectOwner(owner)))
+	transformerfuncs = append(transformerfuncs, injectManagedAnnotation(owner))
+	transformerfuncs = append(transformerfuncs, manifestival.InjectNamespace(common.TargetNamespace(owner)))
+	transformerfuncs = append(transformerfuncs, injectNamespaceReferences(common.TargetNamespace(owner)))
+	transformerfuncs = append(transformerfuncs, common.InjectRestrictedSecurityContext)
 	if sr.State == openshiftv1alpha1.Enabled && owner.DeletionTimestamp.IsZero() {
-		transformerfuncs = append(transformerfuncs, common.InjectFinalizer(common.OpenShiftBuildFinalizerName))
+		transformerfuncs = append(transformerfuncs, exceptCRDs(common.InjectFinalizer(common.OpenShiftBuildFinalizerName)))
+
+		if err := ValidateConfig(owner.Spec.SharedResource.Config); err != nil {
+			logger.Error(err, "validating config")
+			return err
+		}
+		config, err := renderConfig(sr.Manifest, owner.Spec.SharedResource.Config)
+		if err != nil {
+			logger.Error(err, "rendering config")
+			return err
+		}
+		transformerfuncs = append(transformerfuncs, injectConfig(config))
 	}
 
 	manifest, err := sr.Manifest.Transform(transformerfuncs...)
@@ -44,22 +65,77 @@ func (sr *SharedResource) Reconcile(owner *openshiftv1alpha1.OpenShiftBuild) err
 	// The deleteManifests is invoked if either SharedResource is disabled or
 	// the owner is being deleted with enabled SharedResource
 	if !owner.DeletionTimestamp.IsZero() || sr.State == openshiftv1alpha1.Disabled {
-		return sr.deleteManifests(&manifest)
+		return sr.deleteManifests(&manifest, owner, sr.State == openshiftv1alpha1.Disabled)
+	}
+
+	// Objects of a driver installed by another party are only taken over when explicitly requested
+	conflicts, err := sr.conflicts(&manifest, owner)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		if owner.Spec.SharedResource.ExistingInstallPolicy != openshiftv1alpha1.Adopt {
+			conflictErr := &ConflictError{}
+			for _, obj := range conflicts {
+				conflictErr.Objects = append(conflictErr.Objects, obj.GetKind()+"/"+obj.GetName())
+			}
+			return conflictErr
+		}
+		if err := sr.adopt(ctx, owner, conflicts); err != nil {
+			logger.Error(err, "adopting existing installation")
+			return err
+		}
+	}
+
+	// Cluster shares which are not opted in are removed
+	optedOut := manifest.Filter(optedOutShares(owner))
+	if err := sr.deleteManifests(&optedOut, owner, true); err != nil {
+		return err
 	}
 
 	logger.Info("Applying manifests...")
-	return manifest.Apply()
+	return manifest.Filter(manifestival.Not(manifestival.In(optedOut))).Apply()
 }
 
-// deleteManifests removes the applied finalizer from all manifest.Resources &
-// performs deletion of the resources if SharedResource.State is disabled.
-func (sr *SharedResource) deleteManifests(manifest *manifestival.Manifest) error {
+// optedOutShares returns a predicate matching the resources of the optional cluster shares which
+// are not enabled
+func optedOutShares(owner *openshiftv1alpha1.OpenShiftBuild) manifestival.Predicate {
+	shares := owner.Spec.SharedResource.ClusterShares
+	if shares != nil && shares.PullSecret == openshiftv1alpha1.Enabled {
+		return manifestival.Nothing
+	}
+	return manifestival.ByName(common.PullSecretShareName)
+}
+
+// deleteManifests removes the applied finalizer from all manifest.Resources controlled by the
+// owner & performs deletion of the resources if remove is true. Objects of another installation
+// are left untouched. CRDs are never deleted, as deleting them would delete every user share.
+func (sr *SharedResource) deleteManifests(manifest *manifestival.Manifest, owner *openshiftv1alpha1.OpenShiftBuild, remove bool) error {
 	mfc := sr.Manifest.Client
 	for _, res := range manifest.Resources() {
 		obj, err := mfc.Get(&res)
-		if err != nil && !errors.IsNotFound(err) {
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
 			return err
 		}
+		if !metav1.IsControlledBy(obj, owner) && !isManagedCRD(obj, owner) {
+			continue
+		}
+
+		// CRDs installed by previous versions are released so that they are not garbage collected
+		// with the owner
+		if manifestival.CRDs(obj) {
+			if len(obj.GetFinalizers()) > 0 || len(obj.GetOwnerReferences()) > 0 {
+				obj.SetFinalizers([]string{})
+				obj.SetOwnerReferences(nil)
+				if err := mfc.Update(obj); err != nil {
+					return err
+				}
+			}
+			continue
+		}
 
 		// removes finalizers
 		if len(obj.GetFinalizers()) > 0 {
@@ -69,12 +145,45 @@ func (sr *SharedResource) deleteManifests(manifest *manifestival.Manifest) error
 			}
 		}
 
-		// Perform explicit deletion of resources only when SharedResource is Disabled.
-		// When owner is set for deletion, the deletion of resources will be performed by reconciler.
-		if sr.State == openshiftv1alpha1.Disabled {
+		// Perform explicit deletion of resources only when SharedResource is Disabled or the
+		// resource is opted out. When owner is set for deletion, the deletion of resources will be
+		// performed by reconciler.
+		if remove {
 			sr.Logger.Info("Deleting SharedResources")
 			mfc.Delete(&res)
 		}
 	}
 	return nil
 }
+
+// exceptCRDs returns a transformer applying the given transformer to every resource but CRDs.
+// CRDs are not owned by the OpenShiftBuild so that user shares outlive it.
+func exceptCRDs(transformer manifestival.Transformer) manifestival.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if manifestival.CRDs(u) {
+			return nil
+		}
+		return transformer(u)
+	}
+}
+
+// injectManagedAnnotation records the owner managing the CRDs, in place of an owner reference
+func injectManagedAnnotation(owner *openshiftv1alpha1.OpenShiftBuild) manifestival.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if !manifestival.CRDs(u) {
+			return nil
+		}
+		annotations := u.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[common.SharedResourceManagedAnnotation] = owner.Name
+		u.SetAnnotations(annotations)
+		return nil
+	}
+}
+
+// isManagedCRD reports whether obj is a CRD managed by the owner
+func isManagedCRD(obj *unstructured.Unstructured, owner *openshiftv1alpha1.OpenShiftBuild) bool {
+	return manifestival.CRDs(obj) && obj.GetAnnotations()[common.SharedResourceManagedAnnotation] == owner.Name
+}
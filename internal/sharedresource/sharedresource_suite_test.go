@@ -0,0 +1,40 @@
+package sharedresource_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var scheme *runtime.Scheme
+
+func TestSharedResource(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SharedResource Suite")
+}
+
+var _ = BeforeSuite(func() {
+	scheme = runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+	Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+	// register the shares and monitoring objects as unstructured objects
+	for _, gvk := range []schema.GroupVersionKind{
+		{Group: "sharedresource.openshift.io", Version: "v1alpha1", Kind: "SharedConfigMap"},
+		{Group: "sharedresource.openshift.io", Version: "v1alpha1", Kind: "SharedSecret"},
+		{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"},
+	} {
+		scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		gvk.Kind += "List"
+		scheme.AddKnownTypeWithName(gvk, &unstructured.UnstructuredList{})
+	}
+})
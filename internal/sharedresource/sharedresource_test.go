@@ -0,0 +1,317 @@
+package sharedresource_test
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	manifestivalclient "github.com/manifestival/controller-runtime-client"
+	"github.com/manifestival/manifestival"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/redhat-openshift-builds/operator/internal/sharedresource"
+)
+
+var sharedResourceManifestPath = filepath.Join("..", "..", common.SharedResourceManifestPath)
+
+// getSharedSecret fetches the named SharedSecret
+func getSharedSecret(ctx context.Context, c client.Client, name string) (*unstructured.Unstructured, error) {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "sharedresource.openshift.io",
+		Version: "v1alpha1",
+		Kind:    "SharedSecret",
+	})
+	err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: common.OpenShiftBuildNamespaceName}, object)
+	return object, err
+}
+
+var _ = Describe("SharedResource", Label("sharedresource"), func() {
+	var (
+		ctx            context.Context
+		fakeClient     client.Client
+		sharedResource *sharedresource.SharedResource
+		owner          *openshiftv1alpha1.OpenShiftBuild
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+		manifest, err := manifestival.NewManifest(sharedResourceManifestPath,
+			manifestival.UseClient(manifestivalclient.NewClient(fakeClient)),
+		)
+		Expect(err).ShouldNot(HaveOccurred())
+		sharedResource = sharedresource.New(fakeClient, manifest)
+		owner = &openshiftv1alpha1.OpenShiftBuild{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: openshiftv1alpha1.GroupVersion.String(),
+				Kind:       "OpenShiftBuild",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: common.OpenShiftBuildResourceName,
+				UID:  uuid.NewUUID(),
+			},
+			Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+				SharedResource: &openshiftv1alpha1.SharedResource{State: openshiftv1alpha1.Enabled},
+			},
+		}
+	})
+
+	It("should share the cluster RHEL entitlement without granting it to every service account", func() {
+		Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+		share, err := getSharedSecret(ctx, fakeClient, common.EntitlementShareName)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(share.Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("secretRef", And(
+			HaveKeyWithValue("name", "etc-pki-entitlement"),
+			HaveKeyWithValue("namespace", "openshift-config-managed"),
+		))))
+
+		role := &rbacv1.ClusterRole{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Name: common.EntitlementShareName}, role)).To(Succeed())
+		err = fakeClient.Get(ctx, client.ObjectKey{Name: common.EntitlementShareName}, &rbacv1.ClusterRoleBinding{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should not share the cluster pull secret by default", func() {
+		Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+		_, err := getSharedSecret(ctx, fakeClient, common.PullSecretShareName)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should share the cluster pull secret when enabled and remove it when disabled", func() {
+		owner.Spec.SharedResource.ClusterShares = &openshiftv1alpha1.ClusterShares{PullSecret: openshiftv1alpha1.Enabled}
+		Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+		_, err := getSharedSecret(ctx, fakeClient, common.PullSecretShareName)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		owner.Spec.SharedResource.ClusterShares.PullSecret = openshiftv1alpha1.Disabled
+		Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+		_, err = getSharedSecret(ctx, fakeClient, common.PullSecretShareName)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		role := &rbacv1.ClusterRole{}
+		err = fakeClient.Get(ctx, client.ObjectKey{Name: common.PullSecretShareName}, role)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should install the driver in the target namespace", func() {
+		owner.Spec.TargetNamespace = "builds-system"
+		Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+
+		daemonSet := &appsv1.DaemonSet{}
+		key := client.ObjectKey{Name: common.SharedResourceNodeDaemonSetName, Namespace: "builds-system"}
+		Expect(fakeClient.Get(ctx, key, daemonSet)).To(Succeed())
+
+		binding := &rbacv1.ClusterRoleBinding{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "openshift-builds-shared-resource-node-privileged"}, binding)).To(Succeed())
+		Expect(binding.Subjects).To(ConsistOf(HaveField("Namespace", "builds-system")))
+
+		share := &unstructured.Unstructured{}
+		share.SetGroupVersionKind(schema.GroupVersionKind{
+			Group:   "sharedresource.openshift.io",
+			Version: "v1alpha1",
+			Kind:    "SharedConfigMap",
+		})
+		key = client.ObjectKey{Name: common.TrustedCABundleConfigMapName, Namespace: "builds-system"}
+		Expect(fakeClient.Get(ctx, key, share)).To(Succeed())
+		Expect(share.Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("configMapRef",
+			HaveKeyWithValue("namespace", "builds-system"),
+		)))
+
+		monitor := &unstructured.Unstructured{}
+		monitor.SetGroupVersionKind(schema.GroupVersionKind{
+			Group:   "monitoring.coreos.com",
+			Version: "v1",
+			Kind:    "ServiceMonitor",
+		})
+		key = client.ObjectKey{Name: "shared-resource-csi-driver-node-monitor", Namespace: "builds-system"}
+		Expect(fakeClient.Get(ctx, key, monitor)).To(Succeed())
+		endpoints, _, err := unstructured.NestedSlice(monitor.Object, "spec", "endpoints")
+		Expect(err).ShouldNot(HaveOccurred())
+		for _, endpoint := range endpoints {
+			Expect(endpoint).To(HaveKeyWithValue("tlsConfig", HaveKeyWithValue("serverName",
+				"shared-resource-csi-driver-node-metrics.builds-system.svc",
+			)))
+		}
+	})
+
+	When("the driver configuration is set", func() {
+		getConfig := func() string {
+			object := &corev1.ConfigMap{}
+			key := client.ObjectKey{Name: common.SharedResourceConfigMapName, Namespace: common.OpenShiftBuildNamespaceName}
+			Expect(fakeClient.Get(ctx, key, object)).To(Succeed())
+			return object.Data[common.SharedResourceConfigKey]
+		}
+		getConfigHash := func() string {
+			object := &appsv1.DaemonSet{}
+			key := client.ObjectKey{Name: common.SharedResourceNodeDaemonSetName, Namespace: common.OpenShiftBuildNamespaceName}
+			Expect(fakeClient.Get(ctx, key, object)).To(Succeed())
+			return object.Spec.Template.Annotations[common.SharedResourceConfigHashAnnotation]
+		}
+
+		It("should render it on top of the default configuration", func() {
+			owner.Spec.SharedResource.Config = &openshiftv1alpha1.SharedResourceConfig{
+				IgnoredNamespaces:   []string{"platform-tools"},
+				RefreshPeriod:       &metav1.Duration{Duration: 5 * time.Minute},
+				RefreshResources:    ptr.To(false),
+				ShareRelistInterval: &metav1.Duration{Duration: time.Hour},
+			}
+			Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+			config := getConfig()
+			Expect(config).To(ContainSubstring("- openshift-machine-api\n"))
+			Expect(config).To(ContainSubstring("- platform-tools\n"))
+			Expect(config).To(ContainSubstring("refreshPeriod: 5m0s\n"))
+			Expect(config).To(ContainSubstring("refreshResources: false\n"))
+			Expect(config).To(ContainSubstring("shareRelistInterval: 1h0m0s\n"))
+		})
+
+		It("should restart the node plugin when the configuration changes", func() {
+			Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+			hash := getConfigHash()
+			Expect(hash).NotTo(BeEmpty())
+
+			Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+			Expect(getConfigHash()).To(Equal(hash))
+
+			owner.Spec.SharedResource.Config = &openshiftv1alpha1.SharedResourceConfig{RefreshResources: ptr.To(false)}
+			Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+			Expect(getConfigHash()).NotTo(Equal(hash))
+		})
+
+		It("should reject an invalid configuration", func() {
+			owner.Spec.SharedResource.Config = &openshiftv1alpha1.SharedResourceConfig{
+				IgnoredNamespaces: []string{"Invalid_Namespace"},
+				RefreshPeriod:     &metav1.Duration{Duration: time.Second},
+			}
+			err := sharedResource.Reconcile(ctx, owner)
+			Expect(err).To(MatchError(ContainSubstring("invalid ignored namespace")))
+			Expect(err).To(MatchError(ContainSubstring("invalid refreshPeriod")))
+		})
+	})
+
+	When("the driver is installed by another party", func() {
+		var (
+			csiDriver *storagev1.CSIDriver
+			crd       *apiextensionsv1.CustomResourceDefinition
+			share     *unstructured.Unstructured
+		)
+
+		BeforeEach(func() {
+			csiDriver = &storagev1.CSIDriver{ObjectMeta: metav1.ObjectMeta{
+				Name:      common.SharedResourceCSIDriverName,
+				Namespace: common.OpenShiftBuildNamespaceName,
+			}}
+			Expect(fakeClient.Create(ctx, csiDriver)).To(Succeed())
+			crd = &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{
+				Name: "sharedsecrets.sharedresource.openshift.io",
+			}}
+			Expect(fakeClient.Create(ctx, crd)).To(Succeed())
+			share = &unstructured.Unstructured{}
+			share.SetGroupVersionKind(schema.GroupVersionKind{
+				Group:   "sharedresource.openshift.io",
+				Version: "v1alpha1",
+				Kind:    "SharedSecret",
+			})
+			share.SetName("user-share")
+			Expect(fakeClient.Create(ctx, share)).To(Succeed())
+		})
+
+		It("should report a conflict without changing the existing objects", func() {
+			err := sharedResource.Reconcile(ctx, owner)
+			conflictErr := &sharedresource.ConflictError{}
+			Expect(err).To(BeAssignableToTypeOf(conflictErr))
+			Expect(err.(*sharedresource.ConflictError).Objects).To(ConsistOf(
+				"CSIDriver/"+common.SharedResourceCSIDriverName,
+				"CustomResourceDefinition/sharedsecrets.sharedresource.openshift.io",
+			))
+			_, err = getSharedSecret(ctx, fakeClient, common.EntitlementShareName)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should not delete the existing objects when disabled", func() {
+			owner.Spec.SharedResource.State = openshiftv1alpha1.Disabled
+			Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(crd), crd)).To(Succeed())
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(csiDriver), csiDriver)).To(Succeed())
+		})
+
+		It("should take ownership of the existing objects when adopting", func() {
+			owner.Spec.SharedResource.ExistingInstallPolicy = openshiftv1alpha1.Adopt
+			Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(crd), crd)).To(Succeed())
+			Expect(crd.OwnerReferences).To(BeEmpty())
+			Expect(crd.Annotations).To(HaveKeyWithValue(common.SharedResourceAdoptedAnnotation, owner.Name))
+			Expect(crd.Annotations).To(HaveKeyWithValue(common.SharedResourceManagedAnnotation, owner.Name))
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(csiDriver), csiDriver)).To(Succeed())
+			Expect(metav1.IsControlledBy(csiDriver, owner)).To(BeTrue())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(share), share)).To(Succeed())
+			Expect(share.GetAnnotations()).To(HaveKeyWithValue(common.SharedResourceAdoptedAnnotation, owner.Name))
+			Expect(share.GetOwnerReferences()).To(BeEmpty())
+
+			Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+		})
+
+		It("should keep the adopted CRDs and shares when disabled", func() {
+			owner.Spec.SharedResource.ExistingInstallPolicy = openshiftv1alpha1.Adopt
+			Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+
+			owner.Spec.SharedResource.State = openshiftv1alpha1.Disabled
+			Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(crd), crd)).To(Succeed())
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(share), share)).To(Succeed())
+			err := fakeClient.Get(ctx, client.ObjectKeyFromObject(csiDriver), csiDriver)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	It("should not own the CRDs and keep them when disabled", func() {
+		Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		key := client.ObjectKey{Name: "sharedsecrets.sharedresource.openshift.io"}
+		Expect(fakeClient.Get(ctx, key, crd)).To(Succeed())
+		Expect(crd.OwnerReferences).To(BeEmpty())
+		Expect(crd.Finalizers).To(BeEmpty())
+
+		owner.Spec.SharedResource.State = openshiftv1alpha1.Disabled
+		Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+		Expect(fakeClient.Get(ctx, key, crd)).To(Succeed())
+	})
+
+	It("should release the CRDs owned by previous versions", func() {
+		crd := &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{
+			Name: "sharedsecrets.sharedresource.openshift.io",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: owner.APIVersion,
+				Kind:       owner.Kind,
+				Name:       owner.Name,
+				UID:        owner.UID,
+				Controller: ptr.To(true),
+			}},
+			Finalizers: []string{common.OpenShiftBuildFinalizerName},
+		}}
+		Expect(fakeClient.Create(ctx, crd)).To(Succeed())
+
+		owner.Spec.SharedResource.State = openshiftv1alpha1.Disabled
+		Expect(sharedResource.Reconcile(ctx, owner)).To(Succeed())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(crd), crd)).To(Succeed())
+		Expect(crd.OwnerReferences).To(BeEmpty())
+		Expect(crd.Finalizers).To(BeEmpty())
+	})
+})
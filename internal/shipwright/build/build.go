@@ -15,15 +15,13 @@ import (
 
 // ShipwrightBuild type defines methods to Get, Create, Delete v1alpha1.ShipwrightBuild resource
 type ShipwrightBuild struct {
-	Client    client.Client
-	Namespace string
+	Client client.Client
 }
 
 // New creates new instance of ShipwrightBuild type
-func New(client client.Client, namespace string) *ShipwrightBuild {
+func New(client client.Client) *ShipwrightBuild {
 	return &ShipwrightBuild{
-		Client:    client,
-		Namespace: namespace,
+		Client: client,
 	}
 }
 
@@ -70,7 +68,7 @@ func (sb *ShipwrightBuild) CreateOrUpdate(ctx context.Context, owner client.Obje
 
 	return ctrl.CreateOrUpdate(ctx, sb.Client, object, func() error {
 		object.Spec = shipwrightv1alpha1.ShipwrightBuildSpec{
-			TargetNamespace: sb.Namespace,
+			TargetNamespace: common.TargetNamespace(owner),
 		}
 		controllerutil.AddFinalizer(object, common.OpenShiftBuildFinalizerName)
 		if err := ctrl.SetControllerReference(owner, object, sb.Client.Scheme()); err != nil {
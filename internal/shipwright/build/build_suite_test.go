@@ -7,6 +7,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -24,6 +25,7 @@ func TestBuild(t *testing.T) {
 var _ = BeforeSuite(func() {
 	scheme = runtime.NewScheme()
 	shipwrightv1alpha1.AddToScheme(scheme)
+	apiextensionsv1.AddToScheme(scheme)
 
 	// create an owner object
 	gvk := schema.GroupVersionKind{
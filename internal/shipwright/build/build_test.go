@@ -31,7 +31,7 @@ var _ = Describe("Build", Label("shipwright", "build"), func() {
 	BeforeEach(OncePerOrdered, func() {
 		ctx = context.Background()
 		namespace = common.OpenShiftBuildNamespaceName
-		shipwrightBuild = build.New(fake.NewClientBuilder().WithScheme(scheme).Build(), namespace)
+		shipwrightBuild = build.New(fake.NewClientBuilder().WithScheme(scheme).Build())
 	})
 
 	JustBeforeEach(OncePerOrdered, func() {
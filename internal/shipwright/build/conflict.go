@@ -0,0 +1,122 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Conflicts holds the objects of a Shipwright Build installation managed by another party, such
+// as the community Shipwright operator
+type Conflicts struct {
+	// ShipwrightBuilds are the ShipwrightBuilds not controlled by the owner, oldest first
+	ShipwrightBuilds []shipwrightv1alpha1.ShipwrightBuild
+
+	// CRDs are the names of the Shipwright Build CRDs served by a webhook in another namespace
+	CRDs []string
+}
+
+// IsEmpty returns true if there are no conflicting objects
+func (c *Conflicts) IsEmpty() bool {
+	return len(c.ShipwrightBuilds) == 0 && len(c.CRDs) == 0
+}
+
+// ConflictError reports the objects of a Shipwright Build installation managed by another party
+type ConflictError struct {
+	Objects []string
+}
+
+// Error implements the error interface
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("Shipwright Build objects are managed by another installation: %s",
+		strings.Join(e.Objects, ", "))
+}
+
+// NewConflictError returns a ConflictError listing the conflicting objects
+func NewConflictError(conflicts *Conflicts) *ConflictError {
+	err := &ConflictError{}
+	for _, object := range conflicts.ShipwrightBuilds {
+		err.Objects = append(err.Objects, "ShipwrightBuild "+object.Name)
+	}
+	for _, name := range conflicts.CRDs {
+		err.Objects = append(err.Objects, "CustomResourceDefinition "+name)
+	}
+	return err
+}
+
+// Conflicts returns the ShipwrightBuilds not controlled by the owner, and the Shipwright Build
+// CRDs whose conversion webhook is not served from the target namespace
+func (sb *ShipwrightBuild) Conflicts(ctx context.Context, owner client.Object) (*Conflicts, error) {
+	conflicts := &Conflicts{}
+
+	list := &shipwrightv1alpha1.ShipwrightBuildList{}
+	if err := sb.Client.List(ctx, list); err != nil {
+		return nil, err
+	}
+	for _, item := range list.Items {
+		if !metav1.IsControlledBy(&item, owner) {
+			conflicts.ShipwrightBuilds = append(conflicts.ShipwrightBuilds, item)
+		}
+	}
+	sort.SliceStable(conflicts.ShipwrightBuilds, func(i, j int) bool {
+		return conflicts.ShipwrightBuilds[i].CreationTimestamp.Before(&conflicts.ShipwrightBuilds[j].CreationTimestamp)
+	})
+
+	for _, name := range common.ShipwrightBuildCRDNames {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := sb.Client.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		conversion := crd.Spec.Conversion
+		if conversion == nil || conversion.Webhook == nil || conversion.Webhook.ClientConfig == nil ||
+			conversion.Webhook.ClientConfig.Service == nil {
+			continue
+		}
+		if conversion.Webhook.ClientConfig.Service.Namespace != common.TargetNamespace(owner) {
+			conflicts.CRDs = append(conflicts.CRDs, name)
+		}
+	}
+	return conflicts, nil
+}
+
+// Adopt takes ownership of the oldest foreign ShipwrightBuild when the owner does not control one
+// yet, and returns the conflicts which cannot be resolved. CRDs are taken over by the next apply
+// of the Shipwright Build release, while additional ShipwrightBuilds must be removed manually.
+func (sb *ShipwrightBuild) Adopt(ctx context.Context, owner client.Object, conflicts *Conflicts) (*Conflicts, error) {
+	remaining := &Conflicts{ShipwrightBuilds: conflicts.ShipwrightBuilds}
+	if len(remaining.ShipwrightBuilds) == 0 {
+		return remaining, nil
+	}
+
+	if _, err := sb.Get(ctx, owner); err == nil {
+		return remaining, nil
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	object := remaining.ShipwrightBuilds[0].DeepCopy()
+	object.SetOwnerReferences(nil)
+	object.Spec.TargetNamespace = common.TargetNamespace(owner)
+	controllerutil.AddFinalizer(object, common.OpenShiftBuildFinalizerName)
+	if err := controllerutil.SetControllerReference(owner, object, sb.Client.Scheme()); err != nil {
+		return nil, err
+	}
+	if err := sb.Client.Update(ctx, object); err != nil {
+		return nil, err
+	}
+
+	remaining.ShipwrightBuilds = remaining.ShipwrightBuilds[1:]
+	return remaining, nil
+}
@@ -0,0 +1,117 @@
+package build_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build"
+)
+
+// newCRD returns a Shipwright Build CRD with a conversion webhook served from the namespace
+func newCRD(name, namespace string) *apiextensionsv1.CustomResourceDefinition {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	crd.SetName(name)
+	crd.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{
+					Name:      common.ShipwrightWebhookServiceName,
+					Namespace: namespace,
+				},
+			},
+		},
+	}
+	return crd
+}
+
+// newForeignShipwrightBuild returns a ShipwrightBuild not controlled by the owner
+func newForeignShipwrightBuild(name string, created time.Time) *shipwrightv1alpha1.ShipwrightBuild {
+	object := &shipwrightv1alpha1.ShipwrightBuild{}
+	object.SetName(name)
+	object.SetCreationTimestamp(metav1.NewTime(created))
+	object.Spec.TargetNamespace = "shipwright-build"
+	return object
+}
+
+var _ = Describe("Conflicts", Label("shipwright", "build", "conflict"), func() {
+	var (
+		ctx             context.Context
+		shipwrightBuild *build.ShipwrightBuild
+		namespace       string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = common.OpenShiftBuildNamespaceName
+		shipwrightBuild = build.New(fake.NewClientBuilder().WithScheme(scheme).Build())
+	})
+
+	When("there is no other installation", func() {
+		It("should not report any conflict", func() {
+			_, err := shipwrightBuild.CreateOrUpdate(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(shipwrightBuild.Client.Create(ctx, newCRD("builds.shipwright.io", namespace))).To(Succeed())
+			conflicts, err := shipwrightBuild.Conflicts(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(conflicts.IsEmpty()).To(BeTrue())
+		})
+	})
+
+	When("the community Shipwright operator is installed", func() {
+		BeforeEach(func() {
+			now := time.Now()
+			Expect(shipwrightBuild.Client.Create(ctx, newForeignShipwrightBuild("newer", now))).To(Succeed())
+			Expect(shipwrightBuild.Client.Create(ctx, newForeignShipwrightBuild("older", now.Add(-time.Hour)))).To(Succeed())
+			Expect(shipwrightBuild.Client.Create(ctx, newCRD("builds.shipwright.io", "shipwright-build"))).To(Succeed())
+		})
+
+		It("should report the foreign ShipwrightBuilds, oldest first, and CRDs", func() {
+			conflicts, err := shipwrightBuild.Conflicts(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(conflicts.ShipwrightBuilds).To(HaveLen(2))
+			Expect(conflicts.ShipwrightBuilds[0].Name).To(Equal("older"))
+			Expect(conflicts.CRDs).To(ConsistOf("builds.shipwright.io"))
+			Expect(build.NewConflictError(conflicts).Objects).To(ConsistOf(
+				"ShipwrightBuild older",
+				"ShipwrightBuild newer",
+				"CustomResourceDefinition builds.shipwright.io",
+			))
+		})
+
+		It("should adopt the oldest ShipwrightBuild and report the others", func() {
+			conflicts, err := shipwrightBuild.Conflicts(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			remaining, err := shipwrightBuild.Adopt(ctx, owner, conflicts)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(remaining.CRDs).To(BeEmpty())
+			Expect(remaining.ShipwrightBuilds).To(HaveLen(1))
+			Expect(remaining.ShipwrightBuilds[0].Name).To(Equal("newer"))
+
+			adopted := &shipwrightv1alpha1.ShipwrightBuild{}
+			Expect(shipwrightBuild.Client.Get(ctx, client.ObjectKey{Name: "older"}, adopted)).To(Succeed())
+			Expect(metav1.IsControlledBy(adopted, owner)).To(BeTrue())
+			Expect(adopted.Spec.TargetNamespace).To(Equal(namespace))
+			Expect(adopted.GetFinalizers()).To(ContainElement(common.OpenShiftBuildFinalizerName))
+		})
+
+		It("should not adopt a ShipwrightBuild when the owner already controls one", func() {
+			_, err := shipwrightBuild.CreateOrUpdate(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			conflicts, err := shipwrightBuild.Conflicts(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			remaining, err := shipwrightBuild.Adopt(ctx, owner, conflicts)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(remaining.ShipwrightBuilds).To(HaveLen(2))
+		})
+	})
+})
@@ -0,0 +1,206 @@
+package defaults
+
+import (
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Apply sets the cluster defaults on the Build or BuildRun fields it does not set, and enforces the
+// overrides. The Build embedded in a BuildRun is defaulted like a Build. Scheduling fields are only
+// available from the v1beta1 API.
+func Apply(object *unstructured.Unstructured, defaults *openshiftv1alpha1.BuildDefaults) error {
+	if defaults == nil {
+		return nil
+	}
+	v1beta1 := object.GroupVersionKind().Version != "v1alpha1"
+	switch object.GetKind() {
+	case "Build":
+		return applyBuildSpec(object.Object, defaults, v1beta1, "spec")
+	case "BuildRun":
+		return applyBuildRunSpec(object.Object, defaults, v1beta1)
+	}
+	return nil
+}
+
+// applyBuildSpec defaults the Build spec found at the given path
+func applyBuildSpec(object map[string]interface{}, defaults *openshiftv1alpha1.BuildDefaults, v1beta1 bool, path ...string) error {
+	spec, found, err := unstructured.NestedMap(object, path...)
+	if err != nil || !found {
+		return err
+	}
+
+	if _, found := spec["timeout"]; !found && defaults.Timeout != nil {
+		spec["timeout"] = defaults.Timeout.Duration.String()
+	}
+	if err := addEnv(spec, defaults.Env); err != nil {
+		return err
+	}
+	if retention := defaults.Retention; retention != nil {
+		setRetention(spec, retention)
+	}
+	if output, ok := spec["output"].(map[string]interface{}); ok {
+		mergeStrings(output, "labels", defaults.ImageLabels, false)
+	}
+	if v1beta1 {
+		mergeStrings(spec, "nodeSelector", defaults.NodeSelector, false)
+		if err := mergeTolerations(spec, defaults.Tolerations, false); err != nil {
+			return err
+		}
+	}
+
+	if overrides := defaults.Overrides; overrides != nil {
+		if output, ok := spec["output"].(map[string]interface{}); ok {
+			mergeStrings(output, "labels", overrides.ImageLabels, true)
+		}
+		if v1beta1 {
+			mergeStrings(spec, "nodeSelector", overrides.NodeSelector, true)
+			if err := mergeTolerations(spec, overrides.Tolerations, true); err != nil {
+				return err
+			}
+		}
+	}
+	return unstructured.SetNestedMap(object, spec, path...)
+}
+
+// applyBuildRunSpec defaults the ServiceAccount and the embedded Build of the BuildRun, and enforces
+// the scheduling overrides on the BuildRun, whose values take precedence over the ones of the Build
+func applyBuildRunSpec(object map[string]interface{}, defaults *openshiftv1alpha1.BuildDefaults, v1beta1 bool) error {
+	if defaults.ServiceAccount != "" {
+		serviceAccountField := []string{"spec", "serviceAccount"}
+		if !v1beta1 {
+			serviceAccountField = append(serviceAccountField, "name")
+		}
+		serviceAccount, _, _ := unstructured.NestedFieldNoCopy(object, serviceAccountField...)
+		generate, _, _ := unstructured.NestedBool(object, "spec", "serviceAccount", "generate")
+		if serviceAccount == nil && !generate {
+			if err := unstructured.SetNestedField(object, defaults.ServiceAccount, serviceAccountField...); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !v1beta1 {
+		return applyBuildSpec(object, defaults, v1beta1, "spec", "buildSpec")
+	}
+	if err := applyBuildSpec(object, defaults, v1beta1, "spec", "build", "spec"); err != nil {
+		return err
+	}
+
+	overrides := defaults.Overrides
+	spec, found, err := unstructured.NestedMap(object, "spec")
+	if err != nil || !found || overrides == nil {
+		return err
+	}
+	if _, found := spec["nodeSelector"]; found {
+		mergeStrings(spec, "nodeSelector", overrides.NodeSelector, true)
+	}
+	if _, found := spec["tolerations"]; found {
+		if err := mergeTolerations(spec, overrides.Tolerations, true); err != nil {
+			return err
+		}
+	}
+	return unstructured.SetNestedMap(object, spec, "spec")
+}
+
+// mergeStrings adds the values to the string map of the field. Values of existing keys are only
+// replaced when overriding.
+func mergeStrings(parent map[string]interface{}, field string, values map[string]string, override bool) {
+	if len(values) == 0 {
+		return
+	}
+	current, ok := parent[field].(map[string]interface{})
+	if !ok {
+		current = map[string]interface{}{}
+	}
+	for key, value := range values {
+		if _, found := current[key]; !found || override {
+			current[key] = value
+		}
+	}
+	parent[field] = current
+}
+
+// mergeTolerations adds the tolerations whose key is not tolerated yet. Tolerations with the same
+// key are only replaced when overriding.
+func mergeTolerations(spec map[string]interface{}, tolerations []corev1.Toleration, override bool) error {
+	if len(tolerations) == 0 {
+		return nil
+	}
+	current, _ := spec["tolerations"].([]interface{})
+	for i := range tolerations {
+		value, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&tolerations[i])
+		if err != nil {
+			return err
+		}
+		index := indexOf(current, "key", tolerations[i].Key)
+		switch {
+		case index < 0:
+			current = append(current, value)
+		case override:
+			current[index] = value
+		}
+	}
+	spec["tolerations"] = current
+	return nil
+}
+
+// addEnv adds the environment variables the spec does not set
+func addEnv(spec map[string]interface{}, env []corev1.EnvVar) error {
+	if len(env) == 0 {
+		return nil
+	}
+	current, _ := spec["env"].([]interface{})
+	for i := range env {
+		if indexOf(current, "name", env[i].Name) >= 0 {
+			continue
+		}
+		value, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&env[i])
+		if err != nil {
+			return err
+		}
+		current = append(current, value)
+	}
+	spec["env"] = current
+	return nil
+}
+
+// setRetention sets the retention fields the spec does not set
+func setRetention(spec map[string]interface{}, retention *openshiftv1alpha1.BuildRetention) {
+	current, ok := spec["retention"].(map[string]interface{})
+	if !ok {
+		current = map[string]interface{}{}
+	}
+	values := map[string]interface{}{}
+	if retention.FailedLimit != nil {
+		values["failedLimit"] = int64(*retention.FailedLimit)
+	}
+	if retention.SucceededLimit != nil {
+		values["succeededLimit"] = int64(*retention.SucceededLimit)
+	}
+	if retention.TTLAfterFailed != nil {
+		values["ttlAfterFailed"] = retention.TTLAfterFailed.Duration.String()
+	}
+	if retention.TTLAfterSucceeded != nil {
+		values["ttlAfterSucceeded"] = retention.TTLAfterSucceeded.Duration.String()
+	}
+	for key, value := range values {
+		if _, found := current[key]; !found {
+			current[key] = value
+		}
+	}
+	if len(current) > 0 {
+		spec["retention"] = current
+	}
+}
+
+// indexOf returns the index of the item whose field has the value, or -1
+func indexOf(items []interface{}, field, value string) int {
+	for i, item := range items {
+		if item, ok := item.(map[string]interface{}); ok && item[field] == value {
+			return i
+		}
+	}
+	return -1
+}
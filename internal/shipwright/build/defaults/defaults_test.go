@@ -0,0 +1,135 @@
+package defaults_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/defaults"
+)
+
+var _ = Describe("Apply", Label("shipwright", "defaults"), func() {
+	var (
+		buildDefaults *openshiftv1alpha1.BuildDefaults
+		build         *unstructured.Unstructured
+	)
+
+	newObject := func(apiVersion, kind string, spec map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": "sample", "namespace": "tenant"},
+			"spec":       spec,
+		}}
+	}
+
+	BeforeEach(func() {
+		buildDefaults = &openshiftv1alpha1.BuildDefaults{
+			Timeout:        &metav1.Duration{Duration: 30 * time.Minute},
+			ServiceAccount: "builder",
+			NodeSelector:   map[string]string{"node-role.kubernetes.io/builds": ""},
+			Tolerations: []corev1.Toleration{{
+				Key:      "builds",
+				Operator: corev1.TolerationOpExists,
+				Effect:   corev1.TaintEffectNoSchedule,
+			}},
+			Env: []corev1.EnvVar{{Name: "HTTP_PROXY", Value: "http://proxy:3128"}},
+			Retention: &openshiftv1alpha1.BuildRetention{
+				SucceededLimit: ptr.To[int32](5),
+				TTLAfterFailed: &metav1.Duration{Duration: 24 * time.Hour},
+			},
+			ImageLabels: map[string]string{"vendor": "acme"},
+		}
+		build = newObject("shipwright.io/v1beta1", "Build", map[string]interface{}{
+			"strategy": map[string]interface{}{"name": "buildah", "kind": "ClusterBuildStrategy"},
+			"output":   map[string]interface{}{"image": "quay.io/acme/sample"},
+		})
+	})
+
+	It("should set the defaults on a Build which does not set them", func() {
+		Expect(defaults.Apply(build, buildDefaults)).To(Succeed())
+
+		spec := build.Object["spec"].(map[string]interface{})
+		Expect(spec).To(HaveKeyWithValue("timeout", "30m0s"))
+		Expect(spec).To(HaveKeyWithValue("nodeSelector", map[string]interface{}{"node-role.kubernetes.io/builds": ""}))
+		Expect(spec["tolerations"]).To(ConsistOf(map[string]interface{}{
+			"key": "builds", "operator": "Exists", "effect": "NoSchedule",
+		}))
+		Expect(spec["env"]).To(ConsistOf(map[string]interface{}{"name": "HTTP_PROXY", "value": "http://proxy:3128"}))
+		Expect(spec).To(HaveKeyWithValue("retention", map[string]interface{}{
+			"succeededLimit": int64(5), "ttlAfterFailed": "24h0m0s",
+		}))
+		Expect(spec["output"]).To(HaveKeyWithValue("labels", map[string]interface{}{"vendor": "acme"}))
+	})
+
+	It("should keep the values set by the Build", func() {
+		spec := build.Object["spec"].(map[string]interface{})
+		spec["timeout"] = "2h"
+		spec["env"] = []interface{}{map[string]interface{}{"name": "HTTP_PROXY", "value": "none"}}
+		spec["nodeSelector"] = map[string]interface{}{"node-role.kubernetes.io/builds": "large"}
+		spec["output"].(map[string]interface{})["labels"] = map[string]interface{}{"vendor": "team"}
+		Expect(defaults.Apply(build, buildDefaults)).To(Succeed())
+
+		spec = build.Object["spec"].(map[string]interface{})
+		Expect(spec).To(HaveKeyWithValue("timeout", "2h"))
+		Expect(spec["env"]).To(ConsistOf(map[string]interface{}{"name": "HTTP_PROXY", "value": "none"}))
+		Expect(spec).To(HaveKeyWithValue("nodeSelector", map[string]interface{}{"node-role.kubernetes.io/builds": "large"}))
+		Expect(spec["output"]).To(HaveKeyWithValue("labels", map[string]interface{}{"vendor": "team"}))
+	})
+
+	It("should enforce the overrides", func() {
+		buildDefaults.Overrides = &openshiftv1alpha1.BuildOverrides{
+			NodeSelector: map[string]string{"node-role.kubernetes.io/builds": ""},
+			Tolerations: []corev1.Toleration{{
+				Key:      "builds",
+				Operator: corev1.TolerationOpEqual,
+				Value:    "true",
+			}},
+			ImageLabels: map[string]string{"vendor": "acme"},
+		}
+		spec := build.Object["spec"].(map[string]interface{})
+		spec["nodeSelector"] = map[string]interface{}{"node-role.kubernetes.io/builds": "large", "zone": "a"}
+		spec["output"].(map[string]interface{})["labels"] = map[string]interface{}{"vendor": "team"}
+		Expect(defaults.Apply(build, buildDefaults)).To(Succeed())
+
+		spec = build.Object["spec"].(map[string]interface{})
+		Expect(spec).To(HaveKeyWithValue("nodeSelector", map[string]interface{}{"node-role.kubernetes.io/builds": "", "zone": "a"}))
+		Expect(spec["tolerations"]).To(ConsistOf(map[string]interface{}{
+			"key": "builds", "operator": "Equal", "value": "true",
+		}))
+		Expect(spec["output"]).To(HaveKeyWithValue("labels", map[string]interface{}{"vendor": "acme"}))
+	})
+
+	It("should default the service account and the embedded Build of a BuildRun", func() {
+		buildRun := newObject("shipwright.io/v1beta1", "BuildRun", map[string]interface{}{
+			"build": map[string]interface{}{"spec": build.Object["spec"]},
+		})
+		Expect(defaults.Apply(buildRun, buildDefaults)).To(Succeed())
+
+		spec := buildRun.Object["spec"].(map[string]interface{})
+		Expect(spec).To(HaveKeyWithValue("serviceAccount", "builder"))
+		Expect(spec["build"].(map[string]interface{})["spec"]).To(HaveKeyWithValue("timeout", "30m0s"))
+		Expect(spec).NotTo(HaveKey("timeout"))
+	})
+
+	It("should only default the fields of the v1alpha1 API", func() {
+		buildRun := newObject("shipwright.io/v1alpha1", "BuildRun", map[string]interface{}{
+			"buildRef": map[string]interface{}{"name": "sample"},
+		})
+		Expect(defaults.Apply(buildRun, buildDefaults)).To(Succeed())
+		Expect(buildRun.Object["spec"]).To(HaveKeyWithValue("serviceAccount", map[string]interface{}{"name": "builder"}))
+
+		build.SetAPIVersion("shipwright.io/v1alpha1")
+		Expect(defaults.Apply(build, buildDefaults)).To(Succeed())
+		spec := build.Object["spec"].(map[string]interface{})
+		Expect(spec).To(HaveKeyWithValue("timeout", "30m0s"))
+		Expect(spec).NotTo(HaveKey("nodeSelector"))
+		Expect(spec).NotTo(HaveKey("tolerations"))
+	})
+})
@@ -0,0 +1,93 @@
+package output
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/strategy"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Policy returns the output policy of the OpenShiftBuild, or nil
+func Policy(owner *openshiftv1alpha1.OpenShiftBuild) *openshiftv1alpha1.BuildOutputPolicy {
+	if owner == nil || owner.Spec.Shipwright == nil || owner.Spec.Shipwright.Build == nil {
+		return nil
+	}
+	return owner.Spec.Shipwright.Build.OutputPolicy
+}
+
+// AllowedRegistries returns the registries the output images of the namespace may be pushed to,
+// including the ones of the exceptions matching the namespace, or nil if any registry is allowed
+func AllowedRegistries(policy *openshiftv1alpha1.BuildOutputPolicy, namespace *corev1.Namespace) ([]string, error) {
+	if policy == nil || len(policy.AllowedRegistries) == 0 {
+		return nil, nil
+	}
+	registries := slices.Clone(policy.AllowedRegistries)
+	for i := range policy.Exceptions {
+		exception := &policy.Exceptions[i]
+		selector, err := metav1.LabelSelectorAsSelector(&exception.NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("output policy exception %d: %w", i, err)
+		}
+		if !selector.Matches(labels.Set(namespace.Labels)) {
+			continue
+		}
+		if len(exception.AllowedRegistries) == 0 {
+			return nil, nil
+		}
+		registries = append(registries, exception.AllowedRegistries...)
+	}
+	return registries, nil
+}
+
+// Images returns the output images set by the Build or BuildRun, by field path. The images of a
+// BuildRun are the output override and the output of its embedded Build.
+func Images(object *unstructured.Unstructured) map[string]string {
+	fields := [][]string{{"spec", "output", "image"}}
+	if object.GetKind() == "BuildRun" {
+		embedded := []string{"spec", "build", "spec", "output", "image"}
+		// the embedded Build was named buildSpec before v1beta1
+		if object.GroupVersionKind().Version == "v1alpha1" {
+			embedded = []string{"spec", "buildSpec", "output", "image"}
+		}
+		fields = append(fields, embedded)
+	}
+
+	images := map[string]string{}
+	for _, field := range fields {
+		if image, _, _ := unstructured.NestedString(object.Object, field...); image != "" {
+			images[strings.Join(field, ".")] = image
+		}
+	}
+	return images
+}
+
+// BuildName returns the name of the Build referenced by the BuildRun, or an empty string
+func BuildName(object *unstructured.Unstructured) string {
+	// the Build was referenced by buildRef before v1beta1
+	field := []string{"spec", "build", "name"}
+	if object.GroupVersionKind().Version == "v1alpha1" {
+		field = []string{"spec", "buildRef", "name"}
+	}
+	name, _, _ := unstructured.NestedString(object.Object, field...)
+	return name
+}
+
+// Violations returns the output images which are not pushed to one of the registries
+func Violations(images map[string]string, registries []string) []string {
+	violations := []string{}
+	for path, image := range images {
+		if !strategy.IsAllowedImage(image, registries) {
+			violations = append(violations, fmt.Sprintf("%s %s is not pushed to an allowed registry (%s)",
+				path, image, strings.Join(registries, ", ")))
+		}
+	}
+	sort.Strings(violations)
+	return violations
+}
@@ -0,0 +1,98 @@
+package output_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/output"
+)
+
+var _ = Describe("Output policy", Label("shipwright", "output"), func() {
+	var policy *openshiftv1alpha1.BuildOutputPolicy
+
+	namespace := func(labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Labels: labels}}
+	}
+
+	BeforeEach(func() {
+		policy = &openshiftv1alpha1.BuildOutputPolicy{
+			AllowedRegistries: []string{"quay.io/acme"},
+			Exceptions: []openshiftv1alpha1.BuildOutputPolicyException{
+				{
+					NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "oss"}},
+					AllowedRegistries: []string{"ghcr.io/acme"},
+				},
+				{
+					NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "platform"}},
+				},
+			},
+		}
+	})
+
+	It("should add the registries of the exceptions matching the namespace", func() {
+		registries, err := output.AllowedRegistries(policy, namespace(nil))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(registries).To(Equal([]string{"quay.io/acme"}))
+
+		registries, err = output.AllowedRegistries(policy, namespace(map[string]string{"team": "oss"}))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(registries).To(Equal([]string{"quay.io/acme", "ghcr.io/acme"}))
+	})
+
+	It("should allow any registry without allowed registries or with an unrestricted exception", func() {
+		registries, err := output.AllowedRegistries(policy, namespace(map[string]string{"team": "platform"}))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(registries).To(BeNil())
+
+		registries, err = output.AllowedRegistries(&openshiftv1alpha1.BuildOutputPolicy{}, namespace(nil))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(registries).To(BeNil())
+	})
+
+	It("should return the output images of BuildRuns", func() {
+		buildRun := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "shipwright.io/v1beta1",
+			"kind":       "BuildRun",
+			"spec": map[string]interface{}{
+				"build": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"output": map[string]interface{}{"image": "quay.io/acme/app"},
+					},
+				},
+				"output": map[string]interface{}{"image": "acme/app"},
+			},
+		}}
+		Expect(output.Images(buildRun)).To(Equal(map[string]string{
+			"spec.output.image":            "acme/app",
+			"spec.build.spec.output.image": "quay.io/acme/app",
+		}))
+
+		buildRun.SetAPIVersion("shipwright.io/v1alpha1")
+		Expect(unstructured.SetNestedField(buildRun.Object, "app", "spec", "buildRef", "name")).To(Succeed())
+		Expect(output.Images(buildRun)).To(Equal(map[string]string{"spec.output.image": "acme/app"}))
+		Expect(output.BuildName(buildRun)).To(Equal("app"))
+	})
+
+	It("should report the images pushed to registries which are not allowed", func() {
+		violations := output.Violations(map[string]string{
+			"spec.output.image":            "acme/app",
+			"spec.build.spec.output.image": "quay.io/acme/app:latest",
+		}, []string{"quay.io/acme"})
+		Expect(violations).To(ConsistOf(
+			"spec.output.image acme/app is not pushed to an allowed registry (quay.io/acme)",
+		))
+	})
+
+	It("should compare the registry hosts case-insensitively", func() {
+		Expect(output.Violations(map[string]string{
+			"spec.output.image": "Quay.IO/acme/app",
+		}, []string{"QUAY.io/acme"})).To(BeEmpty())
+		Expect(output.Violations(map[string]string{
+			"spec.output.image": "Quay.IO/other/app",
+		}, []string{"quay.io/acme"})).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,24 @@
+package pruner
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// prunedBuildRuns counts the deleted BuildRuns by namespace, retention rule, and result
+	prunedBuildRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "openshift_builds_pruned_buildruns_total",
+		Help: "Number of completed BuildRuns deleted by the pruner",
+	}, []string{"namespace", "reason", "result"})
+
+	// lastPruneTimestamp is the time of the last pruning pass
+	lastPruneTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "openshift_builds_pruner_last_run_timestamp_seconds",
+		Help: "Time of the last pruning pass of the completed BuildRuns",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(prunedBuildRuns, lastPruneTimestamp)
+}
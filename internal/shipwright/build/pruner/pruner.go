@@ -0,0 +1,322 @@
+package pruner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultInterval is the time between two pruning passes when the OpenShiftBuild does not set it
+const DefaultInterval = time.Hour
+
+// pageSize is the number of BuildRuns listed per request, so that busy clusters are not listed at
+// once
+const pageSize = 500
+
+// buildRunKind is the kind of the pruned BuildRuns
+var buildRunKind = schema.GroupVersionKind{Group: "shipwright.io", Version: "v1beta1", Kind: "BuildRun"}
+
+// Pruner type defines methods to delete the completed BuildRuns exceeding the retention of their
+// namespace
+type Pruner struct {
+	Client client.Client
+	// APIReader lists the BuildRuns, which are not cached by the manager
+	APIReader client.Reader
+	// Now returns the current time
+	Now func() time.Time
+}
+
+// New creates new instance of Pruner type
+func New(client client.Client, apiReader client.Reader) *Pruner {
+	return &Pruner{
+		Client:    client,
+		APIReader: apiReader,
+		Now:       time.Now,
+	}
+}
+
+// retention defines which completed BuildRuns of a Build are kept
+type retention struct {
+	maxAge        time.Duration
+	keep          *int64
+	keepSucceeded *int64
+	keepFailed    *int64
+}
+
+// buildRun holds the fields of a completed BuildRun needed to prune it
+type buildRun struct {
+	name      string
+	namespace string
+	build     string
+	succeeded bool
+	completed time.Time
+	// reason is the retention rule expiring the BuildRun
+	reason string
+}
+
+// outcome returns the result of the BuildRun as recorded in the metrics
+func (r buildRun) outcome() string {
+	if r.succeeded {
+		return "Succeeded"
+	}
+	return "Failed"
+}
+
+// IsEnabled returns true if the OpenShiftBuild enables the pruning of completed BuildRuns
+func IsEnabled(owner *openshiftv1alpha1.OpenShiftBuild) bool {
+	return pruner(owner) != nil && pruner(owner).State == openshiftv1alpha1.Enabled
+}
+
+// Interval returns the time between two pruning passes
+func Interval(owner *openshiftv1alpha1.OpenShiftBuild) time.Duration {
+	if config := pruner(owner); config != nil && config.Interval != nil && config.Interval.Duration > 0 {
+		return config.Interval.Duration
+	}
+	return DefaultInterval
+}
+
+// Prune deletes the completed BuildRuns of every namespace exceeding the retention of the
+// OpenShiftBuild, or the one set by the annotations of the namespace. TaskRuns and pods are
+// garbage collected with their BuildRun. Returns the number of deleted BuildRuns.
+func (p *Pruner) Prune(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) (int, error) {
+	logger := log.FromContext(ctx)
+	if !IsEnabled(owner) {
+		return 0, nil
+	}
+	config := pruner(owner)
+	clusterRetention := retention{
+		keep:          toInt64(config.Keep),
+		keepSucceeded: toInt64(config.KeepSucceeded),
+		keepFailed:    toInt64(config.KeepFailed),
+	}
+	if config.MaxAge != nil {
+		clusterRetention.maxAge = config.MaxAge.Duration
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := p.Client.List(ctx, namespaces); err != nil {
+		return 0, err
+	}
+	retentions := map[string]*retention{}
+	for i := range namespaces.Items {
+		namespace := &namespaces.Items[i]
+		namespaceRetention, err := namespaceRetention(clusterRetention, namespace)
+		if err != nil {
+			logger.Error(err, "Invalid BuildRun retention, skipping namespace", "namespace", namespace.Name)
+			continue
+		}
+		retentions[namespace.Name] = namespaceRetention
+	}
+
+	groups, err := p.completedBuildRuns(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	var errs []error
+	for _, runs := range groups {
+		namespaceRetention := retentions[runs[0].namespace]
+		if namespaceRetention == nil {
+			continue
+		}
+		for _, run := range p.expired(runs, namespaceRetention) {
+			object := &unstructured.Unstructured{}
+			object.SetGroupVersionKind(buildRunKind)
+			object.SetName(run.name)
+			object.SetNamespace(run.namespace)
+			err := p.Client.Delete(ctx, object, client.PropagationPolicy(metav1.DeletePropagationBackground))
+			if client.IgnoreNotFound(err) != nil {
+				errs = append(errs, err)
+				continue
+			}
+			prunedBuildRuns.WithLabelValues(run.namespace, run.reason, run.outcome()).Inc()
+			deleted++
+		}
+	}
+	lastPruneTimestamp.SetToCurrentTime()
+	return deleted, errors.Join(errs...)
+}
+
+// expired returns the BuildRuns of a Build to delete, with the retention rule expiring them
+func (p *Pruner) expired(runs []buildRun, retention *retention) []buildRun {
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].completed.After(runs[j].completed)
+	})
+
+	result := []buildRun{}
+	var seen, succeeded, failed int64
+	now := p.Now()
+	for _, run := range runs {
+		position := succeeded
+		keepResult := retention.keepSucceeded
+		if !run.succeeded {
+			position = failed
+			keepResult = retention.keepFailed
+		}
+
+		reason := ""
+		switch {
+		case retention.maxAge > 0 && now.Sub(run.completed) > retention.maxAge:
+			reason = "MaxAge"
+		case retention.keep != nil && seen >= *retention.keep:
+			reason = "Keep"
+		case keepResult != nil && position >= *keepResult:
+			reason = "Keep" + run.outcome()
+		}
+
+		seen++
+		if run.succeeded {
+			succeeded++
+		} else {
+			failed++
+		}
+		if reason != "" {
+			run.reason = reason
+			result = append(result, run)
+		}
+	}
+	return result
+}
+
+// completedBuildRuns lists the completed BuildRuns of the cluster page by page, grouped by
+// namespace and Build. BuildRuns of the same namespace without a Build are grouped together.
+func (p *Pruner) completedBuildRuns(ctx context.Context) (map[string][]buildRun, error) {
+	groups := map[string][]buildRun{}
+	options := []client.ListOption{client.Limit(pageSize)}
+	for {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(buildRunKind.GroupVersion().WithKind(buildRunKind.Kind + "List"))
+		if err := p.APIReader.List(ctx, list, options...); err != nil {
+			if common.IgnoreMissing(err) == nil {
+				return groups, nil
+			}
+			return nil, err
+		}
+		for i := range list.Items {
+			run, ok := completedBuildRun(&list.Items[i])
+			if !ok {
+				continue
+			}
+			key := run.namespace + "/" + run.build
+			groups[key] = append(groups[key], run)
+		}
+		if list.GetContinue() == "" {
+			return groups, nil
+		}
+		options = []client.ListOption{client.Limit(pageSize), client.Continue(list.GetContinue())}
+	}
+}
+
+// completedBuildRun returns the BuildRun if it succeeded or failed
+func completedBuildRun(object *unstructured.Unstructured) (buildRun, bool) {
+	run := buildRun{
+		name:      object.GetName(),
+		namespace: object.GetNamespace(),
+		build:     object.GetLabels()[common.BuildRunBuildNameLabel],
+	}
+	if run.build == "" {
+		run.build, _, _ = unstructured.NestedString(object.Object, "spec", "build", "name")
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(object.Object, "status", "conditions")
+	status, transitioned := "", ""
+	for _, condition := range conditions {
+		condition, ok := condition.(map[string]interface{})
+		if ok && condition["type"] == "Succeeded" {
+			status, _ = condition["status"].(string)
+			transitioned, _ = condition["lastTransitionTime"].(string)
+		}
+	}
+	if status != string(corev1.ConditionTrue) && status != string(corev1.ConditionFalse) {
+		return run, false
+	}
+	run.succeeded = status == string(corev1.ConditionTrue)
+
+	completed, _, _ := unstructured.NestedString(object.Object, "status", "completionTime")
+	if completed == "" {
+		completed = transitioned
+	}
+	completionTime, err := time.Parse(time.RFC3339, completed)
+	if err != nil {
+		completionTime = object.GetCreationTimestamp().Time
+	}
+	run.completed = completionTime
+	return run, true
+}
+
+// namespaceRetention returns the retention of the namespace, overridden by its annotations, or nil
+// if the BuildRuns of the namespace are not pruned. Setting keep in a namespace replaces the
+// succeeded and failed limits of the cluster, and the other way around.
+func namespaceRetention(clusterRetention retention, namespace *corev1.Namespace) (*retention, error) {
+	annotations := namespace.Annotations
+	if skip, _ := strconv.ParseBool(annotations[common.PruneSkipAnnotation]); skip {
+		return nil, nil
+	}
+
+	result := clusterRetention
+	if value, ok := annotations[common.PruneMaxAgeAnnotation]; ok {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", common.PruneMaxAgeAnnotation, err)
+		}
+		result.maxAge = maxAge
+	}
+
+	limits := map[string]**int64{
+		common.PruneKeepAnnotation:          &result.keep,
+		common.PruneKeepSucceededAnnotation: &result.keepSucceeded,
+		common.PruneKeepFailedAnnotation:    &result.keepFailed,
+	}
+	overridden := map[string]bool{}
+	for annotation, limit := range limits {
+		value, ok := annotations[annotation]
+		if !ok {
+			continue
+		}
+		keep, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || keep < 0 {
+			return nil, fmt.Errorf("%s: %q is not a non-negative number", annotation, value)
+		}
+		*limit = &keep
+		overridden[annotation] = true
+	}
+	switch {
+	case overridden[common.PruneKeepAnnotation] && (overridden[common.PruneKeepSucceededAnnotation] || overridden[common.PruneKeepFailedAnnotation]):
+		return nil, fmt.Errorf("%s cannot be combined with the succeeded and failed limits", common.PruneKeepAnnotation)
+	case overridden[common.PruneKeepAnnotation]:
+		result.keepSucceeded, result.keepFailed = nil, nil
+	case overridden[common.PruneKeepSucceededAnnotation] || overridden[common.PruneKeepFailedAnnotation]:
+		result.keep = nil
+	}
+	return &result, nil
+}
+
+// pruner returns the pruner configuration of the OpenShiftBuild, or nil
+func pruner(owner *openshiftv1alpha1.OpenShiftBuild) *openshiftv1alpha1.BuildRunPruner {
+	if owner.Spec.Shipwright == nil || owner.Spec.Shipwright.Build == nil {
+		return nil
+	}
+	return owner.Spec.Shipwright.Build.Pruner
+}
+
+// toInt64 converts an optional limit
+func toInt64(value *int32) *int64 {
+	if value == nil {
+		return nil
+	}
+	result := int64(*value)
+	return &result
+}
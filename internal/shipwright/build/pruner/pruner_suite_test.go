@@ -0,0 +1,33 @@
+package pruner_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var scheme *runtime.Scheme
+
+func TestPruner(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "BuildRun Pruner Suite")
+}
+
+var _ = BeforeSuite(func() {
+	scheme = runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+	// register the BuildRuns as unstructured objects
+	gvk := schema.GroupVersionKind{Group: "shipwright.io", Version: "v1beta1", Kind: "BuildRun"}
+	scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+	gvk.Kind += "List"
+	scheme.AddKnownTypeWithName(gvk, &unstructured.UnstructuredList{})
+})
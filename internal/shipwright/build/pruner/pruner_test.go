@@ -0,0 +1,202 @@
+package pruner_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/pruner"
+)
+
+var _ = Describe("Pruner", Label("shipwright", "pruner"), func() {
+	var (
+		ctx        context.Context
+		now        time.Time
+		fakeClient client.Client
+		owner      *openshiftv1alpha1.OpenShiftBuild
+		buildRuns  *pruner.Pruner
+	)
+
+	namespace := func(name string, annotations map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+	}
+
+	// buildRun returns a BuildRun of the build which completed the given time ago, or is running
+	// when status is empty
+	buildRun := func(namespace, name, build, status string, age time.Duration) client.Object {
+		object := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "shipwright.io/v1beta1",
+			"kind":       "BuildRun",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+				"labels":    map[string]interface{}{common.BuildRunBuildNameLabel: build},
+			},
+			"spec": map[string]interface{}{"build": map[string]interface{}{"name": build}},
+		}}
+		if status != "" {
+			object.Object["status"] = map[string]interface{}{
+				"completionTime": now.Add(-age).Format(time.RFC3339),
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Succeeded", "status": status},
+				},
+			}
+		}
+		return object
+	}
+
+	remaining := func(namespace string) []string {
+		list := &unstructured.UnstructuredList{}
+		list.SetAPIVersion("shipwright.io/v1beta1")
+		list.SetKind("BuildRunList")
+		Expect(fakeClient.List(ctx, list, client.InNamespace(namespace))).To(Succeed())
+		names := []string{}
+		for _, item := range list.Items {
+			names = append(names, item.GetName())
+		}
+		return names
+	}
+
+	setup := func(objects ...client.Object) {
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		buildRuns = pruner.New(fakeClient, fakeClient)
+		buildRuns.Now = func() time.Time { return now }
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+		owner = &openshiftv1alpha1.OpenShiftBuild{
+			ObjectMeta: metav1.ObjectMeta{Name: common.OpenShiftBuildResourceName},
+			Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+				Shipwright: &openshiftv1alpha1.Shipwright{
+					Build: &openshiftv1alpha1.ShipwrightBuild{
+						State: openshiftv1alpha1.Enabled,
+						Pruner: &openshiftv1alpha1.BuildRunPruner{
+							State:  openshiftv1alpha1.Enabled,
+							MaxAge: &metav1.Duration{Duration: 7 * 24 * time.Hour},
+							Keep:   ptr.To[int32](2),
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("should only be enabled by the OpenShiftBuild", func() {
+		Expect(pruner.IsEnabled(owner)).To(BeTrue())
+		Expect(pruner.Interval(owner)).To(Equal(pruner.DefaultInterval))
+		owner.Spec.Shipwright.Build.Pruner.Interval = &metav1.Duration{Duration: 10 * time.Minute}
+		Expect(pruner.Interval(owner)).To(Equal(10 * time.Minute))
+
+		owner.Spec.Shipwright.Build.Pruner.State = openshiftv1alpha1.Disabled
+		Expect(pruner.IsEnabled(owner)).To(BeFalse())
+		owner.Spec.Shipwright.Build.Pruner = nil
+		Expect(pruner.IsEnabled(owner)).To(BeFalse())
+	})
+
+	It("should delete the BuildRuns exceeding the cluster retention of each Build", func() {
+		setup(
+			namespace("team-a", nil),
+			buildRun("team-a", "app-1", "app", "True", time.Hour),
+			buildRun("team-a", "app-2", "app", "False", 2*time.Hour),
+			buildRun("team-a", "app-3", "app", "True", 3*time.Hour),
+			buildRun("team-a", "app-4", "app", "", 0),
+			buildRun("team-a", "lib-1", "lib", "True", 8*24*time.Hour),
+			buildRun("team-a", "lib-2", "lib", "False", time.Hour),
+		)
+
+		deleted, err := buildRuns.Prune(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(deleted).To(Equal(2))
+		Expect(remaining("team-a")).To(ConsistOf("app-1", "app-2", "app-4", "lib-2"))
+	})
+
+	It("should apply the retention set by the namespace annotations", func() {
+		setup(
+			namespace("team-a", map[string]string{
+				common.PruneKeepSucceededAnnotation: "1",
+				common.PruneKeepFailedAnnotation:    "0",
+			}),
+			namespace("team-b", map[string]string{common.PruneSkipAnnotation: "true"}),
+			namespace("team-c", map[string]string{common.PruneMaxAgeAnnotation: "one week"}),
+			buildRun("team-a", "app-1", "app", "True", time.Hour),
+			buildRun("team-a", "app-2", "app", "True", 2*time.Hour),
+			buildRun("team-a", "app-3", "app", "False", 3*time.Hour),
+			buildRun("team-b", "app-1", "app", "True", 30*24*time.Hour),
+			buildRun("team-c", "app-1", "app", "True", 30*24*time.Hour),
+		)
+
+		deleted, err := buildRuns.Prune(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(deleted).To(Equal(2))
+		Expect(remaining("team-a")).To(ConsistOf("app-1"))
+		Expect(remaining("team-b")).To(ConsistOf("app-1"))
+		Expect(remaining("team-c")).To(ConsistOf("app-1"))
+	})
+
+	It("should only count the BuildRuns actually deleted", func() {
+		setup(
+			namespace("team-d", nil),
+			buildRun("team-d", "app-1", "app", "True", 8*24*time.Hour),
+			buildRun("team-d", "app-2", "app", "True", 9*24*time.Hour),
+		)
+		buildRuns.Client = interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+			Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+				if obj.GetName() == "app-2" {
+					return apierrors.NewForbidden(schema.GroupResource{Group: "shipwright.io", Resource: "buildruns"}, obj.GetName(), nil)
+				}
+				return c.Delete(ctx, obj, opts...)
+			},
+		})
+
+		deleted, err := buildRuns.Prune(ctx, owner)
+		Expect(err).Should(HaveOccurred())
+		Expect(deleted).To(Equal(1))
+		Expect(remaining("team-d")).To(ConsistOf("app-2"))
+
+		families, err := metrics.Registry.Gather()
+		Expect(err).ShouldNot(HaveOccurred())
+		pruned := 0.0
+		for _, family := range families {
+			if family.GetName() != "openshift_builds_pruned_buildruns_total" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "namespace" && label.GetValue() == "team-d" {
+						pruned += metric.GetCounter().GetValue()
+					}
+				}
+			}
+		}
+		Expect(pruned).To(Equal(1.0))
+	})
+
+	It("should not delete BuildRuns when disabled", func() {
+		owner.Spec.Shipwright.Build.Pruner.State = openshiftv1alpha1.Disabled
+		setup(
+			namespace("team-a", nil),
+			buildRun("team-a", "app-1", "app", "True", 30*24*time.Hour),
+		)
+
+		deleted, err := buildRuns.Prune(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(deleted).To(BeZero())
+		Expect(remaining("team-a")).To(ConsistOf("app-1"))
+	})
+})
@@ -0,0 +1,24 @@
+package queue
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// queuedBuildRuns is the number of queued BuildRuns by namespace and priority tier
+	queuedBuildRuns = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_builds_queued_buildruns",
+		Help: "Number of BuildRuns waiting for the concurrency limits",
+	}, []string{"namespace", "tier"})
+
+	// releasedBuildRuns counts the released BuildRuns by priority tier
+	releasedBuildRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "openshift_builds_released_buildruns_total",
+		Help: "Number of queued BuildRuns released within the concurrency limits",
+	}, []string{"tier"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(queuedBuildRuns, releasedBuildRuns)
+}
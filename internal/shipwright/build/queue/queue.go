@@ -0,0 +1,322 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// defaultTier is the name of the implicit tier of the namespaces matching no priority tier
+const defaultTier = "default"
+
+// buildRunKind is the kind of the BuildRuns the Events are recorded on
+var buildRunKind = schema.GroupVersionKind{Group: "shipwright.io", Version: "v1beta1", Kind: "BuildRun"}
+
+// Queue type defines methods to release the pods of the queued BuildRuns within the concurrency
+// limits of the OpenShiftBuild
+type Queue struct {
+	Client client.Client
+	// APIReader reads the BuildRuns of the queued pods, which are not cached
+	APIReader client.Reader
+	// Recorder records the Events of the queued and released BuildRuns, when set
+	Recorder record.EventRecorder
+
+	mu sync.Mutex
+	// released holds the pods released by the previous passes which the cache still sees as queued,
+	// so that they are counted as running rather than released again
+	released map[types.UID]bool
+	// queued holds the BuildRuns of the queued pods which were reported as queued
+	queued map[types.UID]*metav1.PartialObjectMetadata
+}
+
+// New creates new instance of Queue type
+func New(client client.Client, apiReader client.Reader) *Queue {
+	return &Queue{
+		Client:    client,
+		APIReader: apiReader,
+		released:  map[types.UID]bool{},
+		queued:    map[types.UID]*metav1.PartialObjectMetadata{},
+	}
+}
+
+// namespaceQueue holds the build pods of a namespace
+type namespaceQueue struct {
+	name    string
+	tier    int
+	limit   int
+	running int
+	queued  []*corev1.Pod
+}
+
+// IsEnabled returns true if the OpenShiftBuild limits the number of running BuildRuns
+func IsEnabled(owner *openshiftv1alpha1.OpenShiftBuild) bool {
+	return concurrency(owner) != nil && concurrency(owner).State == openshiftv1alpha1.Enabled
+}
+
+// IsBuildPod returns true if the pod runs a BuildRun
+func IsBuildPod(pod client.Object) bool {
+	return pod.GetLabels()[common.BuildRunNameLabel] != ""
+}
+
+// IsQueued returns true if the pod is held by the scheduling gate of the operator
+func IsQueued(pod *corev1.Pod) bool {
+	return slices.ContainsFunc(pod.Spec.SchedulingGates, func(gate corev1.PodSchedulingGate) bool {
+		return gate.Name == common.BuildRunSchedulingGate
+	})
+}
+
+// Gate adds the scheduling gate of the operator to the pod, which is not scheduled until the gate
+// is removed
+func Gate(pod *corev1.Pod) {
+	if !IsQueued(pod) {
+		pod.Spec.SchedulingGates = append(pod.Spec.SchedulingGates, corev1.PodSchedulingGate{Name: common.BuildRunSchedulingGate})
+	}
+}
+
+// Release removes the scheduling gate of the queued build pods fitting in the concurrency limits,
+// or of every queued build pod when the limits are disabled. Pods are released by priority tier,
+// then to the namespace of the tier with the fewest running BuildRuns, oldest first. Build pods
+// which were never gated count as running. Pods are read from the cache of the manager, which only
+// holds the pods of BuildRuns. A Queued Event is recorded once on the BuildRuns left in the queue,
+// and a Released Event when they are released. Returns the number of released pods.
+func (q *Queue) Release(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pods := &corev1.PodList{}
+	if err := q.Client.List(ctx, pods, client.HasLabels{common.BuildRunNameLabel}); err != nil {
+		return 0, err
+	}
+
+	queues, err := q.namespaceQueues(ctx, owner)
+	if err != nil {
+		return 0, err
+	}
+	running := 0
+	released := map[types.UID]bool{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		queue, ok := queues[pod.Namespace]
+		if !ok {
+			queue = newNamespaceQueue(owner, pod.Namespace)
+			queues[pod.Namespace] = queue
+		}
+		switch {
+		case IsQueued(pod) && q.released[pod.UID]:
+			released[pod.UID] = true
+			queue.running++
+			running++
+		case IsQueued(pod):
+			queue.queued = append(queue.queued, pod)
+		case isActive(pod):
+			queue.running++
+			running++
+		}
+	}
+	q.released = released
+
+	maxRunning := math.MaxInt
+	if config := concurrency(owner); IsEnabled(owner) && config.MaxRunning != nil {
+		maxRunning = int(*config.MaxRunning)
+	}
+	candidates := []*namespaceQueue{}
+	for _, queue := range queues {
+		sort.Slice(queue.queued, func(i, j int) bool {
+			return olderThan(queue.queued[i], queue.queued[j])
+		})
+		candidates = append(candidates, queue)
+	}
+
+	count := 0
+	var errs []error
+	for running < maxRunning {
+		queue := next(candidates)
+		if queue == nil {
+			break
+		}
+		pod := queue.queued[0]
+		queue.queued = queue.queued[1:]
+		if err := q.ungate(ctx, pod); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		q.released[pod.UID] = true
+		queue.running++
+		running++
+		count++
+		releasedBuildRuns.WithLabelValues(tierName(owner, queue.tier)).Inc()
+		if buildRun := q.queued[pod.UID]; buildRun != nil && q.Recorder != nil {
+			q.Recorder.Eventf(buildRun, corev1.EventTypeNormal, "Released",
+				"Released from the BuildRun queue after %s", time.Since(pod.CreationTimestamp.Time).Round(time.Second))
+		}
+	}
+
+	queuedBuildRuns.Reset()
+	queued := map[types.UID]*metav1.PartialObjectMetadata{}
+	for _, queue := range queues {
+		if len(queue.queued) > 0 {
+			queuedBuildRuns.WithLabelValues(queue.name, tierName(owner, queue.tier)).Set(float64(len(queue.queued)))
+		}
+		for _, pod := range queue.queued {
+			buildRun, err := q.reportQueued(ctx, pod, queue.running, running)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			queued[pod.UID] = buildRun
+		}
+	}
+	q.queued = queued
+	return count, errors.Join(errs...)
+}
+
+// reportQueued records a Queued Event on the BuildRun of the pod the first time it is left in the
+// queue. Returns the BuildRun, or nil if it was not found.
+func (q *Queue) reportQueued(ctx context.Context, pod *corev1.Pod, namespaceRunning, running int) (*metav1.PartialObjectMetadata, error) {
+	if buildRun, ok := q.queued[pod.UID]; ok || q.Recorder == nil {
+		return buildRun, nil
+	}
+	buildRun := &metav1.PartialObjectMetadata{}
+	buildRun.SetGroupVersionKind(buildRunKind)
+	key := client.ObjectKey{Namespace: pod.Namespace, Name: pod.Labels[common.BuildRunNameLabel]}
+	if err := q.APIReader.Get(ctx, key, buildRun); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	buildRun.SetGroupVersionKind(buildRunKind)
+	q.Recorder.Eventf(buildRun, corev1.EventTypeNormal, "Queued",
+		"Queued by the BuildRun concurrency limits, %d BuildRuns are running in the namespace and %d in the cluster",
+		namespaceRunning, running)
+	return buildRun, nil
+}
+
+// namespaceQueues returns the priority tier and the limit of every namespace
+func (q *Queue) namespaceQueues(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) (map[string]*namespaceQueue, error) {
+	logger := log.FromContext(ctx)
+	config := concurrency(owner)
+	queues := map[string]*namespaceQueue{}
+	if !IsEnabled(owner) {
+		return queues, nil
+	}
+
+	selectors := make([]labels.Selector, len(config.PriorityTiers))
+	for i := range config.PriorityTiers {
+		selector, err := metav1.LabelSelectorAsSelector(&config.PriorityTiers[i].NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("priority tier %s: %w", config.PriorityTiers[i].Name, err)
+		}
+		selectors[i] = selector
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := q.Client.List(ctx, namespaces); err != nil {
+		return nil, err
+	}
+	for i := range namespaces.Items {
+		namespace := &namespaces.Items[i]
+		queue := newNamespaceQueue(owner, namespace.Name)
+		for tier, selector := range selectors {
+			if selector.Matches(labels.Set(namespace.Labels)) {
+				queue.tier = tier
+				break
+			}
+		}
+		if value, ok := namespace.Annotations[common.BuildRunMaxRunningAnnotation]; ok {
+			limit, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || limit < 0 {
+				logger.Error(fmt.Errorf("%s: %q is not a non-negative number", common.BuildRunMaxRunningAnnotation, value),
+					"Invalid BuildRun concurrency limit, using the cluster limit", "namespace", namespace.Name)
+			} else {
+				queue.limit = int(limit)
+			}
+		}
+		queues[namespace.Name] = queue
+	}
+	return queues, nil
+}
+
+// newNamespaceQueue returns the queue of a namespace in the implicit priority tier, limited by the
+// cluster limit per namespace. Namespaces are unlimited when the limits are disabled.
+func newNamespaceQueue(owner *openshiftv1alpha1.OpenShiftBuild, name string) *namespaceQueue {
+	queue := &namespaceQueue{name: name, limit: math.MaxInt}
+	if config := concurrency(owner); IsEnabled(owner) {
+		queue.tier = len(config.PriorityTiers)
+		if config.MaxRunningPerNamespace != nil {
+			queue.limit = int(*config.MaxRunningPerNamespace)
+		}
+	}
+	return queue
+}
+
+// ungate removes the scheduling gate of the operator from the pod
+func (q *Queue) ungate(ctx context.Context, pod *corev1.Pod) error {
+	patch := client.MergeFrom(pod.DeepCopy())
+	pod.Spec.SchedulingGates = slices.DeleteFunc(pod.Spec.SchedulingGates, func(gate corev1.PodSchedulingGate) bool {
+		return gate.Name == common.BuildRunSchedulingGate
+	})
+	return client.IgnoreNotFound(q.Client.Patch(ctx, pod, patch))
+}
+
+// next returns the namespace whose oldest queued pod is released next, or nil if no namespace
+// may run more BuildRuns
+func next(queues []*namespaceQueue) *namespaceQueue {
+	var result *namespaceQueue
+	for _, queue := range queues {
+		if len(queue.queued) == 0 || queue.running >= queue.limit {
+			continue
+		}
+		switch {
+		case result == nil,
+			queue.tier < result.tier,
+			queue.tier == result.tier && queue.running < result.running,
+			queue.tier == result.tier && queue.running == result.running && olderThan(queue.queued[0], result.queued[0]):
+			result = queue
+		}
+	}
+	return result
+}
+
+// isActive returns true if the pod is running or waiting to be scheduled
+func isActive(pod *corev1.Pod) bool {
+	return pod.DeletionTimestamp.IsZero() && pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed
+}
+
+// olderThan orders the pods by creation, then by name
+func olderThan(pod, other *corev1.Pod) bool {
+	if !pod.CreationTimestamp.Equal(&other.CreationTimestamp) {
+		return pod.CreationTimestamp.Before(&other.CreationTimestamp)
+	}
+	return pod.Namespace+"/"+pod.Name < other.Namespace+"/"+other.Name
+}
+
+// tierName returns the name of the priority tier at the index
+func tierName(owner *openshiftv1alpha1.OpenShiftBuild, tier int) string {
+	if config := concurrency(owner); IsEnabled(owner) && tier < len(config.PriorityTiers) {
+		return config.PriorityTiers[tier].Name
+	}
+	return defaultTier
+}
+
+// concurrency returns the concurrency configuration of the OpenShiftBuild, or nil
+func concurrency(owner *openshiftv1alpha1.OpenShiftBuild) *openshiftv1alpha1.BuildRunConcurrency {
+	if owner == nil || owner.Spec.Shipwright == nil || owner.Spec.Shipwright.Build == nil {
+		return nil
+	}
+	return owner.Spec.Shipwright.Build.Concurrency
+}
@@ -0,0 +1,241 @@
+package queue_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/queue"
+)
+
+var _ = Describe("Queue", Label("shipwright", "queue"), func() {
+	var (
+		ctx        context.Context
+		created    time.Time
+		fakeClient client.Client
+		owner      *openshiftv1alpha1.OpenShiftBuild
+		buildRuns  *queue.Queue
+	)
+
+	namespace := func(name string, labels, annotations map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, Annotations: annotations}}
+	}
+
+	// buildPod returns the pod of a BuildRun, queued or running, created after the previous one
+	buildPod := func(namespace, name string, queued bool) *corev1.Pod {
+		created = created.Add(time.Second)
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				UID:               uuid.NewUUID(),
+				CreationTimestamp: metav1.NewTime(created),
+				Labels:            map[string]string{common.BuildRunNameLabel: name},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodPending},
+		}
+		if queued {
+			queue.Gate(pod)
+		} else {
+			pod.Status.Phase = corev1.PodRunning
+		}
+		return pod
+	}
+
+	queuedPods := func(count int, namespace string) []client.Object {
+		pods := []client.Object{}
+		for i := 0; i < count; i++ {
+			pods = append(pods, buildPod(namespace, fmt.Sprintf("%s-%d", namespace, i), true))
+		}
+		return pods
+	}
+
+	// released returns the names of the pods which are no longer queued
+	released := func() []string {
+		pods := &corev1.PodList{}
+		Expect(fakeClient.List(ctx, pods)).To(Succeed())
+		names := []string{}
+		for i := range pods.Items {
+			if !queue.IsQueued(&pods.Items[i]) && pods.Items[i].Status.Phase == corev1.PodPending {
+				names = append(names, pods.Items[i].Name)
+			}
+		}
+		return names
+	}
+
+	// buildRun returns the BuildRun of a build pod
+	buildRun := func(namespace, name string) *unstructured.Unstructured {
+		object := &unstructured.Unstructured{}
+		object.SetAPIVersion("shipwright.io/v1beta1")
+		object.SetKind("BuildRun")
+		object.SetNamespace(namespace)
+		object.SetName(name)
+		return object
+	}
+
+	setup := func(objects ...client.Object) {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		gvk := schema.GroupVersionKind{Group: "shipwright.io", Version: "v1beta1", Kind: "BuildRun"}
+		scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		gvk.Kind += "List"
+		scheme.AddKnownTypeWithName(gvk, &unstructured.UnstructuredList{})
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+		buildRuns = queue.New(fakeClient, fakeClient)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		created = time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+		owner = &openshiftv1alpha1.OpenShiftBuild{
+			ObjectMeta: metav1.ObjectMeta{Name: common.OpenShiftBuildResourceName},
+			Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+				Shipwright: &openshiftv1alpha1.Shipwright{
+					Build: &openshiftv1alpha1.ShipwrightBuild{
+						State: openshiftv1alpha1.Enabled,
+						Concurrency: &openshiftv1alpha1.BuildRunConcurrency{
+							State:                  openshiftv1alpha1.Enabled,
+							MaxRunning:             ptr.To[int32](4),
+							MaxRunningPerNamespace: ptr.To[int32](2),
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("should gate the pods once", func() {
+		pod := &corev1.Pod{}
+		queue.Gate(pod)
+		queue.Gate(pod)
+		Expect(queue.IsQueued(pod)).To(BeTrue())
+		Expect(pod.Spec.SchedulingGates).To(HaveLen(1))
+	})
+
+	It("should release the queued BuildRuns within the cluster and namespace limits", func() {
+		objects := []client.Object{
+			namespace("team-a", nil, nil),
+			namespace("team-b", nil, nil),
+			buildPod("team-a", "running", false),
+		}
+		objects = append(objects, queuedPods(3, "team-a")...)
+		objects = append(objects, queuedPods(3, "team-b")...)
+		setup(objects...)
+
+		count, err := buildRuns.Release(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(count).To(Equal(3))
+		Expect(released()).To(ConsistOf("team-a-0", "team-b-0", "team-b-1"))
+
+		count, err = buildRuns.Release(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(count).To(BeZero())
+	})
+
+	It("should count the pods released by a previous pass as running while the cache is stale", func() {
+		objects := append(queuedPods(3, "team-a"), namespace("team-a", nil, nil))
+		setup(objects...)
+		stale := &corev1.PodList{}
+		Expect(fakeClient.List(ctx, stale)).To(Succeed())
+		buildRuns.Client = interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if pods, ok := list.(*corev1.PodList); ok {
+					stale.DeepCopyInto(pods)
+					return nil
+				}
+				return c.List(ctx, list, opts...)
+			},
+		})
+
+		count, err := buildRuns.Release(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(count).To(Equal(2))
+
+		count, err = buildRuns.Release(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(count).To(BeZero())
+		Expect(released()).To(ConsistOf("team-a-0", "team-a-1"))
+	})
+
+	It("should record the Events of the queued and released BuildRuns once", func() {
+		owner.Spec.Shipwright.Build.Concurrency.MaxRunningPerNamespace = ptr.To[int32](1)
+		objects := append(queuedPods(3, "team-a"), namespace("team-a", nil, nil))
+		for i := 0; i < 3; i++ {
+			objects = append(objects, buildRun("team-a", fmt.Sprintf("team-a-%d", i)))
+		}
+		setup(objects...)
+		recorder := record.NewFakeRecorder(10)
+		buildRuns.Recorder = recorder
+
+		count, err := buildRuns.Release(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(count).To(Equal(1))
+		Expect(recorder.Events).To(HaveLen(2))
+		for i := 0; i < 2; i++ {
+			Expect(<-recorder.Events).To(HavePrefix("Normal Queued"))
+		}
+
+		// the released pod completes and the next one is released
+		pod := &corev1.Pod{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "team-a", Name: "team-a-0"}, pod)).To(Succeed())
+		pod.Status.Phase = corev1.PodSucceeded
+		Expect(fakeClient.Status().Update(ctx, pod)).To(Succeed())
+
+		count, err = buildRuns.Release(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(count).To(Equal(1))
+		Expect(recorder.Events).To(HaveLen(1))
+		Expect(<-recorder.Events).To(HavePrefix("Normal Released"))
+	})
+
+	It("should release the BuildRuns of the higher priority tiers first", func() {
+		owner.Spec.Shipwright.Build.Concurrency.MaxRunning = ptr.To[int32](3)
+		owner.Spec.Shipwright.Build.Concurrency.PriorityTiers = []openshiftv1alpha1.BuildRunPriorityTier{{
+			Name:              "release",
+			NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"builds": "release"}},
+		}}
+		objects := []client.Object{
+			namespace("ci", nil, nil),
+			namespace("release", map[string]string{"builds": "release"}, map[string]string{common.BuildRunMaxRunningAnnotation: "5"}),
+		}
+		// the BuildRuns of the ci namespace are queued first
+		objects = append(objects, queuedPods(3, "ci")...)
+		objects = append(objects, queuedPods(2, "release")...)
+		setup(objects...)
+
+		count, err := buildRuns.Release(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(count).To(Equal(3))
+		Expect(released()).To(ConsistOf("release-0", "release-1", "ci-0"))
+	})
+
+	It("should release every queued BuildRun when the limits are disabled", func() {
+		owner.Spec.Shipwright.Build.Concurrency.State = openshiftv1alpha1.Disabled
+		setup(append(queuedPods(3, "team-a"), namespace("team-a", nil, nil))...)
+
+		count, err := buildRuns.Release(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(count).To(Equal(3))
+
+		setup(queuedPods(1, "team-a")...)
+		count, err = buildRuns.Release(ctx, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(count).To(Equal(1))
+	})
+})
@@ -0,0 +1,45 @@
+package queue
+
+import (
+	"time"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DefaultQueueTimeout is the time a BuildRun may wait in the queue when the OpenShiftBuild does
+// not set one
+const DefaultQueueTimeout = time.Hour
+
+// defaultTaskRunTimeout is the default timeout of Tekton, which applies to the BuildRuns whose
+// Build does not set one
+const defaultTaskRunTimeout = time.Hour
+
+// QueueTimeout returns the time a BuildRun may wait in the queue
+func QueueTimeout(owner *openshiftv1alpha1.OpenShiftBuild) time.Duration {
+	if config := concurrency(owner); config != nil && config.QueueTimeout != nil {
+		return config.QueueTimeout.Duration
+	}
+	return DefaultQueueTimeout
+}
+
+// ExtendTimeout adds the queue timeout of the OpenShiftBuild to the timeout of the BuildRun, since
+// the TaskRun of a queued BuildRun times out while its pod is gated. The timeout of the Build
+// applies when the BuildRun does not set one, and the default timeout of Tekton when neither does.
+// BuildRuns without timeout are left unchanged, and invalid timeouts are left to the validation of
+// Shipwright Build.
+func ExtendTimeout(buildRun *unstructured.Unstructured, buildTimeout string, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	value, _, _ := unstructured.NestedString(buildRun.Object, "spec", "timeout")
+	if value == "" {
+		value = buildTimeout
+	}
+	timeout := defaultTaskRunTimeout
+	if value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed == 0 {
+			return nil
+		}
+		timeout = parsed
+	}
+	return unstructured.SetNestedField(buildRun.Object, (timeout + QueueTimeout(owner)).String(), "spec", "timeout")
+}
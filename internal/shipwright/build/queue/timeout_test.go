@@ -0,0 +1,59 @@
+package queue_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/queue"
+)
+
+var _ = Describe("ExtendTimeout", Label("shipwright", "queue"), func() {
+	var owner *openshiftv1alpha1.OpenShiftBuild
+
+	// timeout extends the timeout of a BuildRun setting the given one, and returns the result
+	timeout := func(buildRunTimeout, buildTimeout string) string {
+		buildRun := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{},
+		}}
+		if buildRunTimeout != "" {
+			Expect(unstructured.SetNestedField(buildRun.Object, buildRunTimeout, "spec", "timeout")).To(Succeed())
+		}
+		Expect(queue.ExtendTimeout(buildRun, buildTimeout, owner)).To(Succeed())
+		result, _, _ := unstructured.NestedString(buildRun.Object, "spec", "timeout")
+		return result
+	}
+
+	BeforeEach(func() {
+		owner = &openshiftv1alpha1.OpenShiftBuild{
+			Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+				Shipwright: &openshiftv1alpha1.Shipwright{
+					Build: &openshiftv1alpha1.ShipwrightBuild{
+						Concurrency: &openshiftv1alpha1.BuildRunConcurrency{State: openshiftv1alpha1.Enabled},
+					},
+				},
+			},
+		}
+	})
+
+	It("should add the queue timeout to the timeout of the BuildRun, then of the Build", func() {
+		Expect(timeout("10m", "20m")).To(Equal("1h10m0s"))
+		Expect(timeout("", "20m")).To(Equal("1h20m0s"))
+		Expect(timeout("", "")).To(Equal("2h0m0s"))
+	})
+
+	It("should add the queue timeout of the OpenShiftBuild", func() {
+		owner.Spec.Shipwright.Build.Concurrency.QueueTimeout = &metav1.Duration{Duration: 15 * time.Minute}
+		Expect(timeout("10m", "")).To(Equal("25m0s"))
+	})
+
+	It("should not extend the BuildRuns without timeout or with an invalid one", func() {
+		Expect(timeout("0s", "")).To(Equal("0s"))
+		Expect(timeout("", "0s")).To(BeEmpty())
+		Expect(timeout("ten minutes", "")).To(Equal("ten minutes"))
+	})
+})
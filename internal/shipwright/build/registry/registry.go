@@ -0,0 +1,117 @@
+package registry
+
+import (
+	"context"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// clusterConfigName is the name of the cluster-wide configuration objects
+const clusterConfigName = "cluster"
+
+// RegistryConfig type defines methods to render the cluster image registry policy into the
+// registries.conf and policy.json files used by the build strategies
+type RegistryConfig struct {
+	Client client.Client
+}
+
+// New creates new instance of RegistryConfig type
+func New(client client.Client) *RegistryConfig {
+	return &RegistryConfig{
+		Client: client,
+	}
+}
+
+// CreateOrUpdate renders the cluster image configuration, ImageDigestMirrorSets and
+// ImageTagMirrorSets into the registries ConfigMap
+func (rc *RegistryConfig) CreateOrUpdate(ctx context.Context, owner client.Object) (controllerutil.OperationResult, error) {
+	policy, err := rc.fetchPolicy(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	policyJSON, err := RenderPolicyJSON(policy)
+	if err != nil {
+		return "", err
+	}
+
+	object := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      common.RegistriesConfigMapName,
+			Namespace: common.TargetNamespace(owner),
+		},
+	}
+	return ctrl.CreateOrUpdate(ctx, rc.Client, object, func() error {
+		object.Data = map[string]string{
+			common.RegistriesConfKey:   RenderRegistriesConf(policy),
+			common.RegistriesPolicyKey: policyJSON,
+		}
+		return ctrl.SetControllerReference(owner, object, rc.Client.Scheme())
+	})
+}
+
+// Delete deletes the registries ConfigMap
+func (rc *RegistryConfig) Delete(ctx context.Context, owner client.Object) error {
+	object := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      common.RegistriesConfigMapName,
+			Namespace: common.TargetNamespace(owner),
+		},
+	}
+	return client.IgnoreNotFound(rc.Client.Delete(ctx, object))
+}
+
+// fetchPolicy collects the registry sources and mirrors configured on the cluster. Missing
+// configuration APIs are treated as empty configuration.
+func (rc *RegistryConfig) fetchPolicy(ctx context.Context) (*Policy, error) {
+	policy := &Policy{}
+
+	image := &configv1.Image{}
+	err := rc.Client.Get(ctx, client.ObjectKey{Name: clusterConfigName}, image)
+	if common.IgnoreMissing(err) != nil {
+		return nil, err
+	}
+	if err == nil {
+		policy.RegistrySources = image.Spec.RegistrySources
+	}
+
+	digestMirrorSets := &configv1.ImageDigestMirrorSetList{}
+	err = rc.Client.List(ctx, digestMirrorSets)
+	if common.IgnoreMissing(err) != nil {
+		return nil, err
+	}
+	for _, item := range digestMirrorSets.Items {
+		for _, mirror := range item.Spec.ImageDigestMirrors {
+			policy.Mirrors = append(policy.Mirrors, Mirror{
+				Source:             mirror.Source,
+				Mirrors:            mirror.Mirrors,
+				MirrorSourcePolicy: mirror.MirrorSourcePolicy,
+				PullFrom:           PullFromDigestOnly,
+			})
+		}
+	}
+
+	tagMirrorSets := &configv1.ImageTagMirrorSetList{}
+	err = rc.Client.List(ctx, tagMirrorSets)
+	if common.IgnoreMissing(err) != nil {
+		return nil, err
+	}
+	for _, item := range tagMirrorSets.Items {
+		for _, mirror := range item.Spec.ImageTagMirrors {
+			policy.Mirrors = append(policy.Mirrors, Mirror{
+				Source:             mirror.Source,
+				Mirrors:            mirror.Mirrors,
+				MirrorSourcePolicy: mirror.MirrorSourcePolicy,
+				PullFrom:           PullFromTagOnly,
+			})
+		}
+	}
+
+	return policy, nil
+}
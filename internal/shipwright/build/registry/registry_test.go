@@ -0,0 +1,153 @@
+package registry_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/registry"
+)
+
+var _ = Describe("RegistryConfig", Label("shipwright", "registry"), func() {
+	var (
+		ctx            context.Context
+		fakeClient     client.Client
+		registryConfig *registry.RegistryConfig
+		owner          *openshiftv1alpha1.OpenShiftBuild
+	)
+
+	getConfigMap := func() (*corev1.ConfigMap, error) {
+		object := &corev1.ConfigMap{}
+		err := fakeClient.Get(ctx, client.ObjectKey{Name: common.RegistriesConfigMapName, Namespace: "openshift-builds"}, object)
+		return object, err
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		owner = &openshiftv1alpha1.OpenShiftBuild{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: common.OpenShiftBuildResourceName,
+				UID:  uuid.NewUUID(),
+			},
+		}
+		image := &configv1.Image{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec: configv1.ImageSpec{
+				RegistrySources: configv1.RegistrySources{
+					InsecureRegistries: []string{"insecure.example.com", "*.mirror.example.com"},
+					BlockedRegistries:  []string{"blocked.example.com"},
+				},
+			},
+		}
+		digestMirrorSet := &configv1.ImageDigestMirrorSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "digest"},
+			Spec: configv1.ImageDigestMirrorSetSpec{
+				ImageDigestMirrors: []configv1.ImageDigestMirrors{{
+					Source:             "registry.redhat.io/ubi8",
+					Mirrors:            []configv1.ImageMirror{"local.mirror.example.com/ubi8"},
+					MirrorSourcePolicy: configv1.NeverContactSource,
+				}},
+			},
+		}
+		tagMirrorSet := &configv1.ImageTagMirrorSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "tag"},
+			Spec: configv1.ImageTagMirrorSetSpec{
+				ImageTagMirrors: []configv1.ImageTagMirrors{{
+					Source:  "quay.io/example",
+					Mirrors: []configv1.ImageMirror{"mirror.internal/example"},
+				}},
+			},
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(image, digestMirrorSet, tagMirrorSet).Build()
+		registryConfig = registry.New(fakeClient)
+	})
+
+	It("should render the cluster image configuration into the ConfigMap", func() {
+		_, err := registryConfig.CreateOrUpdate(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		object, err := getConfigMap()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(metav1.IsControlledBy(object, owner)).To(BeTrue())
+
+		conf := object.Data[common.RegistriesConfKey]
+		Expect(conf).To(ContainSubstring("[[registry]]\n  prefix = \"blocked.example.com\"\n  location = \"blocked.example.com\"\n  blocked = true\n"))
+		Expect(conf).To(ContainSubstring("[[registry]]\n  prefix = \"*.mirror.example.com\"\n  insecure = true\n"))
+		Expect(conf).To(ContainSubstring("  prefix = \"registry.redhat.io/ubi8\"\n  location = \"registry.redhat.io/ubi8\"\n  blocked = true\n\n" +
+			"  [[registry.mirror]]\n    location = \"local.mirror.example.com/ubi8\"\n    insecure = true\n    pull-from-mirror = \"digest-only\"\n"))
+		Expect(conf).To(ContainSubstring("  [[registry.mirror]]\n    location = \"mirror.internal/example\"\n    pull-from-mirror = \"tag-only\"\n"))
+
+		policy := map[string]interface{}{}
+		Expect(json.Unmarshal([]byte(object.Data[common.RegistriesPolicyKey]), &policy)).To(Succeed())
+		Expect(policy).To(HaveKeyWithValue("default", ConsistOf(HaveKeyWithValue("type", "insecureAcceptAnything"))))
+		Expect(policy).To(HaveKeyWithValue("transports", HaveKeyWithValue("docker",
+			HaveKeyWithValue("blocked.example.com", ConsistOf(HaveKeyWithValue("type", "reject"))),
+		)))
+	})
+
+	It("should create the ConfigMap in the target namespace", func() {
+		owner.Spec.TargetNamespace = "builds-system"
+		_, err := registryConfig.CreateOrUpdate(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		object := &corev1.ConfigMap{}
+		key := client.ObjectKey{Name: common.RegistriesConfigMapName, Namespace: "builds-system"}
+		Expect(fakeClient.Get(ctx, key, object)).To(Succeed())
+	})
+
+	It("should delete the ConfigMap", func() {
+		_, err := registryConfig.CreateOrUpdate(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(registryConfig.Delete(ctx, owner)).To(Succeed())
+		_, err = getConfigMap()
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Render", Label("shipwright", "registry"), func() {
+	It("should reject registries which are not allowed", func() {
+		policy := &registry.Policy{
+			RegistrySources: configv1.RegistrySources{
+				AllowedRegistries: []string{"registry.redhat.io", "quay.io"},
+			},
+		}
+		data, err := registry.RenderPolicyJSON(policy)
+		Expect(err).ShouldNot(HaveOccurred())
+		document := map[string]interface{}{}
+		Expect(json.Unmarshal([]byte(data), &document)).To(Succeed())
+		Expect(document).To(HaveKeyWithValue("default", ConsistOf(HaveKeyWithValue("type", "reject"))))
+		Expect(document).To(HaveKeyWithValue("transports", And(
+			HaveKeyWithValue("docker", And(
+				HaveKeyWithValue("registry.redhat.io", ConsistOf(HaveKeyWithValue("type", "insecureAcceptAnything"))),
+				HaveKeyWithValue("quay.io", ConsistOf(HaveKeyWithValue("type", "insecureAcceptAnything"))),
+			)),
+			HaveKeyWithValue("containers-storage", HaveKey("")),
+		)))
+	})
+
+	It("should allow digest and tag pulls from a mirror declared by both mirror sets", func() {
+		policy := &registry.Policy{
+			Mirrors: []registry.Mirror{
+				{Source: "quay.io/example", Mirrors: []configv1.ImageMirror{"mirror.internal/example"}, PullFrom: registry.PullFromDigestOnly},
+				{Source: "quay.io/example", Mirrors: []configv1.ImageMirror{"mirror.internal/example"}, PullFrom: registry.PullFromTagOnly},
+			},
+		}
+		conf := registry.RenderRegistriesConf(policy)
+		Expect(conf).To(ContainSubstring("location = \"mirror.internal/example\"\n"))
+		Expect(conf).NotTo(ContainSubstring("pull-from-mirror"))
+	})
+
+	It("should render an empty policy without registries", func() {
+		Expect(registry.RenderRegistriesConf(&registry.Policy{})).NotTo(ContainSubstring("[[registry]]"))
+	})
+})
@@ -0,0 +1,188 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// PullFrom restricts the image references that can be pulled from a mirror
+type PullFrom string
+
+const (
+	// PullFromAll allows pulling images from the mirror by digest and by tag
+	PullFromAll PullFrom = ""
+
+	// PullFromDigestOnly allows pulling images from the mirror by digest only
+	PullFromDigestOnly PullFrom = "digest-only"
+
+	// PullFromTagOnly allows pulling images from the mirror by tag only
+	PullFromTagOnly PullFrom = "tag-only"
+)
+
+// Policy holds the cluster image registry configuration relevant for builds
+type Policy struct {
+	RegistrySources configv1.RegistrySources
+	Mirrors         []Mirror
+}
+
+// Mirror holds the mirrors of a source repository, as declared by ImageDigestMirrorSets and
+// ImageTagMirrorSets
+type Mirror struct {
+	Source             string
+	Mirrors            []configv1.ImageMirror
+	MirrorSourcePolicy configv1.MirrorSourcePolicy
+	PullFrom           PullFrom
+}
+
+// registryEntry is a [[registry]] table of registries.conf
+type registryEntry struct {
+	scope    string
+	blocked  bool
+	insecure bool
+	mirrors  []mirrorEntry
+}
+
+// mirrorEntry is a [[registry.mirror]] table of registries.conf
+type mirrorEntry struct {
+	location string
+	insecure bool
+	pullFrom PullFrom
+}
+
+// policyRequirement is a policy requirement of the containers-policy.json format
+type policyRequirement struct {
+	Type string `json:"type"`
+}
+
+// RenderRegistriesConf renders the policy in the containers-registries.conf v2 format. Search
+// registries are left to the build strategy parameters.
+func RenderRegistriesConf(policy *Policy) string {
+	entries := map[string]*registryEntry{}
+	entryFor := func(scope string) *registryEntry {
+		if _, ok := entries[scope]; !ok {
+			entries[scope] = &registryEntry{scope: scope}
+		}
+		return entries[scope]
+	}
+
+	for _, mirror := range policy.Mirrors {
+		entry := entryFor(mirror.Source)
+		if mirror.MirrorSourcePolicy == configv1.NeverContactSource {
+			entry.blocked = true
+		}
+		for _, location := range mirror.Mirrors {
+			entry.addMirror(string(location), mirror.PullFrom)
+		}
+	}
+	for _, scope := range policy.RegistrySources.BlockedRegistries {
+		entryFor(scope).blocked = true
+	}
+	for _, scope := range policy.RegistrySources.InsecureRegistries {
+		entryFor(scope).insecure = true
+	}
+
+	scopes := make([]string, 0, len(entries))
+	for scope := range entries {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+
+	builder := &strings.Builder{}
+	builder.WriteString("# Generated by the OpenShift Builds operator from the cluster image configuration.\n")
+	for _, scope := range scopes {
+		entry := entries[scope]
+		builder.WriteString("\n[[registry]]\n")
+		fmt.Fprintf(builder, "  prefix = %q\n", entry.scope)
+		// wildcard prefixes must not set a location
+		if !strings.HasPrefix(entry.scope, "*.") {
+			fmt.Fprintf(builder, "  location = %q\n", entry.scope)
+		}
+		if entry.blocked {
+			builder.WriteString("  blocked = true\n")
+		}
+		if entry.insecure {
+			builder.WriteString("  insecure = true\n")
+		}
+		for _, mirror := range entry.mirrors {
+			builder.WriteString("\n  [[registry.mirror]]\n")
+			fmt.Fprintf(builder, "    location = %q\n", mirror.location)
+			if isInsecure(mirror.location, policy.RegistrySources.InsecureRegistries) {
+				builder.WriteString("    insecure = true\n")
+			}
+			if mirror.pullFrom != PullFromAll {
+				fmt.Fprintf(builder, "    pull-from-mirror = %q\n", mirror.pullFrom)
+			}
+		}
+	}
+	return builder.String()
+}
+
+// RenderPolicyJSON renders the allowed and blocked registries in the containers-policy.json format
+func RenderPolicyJSON(policy *Policy) (string, error) {
+	accept := []policyRequirement{{Type: "insecureAcceptAnything"}}
+	reject := []policyRequirement{{Type: "reject"}}
+
+	defaultRequirement := accept
+	docker := map[string][]policyRequirement{}
+	if len(policy.RegistrySources.AllowedRegistries) > 0 {
+		defaultRequirement = reject
+		for _, scope := range policy.RegistrySources.AllowedRegistries {
+			docker[scope] = accept
+		}
+	}
+	for _, scope := range policy.RegistrySources.BlockedRegistries {
+		docker[scope] = reject
+	}
+
+	// Local transports are used by buildah to commit and push the built image
+	document := map[string]interface{}{
+		"default": defaultRequirement,
+		"transports": map[string]interface{}{
+			"docker":             docker,
+			"docker-daemon":      map[string][]policyRequirement{"": accept},
+			"containers-storage": map[string][]policyRequirement{"": accept},
+			"dir":                map[string][]policyRequirement{"": accept},
+			"oci":                map[string][]policyRequirement{"": accept},
+		},
+	}
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// addMirror adds the mirror location, allowing both digest and tag pulls when the location is
+// declared by an ImageDigestMirrorSet and an ImageTagMirrorSet
+func (entry *registryEntry) addMirror(location string, pullFrom PullFrom) {
+	for i := range entry.mirrors {
+		if entry.mirrors[i].location == location {
+			if entry.mirrors[i].pullFrom != pullFrom {
+				entry.mirrors[i].pullFrom = PullFromAll
+			}
+			return
+		}
+	}
+	entry.mirrors = append(entry.mirrors, mirrorEntry{location: location, pullFrom: pullFrom})
+}
+
+// isInsecure returns true if the location is matched by one of the insecure registry scopes
+func isInsecure(location string, insecureRegistries []string) bool {
+	host, _, _ := strings.Cut(location, "/")
+	for _, scope := range insecureRegistries {
+		if wildcard, ok := strings.CutPrefix(scope, "*"); ok {
+			if strings.HasSuffix(host, wildcard) {
+				return true
+			}
+			continue
+		}
+		if location == scope || strings.HasPrefix(location, scope+"/") {
+			return true
+		}
+	}
+	return false
+}
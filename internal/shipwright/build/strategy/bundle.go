@@ -0,0 +1,216 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// bundleStrategy is a ClusterBuildStrategy read from a strategy bundle
+type bundleStrategy struct {
+	object *unstructured.Unstructured
+	bundle string
+}
+
+// BundleNames returns the names of the strategy bundle ConfigMaps referenced by the OpenShiftBuild
+func BundleNames(owner *openshiftv1alpha1.OpenShiftBuild) []string {
+	names := []string{}
+	if owner.Spec.Shipwright == nil || owner.Spec.Shipwright.Build == nil || owner.Spec.Shipwright.Build.Strategies == nil {
+		return names
+	}
+	for _, bundle := range owner.Spec.Shipwright.Build.Strategies.Bundles {
+		names = append(names, bundle.ConfigMap)
+	}
+	return names
+}
+
+// readBundles reads the ClusterBuildStrategies of the strategy bundles referenced by the owner, in
+// the order of the bundles and of the ConfigMap keys. Bundles which cannot be read or parsed are
+// reported in the returned statuses.
+func (bs *BuildStrategy) readBundles(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) ([]bundleStrategy, []openshiftv1alpha1.StrategyStatus, error) {
+	strategies := []bundleStrategy{}
+	statuses := []openshiftv1alpha1.StrategyStatus{}
+
+	for _, name := range BundleNames(owner) {
+		configMap := &corev1.ConfigMap{}
+		key := client.ObjectKey{Namespace: common.TargetNamespace(owner), Name: name}
+		if err := bs.Client.Get(ctx, key, configMap); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, nil, err
+			}
+			statuses = append(statuses, openshiftv1alpha1.StrategyStatus{
+				Source:  name,
+				State:   openshiftv1alpha1.StrategyInvalid,
+				Message: fmt.Sprintf("ConfigMap %s not found", key),
+			})
+			continue
+		}
+
+		keys := []string{}
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			objects, err := decodeObjects(configMap.Data[key])
+			if err != nil {
+				statuses = append(statuses, openshiftv1alpha1.StrategyStatus{
+					Source:  name,
+					State:   openshiftv1alpha1.StrategyInvalid,
+					Message: fmt.Sprintf("failed to parse key %s: %v", key, err),
+				})
+				continue
+			}
+			for _, object := range objects {
+				strategies = append(strategies, bundleStrategy{object: object, bundle: name})
+			}
+		}
+	}
+	return strategies, statuses, nil
+}
+
+// decodeObjects decodes the YAML documents of data, skipping empty documents
+func decodeObjects(data string) ([]*unstructured.Unstructured, error) {
+	objects := []*unstructured.Unstructured{}
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(data), 4096)
+	for {
+		object := &unstructured.Unstructured{}
+		if err := decoder.Decode(&object.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, err
+		}
+		if len(object.Object) == 0 {
+			continue
+		}
+		objects = append(objects, object)
+	}
+}
+
+// validateStrategy checks that the object is a well-formed ClusterBuildStrategy complying with
+// the security policy
+func validateStrategy(object *unstructured.Unstructured, policy *openshiftv1alpha1.StrategyPolicy) error {
+	gvk := object.GroupVersionKind()
+	if gvk.Group != "shipwright.io" || (gvk.Version != "v1alpha1" && gvk.Version != "v1beta1") ||
+		gvk.Kind != "ClusterBuildStrategy" {
+		return fmt.Errorf("unsupported kind %s, expected shipwright.io ClusterBuildStrategy", gvk)
+	}
+	if errs := validation.IsDNS1123Subdomain(object.GetName()); len(errs) > 0 {
+		return fmt.Errorf("invalid name %q: %s", object.GetName(), strings.Join(errs, ", "))
+	}
+
+	field := common.BuildStrategyStepsField(object)
+	steps, _, err := unstructured.NestedSlice(object.Object, "spec", field)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("spec.%s must not be empty", field)
+	}
+	for i, item := range steps {
+		step, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("spec.%s[%d] must be an object", field, i)
+		}
+		if name, _, _ := unstructured.NestedString(step, "name"); name == "" {
+			return fmt.Errorf("spec.%s[%d].name must be set", field, i)
+		}
+		if image, _, _ := unstructured.NestedString(step, "image"); image == "" {
+			return fmt.Errorf("spec.%s[%d].image must be set", field, i)
+		}
+	}
+
+	if violations := PolicyViolations(object, policy); len(violations) > 0 {
+		return errors.New(strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// checkBundles returns the valid ClusterBuildStrategies of the strategy bundles with the bundle
+// label set, and the observed state of every strategy of the bundles. Strategies named after a
+// strategy of the catalog, defined more than once, or managed by another party are not applied.
+func (bs *BuildStrategy) checkBundles(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) ([]unstructured.Unstructured, []openshiftv1alpha1.StrategyStatus, error) {
+	strategies, statuses, err := bs.readBundles(ctx, owner)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	catalog := []string{}
+	for _, res := range bs.Manifest.Resources() {
+		for _, object := range withVersionedCopy(res) {
+			catalog = append(catalog, object.GetName())
+		}
+	}
+
+	valid := []unstructured.Unstructured{}
+	seen := map[string]bool{}
+	for _, strategy := range strategies {
+		name := strategy.object.GetName()
+		status := openshiftv1alpha1.StrategyStatus{
+			Name:   name,
+			Source: strategy.bundle,
+			State:  openshiftv1alpha1.StrategyInvalid,
+		}
+		if err := validateStrategy(strategy.object, Policy(owner)); err != nil {
+			status.Message = err.Error()
+		} else if seen[name] {
+			status.Message = "ClusterBuildStrategy is defined more than once"
+		} else if slices.Contains(catalog, name) {
+			status.State = openshiftv1alpha1.StrategyConflict
+			status.Message = "ClusterBuildStrategy name is reserved by the catalog"
+		} else {
+			managed, err := bs.isManagedElsewhere(owner, strategy.object)
+			if err != nil {
+				return nil, nil, err
+			}
+			if managed {
+				status.State = openshiftv1alpha1.StrategyConflict
+				status.Message = "ClusterBuildStrategy is managed by another party"
+			} else {
+				status.State = openshiftv1alpha1.StrategyApplied
+				strategy.object.SetNamespace("")
+				labels := strategy.object.GetLabels()
+				if labels == nil {
+					labels = map[string]string{}
+				}
+				labels[common.StrategyBundleLabel] = strategy.bundle
+				strategy.object.SetLabels(labels)
+				valid = append(valid, *strategy.object)
+			}
+		}
+		if name != "" {
+			seen[name] = true
+		}
+		statuses = append(statuses, status)
+	}
+	return valid, statuses, nil
+}
+
+// listLabelled lists the Shipwright objects of the list kind with the label
+func (bs *BuildStrategy) listLabelled(ctx context.Context, kind, label string) (*unstructured.UnstructuredList, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "shipwright.io",
+		Version: "v1alpha1",
+		Kind:    kind,
+	})
+	if err := bs.Client.List(ctx, list, client.HasLabels{label}); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,298 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultDeprecationPeriod is how long deprecated strategies referenced by Builds are kept when
+// the removal policy is Deprecate and no deprecation period is set
+const DefaultDeprecationPeriod = 7 * 24 * time.Hour
+
+// buildsCountInterval is how often the Builds referencing each ClusterBuildStrategy are counted
+// for the OpenShiftBuild status. Builds are always listed again before a strategy is retired.
+const buildsCountInterval = 10 * time.Minute
+
+// buildsPageSize is the number of Builds read per List request
+const buildsPageSize = 500
+
+// Remove deletes the ClusterBuildStrategies controlled by the owner, keeping the ones referenced
+// by Builds according to the removal policy. The observed state of the kept strategies is
+// recorded in the OpenShiftBuild status.
+func (bs *BuildStrategy) Remove(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	owner.Status.Strategies = nil
+	installed, err := bs.isInstalled(ctx)
+	if err != nil || !installed {
+		return err
+	}
+	statuses, err := bs.retire(ctx, owner, nil)
+	if err != nil {
+		return err
+	}
+	copies, err := bs.replicate(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if statuses = append(statuses, copies...); len(statuses) > 0 {
+		owner.Status.Strategies = statuses
+	}
+	return nil
+}
+
+// retire deletes the ClusterBuildStrategies controlled by the owner whose name is not in keep,
+// which covers strategies toggled off, removed from a bundle, or dropped from the catalog by an
+// upgrade. Strategies referenced by Builds are kept according to the removal policy and are
+// reported in the returned statuses. The Builds are only listed when a strategy is retired.
+func (bs *BuildStrategy) retire(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, keep []string) ([]openshiftv1alpha1.StrategyStatus, error) {
+	strategies, err := bs.listControlled(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	retiring := []*unstructured.Unstructured{}
+	for i := range strategies {
+		object := &strategies[i]
+		if slices.Contains(keep, object.GetName()) {
+			if err := bs.undeprecate(ctx, object); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		retiring = append(retiring, object)
+	}
+	if len(retiring) == 0 {
+		return nil, nil
+	}
+
+	builds, err := bs.buildsByStrategy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := []openshiftv1alpha1.StrategyStatus{}
+	for _, object := range retiring {
+		status, err := bs.retireStrategy(ctx, owner, object, builds[client.ObjectKey{Name: object.GetName()}])
+		if err != nil {
+			return nil, err
+		}
+		if status != nil {
+			statuses = append(statuses, *status)
+		}
+	}
+	return statuses, nil
+}
+
+// retireStrategy deletes the strategy, a ClusterBuildStrategy or a namespaced copy, unless it is
+// referenced by Builds. A referenced strategy is marked with the deprecation annotation, the
+// Builds are warned with an Event, and the strategy is only deleted at the end of the deprecation
+// period when the removal policy is Deprecate. Returns the status of the strategy when it is kept.
+func (bs *BuildStrategy) retireStrategy(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, object *unstructured.Unstructured, builds []unstructured.Unstructured) (*openshiftv1alpha1.StrategyStatus, error) {
+	logger := bs.Logger.WithValues("namespace", object.GetNamespace(), "name", object.GetName())
+	kind := object.GetKind()
+	if len(builds) == 0 {
+		logger.Info("Deleting build strategy")
+		if err := bs.Client.Delete(ctx, object); err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	since, err := bs.deprecate(ctx, object)
+	if err != nil {
+		return nil, err
+	}
+	source := object.GetLabels()[common.StrategyBundleLabel]
+	if source == "" {
+		source = common.StrategyCatalogSource
+	}
+	status := &openshiftv1alpha1.StrategyStatus{
+		Name:      object.GetName(),
+		Namespace: object.GetNamespace(),
+		Source:    source,
+		State:     openshiftv1alpha1.StrategyDeprecated,
+		Version:   object.GetLabels()[common.StrategyVersionLabel],
+		Builds:    int32(len(builds)),
+	}
+
+	reason := "StrategyRemovalBlocked"
+	message := fmt.Sprintf("%s %s is deprecated and kept until no Build references it. "+
+		"Migrate this Build to another strategy.", kind, object.GetName())
+	status.Message = fmt.Sprintf("%s is referenced by %d Builds and is not removed", kind, len(builds))
+	if removalPolicy(owner) == openshiftv1alpha1.Deprecate {
+		removal := since.Add(deprecationPeriod(owner))
+		if !time.Now().Before(removal) {
+			logger.Info("Deleting build strategy at the end of its deprecation period", "builds", len(builds))
+			bs.recordEvents(builds, "StrategyRemoved", fmt.Sprintf(
+				"%s %s was removed at the end of its deprecation period", kind, object.GetName()))
+			if err := bs.Client.Delete(ctx, object); err != nil && !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			return nil, nil
+		}
+		reason = "StrategyDeprecated"
+		message = fmt.Sprintf("%s %s is deprecated and will be removed after %s. "+
+			"Migrate this Build to another strategy.", kind, object.GetName(), removal.Format(time.RFC3339))
+		status.Message = fmt.Sprintf("%s is referenced by %d Builds and will be removed after %s",
+			kind, len(builds), removal.Format(time.RFC3339))
+	}
+
+	logger.Info("Keeping deprecated build strategy referenced by Builds", "builds", len(builds))
+	bs.recordEvents(builds, reason, message)
+	return status, nil
+}
+
+// deprecate sets the deprecation annotation on the strategy if it is not set yet, and returns the
+// time the strategy was deprecated
+func (bs *BuildStrategy) deprecate(ctx context.Context, object *unstructured.Unstructured) (time.Time, error) {
+	annotations := object.GetAnnotations()
+	if value, ok := annotations[common.StrategyDeprecatedAnnotation]; ok {
+		if since, err := time.Parse(time.RFC3339, value); err == nil {
+			return since, nil
+		}
+	}
+
+	since := time.Now().UTC().Truncate(time.Second)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[common.StrategyDeprecatedAnnotation] = since.Format(time.RFC3339)
+	object.SetAnnotations(annotations)
+	return since, bs.Client.Update(ctx, object)
+}
+
+// undeprecate removes the deprecation annotation from a strategy which is desired again
+func (bs *BuildStrategy) undeprecate(ctx context.Context, object *unstructured.Unstructured) error {
+	annotations := object.GetAnnotations()
+	if _, ok := annotations[common.StrategyDeprecatedAnnotation]; !ok {
+		return nil
+	}
+	delete(annotations, common.StrategyDeprecatedAnnotation)
+	object.SetAnnotations(annotations)
+	return bs.Client.Update(ctx, object)
+}
+
+// buildCounts returns the number of Builds referencing each ClusterBuildStrategy. The Builds are
+// counted at most once every buildsCountInterval, as listing the Builds of every namespace is
+// expensive on large clusters.
+func (bs *BuildStrategy) buildCounts(ctx context.Context) (map[string]int32, error) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.counts != nil && time.Since(bs.counted) < buildsCountInterval {
+		return bs.counts, nil
+	}
+	builds, err := bs.listBuilds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bs.setCounts(builds)
+	return bs.counts, nil
+}
+
+// buildsByStrategy returns the Builds of every namespace referencing a strategy, indexed by the key
+// of the ClusterBuildStrategy or namespaced BuildStrategy, and refreshes the Build counts
+func (bs *BuildStrategy) buildsByStrategy(ctx context.Context) (map[client.ObjectKey][]unstructured.Unstructured, error) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	builds, err := bs.listBuilds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bs.setCounts(builds)
+	return builds, nil
+}
+
+// setCounts records the number of Builds referencing each ClusterBuildStrategy. Callers must hold
+// the lock.
+func (bs *BuildStrategy) setCounts(builds map[client.ObjectKey][]unstructured.Unstructured) {
+	bs.counts = map[string]int32{}
+	for key, references := range builds {
+		if key.Namespace == "" {
+			bs.counts[key.Name] = int32(len(references))
+		}
+	}
+	bs.counted = time.Now()
+}
+
+// listBuilds lists the Builds of every namespace by pages, indexed by the key of the strategy they
+// reference. Builds without a strategy kind reference a BuildStrategy of their namespace. Only
+// the identity of the Builds is kept, which is enough to record Events on them.
+func (bs *BuildStrategy) listBuilds(ctx context.Context) (map[client.ObjectKey][]unstructured.Unstructured, error) {
+	builds := map[client.ObjectKey][]unstructured.Unstructured{}
+	continueToken := ""
+	for {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(schema.GroupVersionKind{
+			Group:   "shipwright.io",
+			Version: "v1alpha1",
+			Kind:    "BuildList",
+		})
+		if err := bs.Client.List(ctx, list, client.Limit(buildsPageSize), client.Continue(continueToken)); err != nil {
+			if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+				return builds, nil
+			}
+			return nil, err
+		}
+		for _, build := range list.Items {
+			kind, _, _ := unstructured.NestedString(build.Object, "spec", "strategy", "kind")
+			name, _, _ := unstructured.NestedString(build.Object, "spec", "strategy", "name")
+			key := client.ObjectKey{Name: name}
+			switch kind {
+			case "ClusterBuildStrategy":
+			case "", "BuildStrategy":
+				key.Namespace = build.GetNamespace()
+			default:
+				continue
+			}
+			reference := unstructured.Unstructured{}
+			reference.SetGroupVersionKind(build.GroupVersionKind())
+			reference.SetNamespace(build.GetNamespace())
+			reference.SetName(build.GetName())
+			reference.SetUID(build.GetUID())
+			builds[key] = append(builds[key], reference)
+		}
+		if continueToken = list.GetContinue(); continueToken == "" {
+			return builds, nil
+		}
+	}
+}
+
+// recordEvents records a warning Event on every Build
+func (bs *BuildStrategy) recordEvents(builds []unstructured.Unstructured, reason, message string) {
+	if bs.Recorder == nil {
+		return
+	}
+	for i := range builds {
+		bs.Recorder.Event(&builds[i], corev1.EventTypeWarning, reason, message)
+	}
+}
+
+// removalPolicy returns the strategy removal policy of the OpenShiftBuild
+func removalPolicy(owner *openshiftv1alpha1.OpenShiftBuild) openshiftv1alpha1.StrategyRemovalPolicy {
+	if owner.Spec.Shipwright == nil || owner.Spec.Shipwright.Build == nil || owner.Spec.Shipwright.Build.Strategies == nil {
+		return openshiftv1alpha1.Block
+	}
+	if policy := owner.Spec.Shipwright.Build.Strategies.RemovalPolicy; policy != "" {
+		return policy
+	}
+	return openshiftv1alpha1.Block
+}
+
+// deprecationPeriod returns how long deprecated strategies referenced by Builds are kept
+func deprecationPeriod(owner *openshiftv1alpha1.OpenShiftBuild) time.Duration {
+	if owner.Spec.Shipwright == nil || owner.Spec.Shipwright.Build == nil || owner.Spec.Shipwright.Build.Strategies == nil ||
+		owner.Spec.Shipwright.Build.Strategies.DeprecationPeriod == nil {
+		return DefaultDeprecationPeriod
+	}
+	return owner.Spec.Shipwright.Build.Strategies.DeprecationPeriod.Duration
+}
@@ -0,0 +1,197 @@
+package strategy
+
+import (
+	"context"
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// operatorAnnotationPrefix is the prefix of the annotations set by the operator on the strategies
+const operatorAnnotationPrefix = "operator.openshift.io/"
+
+// NamespacedStrategiesSelector returns the selector of the namespaces where the managed
+// ClusterBuildStrategies are replicated, or nil if they are not replicated
+func NamespacedStrategiesSelector(owner *openshiftv1alpha1.OpenShiftBuild) *metav1.LabelSelector {
+	if owner.Spec.Shipwright == nil || owner.Spec.Shipwright.Build == nil {
+		return nil
+	}
+	return owner.Spec.Shipwright.Build.NamespacedStrategies
+}
+
+// replicate copies the ClusterBuildStrategies controlled by the owner as BuildStrategies into the
+// namespaces matching the namespaced strategies selector, corrects copies which drifted, and
+// retires the copies which are no longer desired. BuildStrategies created by namespace admins with
+// the same name as a managed strategy are left untouched. Returns the statuses of the copies kept
+// for the Builds referencing them.
+func (bs *BuildStrategy) replicate(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) ([]openshiftv1alpha1.StrategyStatus, error) {
+	desired := map[client.ObjectKey]*unstructured.Unstructured{}
+	if selector := NamespacedStrategiesSelector(owner); selector != nil {
+		namespaces, err := common.MatchingNamespaces(ctx, bs.Client, selector)
+		if err != nil {
+			return nil, err
+		}
+		strategies, err := bs.listControlled(ctx, owner)
+		if err != nil {
+			return nil, err
+		}
+		for _, namespace := range namespaces {
+			for i := range strategies {
+				object := namespacedCopy(&strategies[i], namespace)
+				desired[client.ObjectKeyFromObject(object)] = object
+			}
+		}
+	}
+
+	for _, object := range desired {
+		if err := bs.applyCopy(ctx, owner, object); err != nil {
+			return nil, err
+		}
+	}
+	return bs.retireCopies(ctx, owner, desired)
+}
+
+// retireCopies retires the namespaced copies controlled by the owner which are not desired, like
+// the ClusterBuildStrategies. Copies referenced by Builds of their namespace are kept according to
+// the removal policy and are reported in the returned statuses.
+func (bs *BuildStrategy) retireCopies(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, desired map[client.ObjectKey]*unstructured.Unstructured) ([]openshiftv1alpha1.StrategyStatus, error) {
+	list, err := bs.listLabelled(ctx, "BuildStrategyList", common.ReplicatedFromLabel)
+	if err != nil {
+		if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	retiring := []*unstructured.Unstructured{}
+	for i := range list.Items {
+		object := &list.Items[i]
+		if _, ok := desired[client.ObjectKeyFromObject(object)]; ok || !metav1.IsControlledBy(object, owner) {
+			continue
+		}
+		retiring = append(retiring, object)
+	}
+	if len(retiring) == 0 {
+		return nil, nil
+	}
+
+	builds, err := bs.buildsByStrategy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := []openshiftv1alpha1.StrategyStatus{}
+	for _, object := range retiring {
+		status, err := bs.retireStrategy(ctx, owner, object, builds[client.ObjectKeyFromObject(object)])
+		if err != nil {
+			return nil, err
+		}
+		if status != nil {
+			statuses = append(statuses, *status)
+		}
+	}
+	return statuses, nil
+}
+
+// applyCopy creates the namespaced copy of a strategy, or restores its spec, labels and annotations
+// when they drifted from the ClusterBuildStrategy. The deprecation of a copy desired again is
+// cleared.
+func (bs *BuildStrategy) applyCopy(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, object *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(object.GroupVersionKind())
+	err := bs.Client.Get(ctx, client.ObjectKeyFromObject(object), existing)
+	if apierrors.IsNotFound(err) {
+		if err := controllerutil.SetControllerReference(owner, object, bs.Client.Scheme()); err != nil {
+			return err
+		}
+		bs.Logger.Info("Creating namespaced build strategy", "namespace", object.GetNamespace(), "name", object.GetName())
+		return bs.Client.Create(ctx, object)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !metav1.IsControlledBy(existing, owner) {
+		return nil
+	}
+	annotations := existing.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	_, drifted := annotations[common.StrategyDeprecatedAnnotation]
+	delete(annotations, common.StrategyDeprecatedAnnotation)
+	for key, value := range object.GetAnnotations() {
+		if annotations[key] != value {
+			annotations[key] = value
+			drifted = true
+		}
+	}
+	if !drifted && equality.Semantic.DeepEqual(existing.Object["spec"], object.Object["spec"]) &&
+		equality.Semantic.DeepEqual(existing.GetLabels(), object.GetLabels()) {
+		return nil
+	}
+	existing.Object["spec"] = object.Object["spec"]
+	existing.SetLabels(object.GetLabels())
+	existing.SetAnnotations(annotations)
+	bs.Logger.Info("Updating namespaced build strategy", "namespace", object.GetNamespace(), "name", object.GetName())
+	return bs.Client.Update(ctx, existing)
+}
+
+// listControlled lists the ClusterBuildStrategies controlled by the owner
+func (bs *BuildStrategy) listControlled(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "shipwright.io",
+		Version: "v1alpha1",
+		Kind:    "ClusterBuildStrategyList",
+	})
+	if err := bs.Client.List(ctx, list); err != nil {
+		return nil, err
+	}
+	strategies := []unstructured.Unstructured{}
+	for _, object := range list.Items {
+		if metav1.IsControlledBy(&object, owner) {
+			strategies = append(strategies, object)
+		}
+	}
+	return strategies, nil
+}
+
+// namespacedCopy returns the BuildStrategy replicating the ClusterBuildStrategy in the namespace
+func namespacedCopy(strategy *unstructured.Unstructured, namespace string) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": runtime.DeepCopyJSONValue(strategy.Object["spec"]),
+	}}
+	object.SetAPIVersion(strategy.GetAPIVersion())
+	object.SetKind("BuildStrategy")
+	object.SetNamespace(namespace)
+	object.SetName(strategy.GetName())
+
+	labels := map[string]string{}
+	for key, value := range strategy.GetLabels() {
+		labels[key] = value
+	}
+	labels[common.ReplicatedFromLabel] = strategy.GetName()
+	object.SetLabels(labels)
+
+	// Annotations propagated to the build pods, such as the user namespace mode, are copied. The
+	// annotations of the operator only apply to the ClusterBuildStrategy.
+	annotations := map[string]string{}
+	for key, value := range strategy.GetAnnotations() {
+		if !strings.HasPrefix(key, operatorAnnotationPrefix) {
+			annotations[key] = value
+		}
+	}
+	if len(annotations) > 0 {
+		object.SetAnnotations(annotations)
+	}
+	return object
+}
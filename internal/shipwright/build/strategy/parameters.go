@@ -0,0 +1,121 @@
+package strategy
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/manifestival/manifestival"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// strategyDefaults returns the parameter defaults set by the OpenShiftBuild, keyed by strategy name
+func strategyDefaults(owner *openshiftv1alpha1.OpenShiftBuild) map[string][]openshiftv1alpha1.ParameterDefault {
+	if owner.Spec.Shipwright == nil || owner.Spec.Shipwright.Build == nil {
+		return nil
+	}
+	return owner.Spec.Shipwright.Build.StrategyDefaults
+}
+
+// validateDefaults checks that every strategy of the parameter defaults is one of the strategies,
+// and that it declares every parameter with the type of the default value
+func validateDefaults(defaults map[string][]openshiftv1alpha1.ParameterDefault, strategies []unstructured.Unstructured) error {
+	names := []string{}
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		index := -1
+		for i := range strategies {
+			if strategies[i].GetName() == name {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return fmt.Errorf("invalid strategyDefaults: unknown strategy %s", name)
+		}
+
+		parameters, err := declaredParameters(&strategies[index])
+		if err != nil {
+			return err
+		}
+		for _, parameter := range defaults[name] {
+			kind, ok := parameters[parameter.Name]
+			if !ok {
+				return fmt.Errorf("invalid strategyDefaults: strategy %s has no parameter %s", name, parameter.Name)
+			}
+			if kind == "array" && parameter.Values == nil {
+				return fmt.Errorf("invalid strategyDefaults: parameter %s of strategy %s is an array and requires values", parameter.Name, name)
+			}
+			if kind != "array" && parameter.Value == nil {
+				return fmt.Errorf("invalid strategyDefaults: parameter %s of strategy %s is a string and requires a value", parameter.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// declaredParameters returns the type of every parameter declared by the strategy
+func declaredParameters(strategy *unstructured.Unstructured) (map[string]string, error) {
+	items, _, err := unstructured.NestedSlice(strategy.Object, "spec", "parameters")
+	if err != nil {
+		return nil, err
+	}
+	parameters := map[string]string{}
+	for _, item := range items {
+		parameter, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(parameter, "name")
+		kind, _, _ := unstructured.NestedString(parameter, "type")
+		if kind == "" {
+			kind = "string"
+		}
+		parameters[name] = kind
+	}
+	return parameters, nil
+}
+
+// injectParameterDefaults is a Manifestival transformer that sets the default values of the
+// parameters of the strategies. The pinned copies of the strategies of the catalog keep the
+// defaults they are shipped with, so that Builds referencing them give reproducible results.
+func injectParameterDefaults(defaults map[string][]openshiftv1alpha1.ParameterDefault) manifestival.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		name := u.GetName()
+		if strategy, ok := u.GetLabels()[common.StrategyLabel]; ok && strategy != name {
+			return nil
+		}
+		if len(defaults[name]) == 0 {
+			return nil
+		}
+
+		items, _, err := unstructured.NestedSlice(u.Object, "spec", "parameters")
+		if err != nil {
+			return err
+		}
+		for _, value := range defaults[name] {
+			for i, item := range items {
+				parameter, ok := item.(map[string]interface{})
+				if !ok || parameter["name"] != value.Name {
+					continue
+				}
+				if value.Values != nil {
+					list := make([]interface{}, 0, len(value.Values))
+					for _, v := range value.Values {
+						list = append(list, v)
+					}
+					parameter["defaults"] = list
+				} else if value.Value != nil {
+					parameter["default"] = *value.Value
+				}
+				items[i] = parameter
+			}
+		}
+		return unstructured.SetNestedSlice(u.Object, items, "spec", "parameters")
+	}
+}
@@ -0,0 +1,116 @@
+package strategy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DefaultAllowedCapabilities lists the Linux capabilities steps may add when the strategy policy
+// does not set them, which are the capabilities used by the strategies of the catalog
+var DefaultAllowedCapabilities = []string{"SETFCAP"}
+
+// Policy returns the strategy security policy of the OpenShiftBuild, with its defaults applied
+func Policy(owner *openshiftv1alpha1.OpenShiftBuild) *openshiftv1alpha1.StrategyPolicy {
+	policy := &openshiftv1alpha1.StrategyPolicy{}
+	if owner != nil && owner.Spec.Shipwright != nil && owner.Spec.Shipwright.Build != nil &&
+		owner.Spec.Shipwright.Build.StrategyPolicy != nil {
+		policy = owner.Spec.Shipwright.Build.StrategyPolicy.DeepCopy()
+	}
+	if policy.AllowedCapabilities == nil {
+		policy.AllowedCapabilities = DefaultAllowedCapabilities
+	}
+	return policy
+}
+
+// PolicyViolations returns the violations of the security policy by the build strategy: privileged
+// steps, capabilities which are not allowed, host path volumes, step images pulled from registries
+// which are not allowed, and steps without resource limits when they are required
+func PolicyViolations(object *unstructured.Unstructured, policy *openshiftv1alpha1.StrategyPolicy) []string {
+	violations := []string{}
+	field := common.BuildStrategyStepsField(object)
+	steps, _, _ := unstructured.NestedSlice(object.Object, "spec", field)
+	for i, item := range steps {
+		step, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := fmt.Sprintf("spec.%s[%d]", field, i)
+
+		if privileged, _, _ := unstructured.NestedBool(step, "securityContext", "privileged"); privileged {
+			violations = append(violations, path+" must not run privileged")
+		}
+		capabilities, _, _ := unstructured.NestedStringSlice(step, "securityContext", "capabilities", "add")
+		for _, capability := range capabilities {
+			if !slices.Contains(policy.AllowedCapabilities, strings.TrimPrefix(capability, "CAP_")) {
+				violations = append(violations, fmt.Sprintf("%s must not add capability %s", path, capability))
+			}
+		}
+
+		image, _, _ := unstructured.NestedString(step, "image")
+		if len(policy.AllowedRegistries) > 0 {
+			if strings.Contains(image, "$(") {
+				violations = append(violations, fmt.Sprintf("%s image %s must not be set from a parameter", path, image))
+			} else if !IsAllowedImage(image, policy.AllowedRegistries) {
+				violations = append(violations, fmt.Sprintf("%s image %s is not pulled from an allowed registry (%s)",
+					path, image, strings.Join(policy.AllowedRegistries, ", ")))
+			}
+		}
+
+		if policy.RequireResourceLimits {
+			limits, _, _ := unstructured.NestedMap(step, "resources", "limits")
+			if limits["cpu"] == nil || limits["memory"] == nil {
+				violations = append(violations, path+" must set cpu and memory limits")
+			}
+		}
+	}
+
+	volumes, _, _ := unstructured.NestedSlice(object.Object, "spec", "volumes")
+	for i, item := range volumes {
+		if volume, ok := item.(map[string]interface{}); ok && volume["hostPath"] != nil {
+			violations = append(violations, fmt.Sprintf("spec.volumes[%d] must not mount a host path", i))
+		}
+	}
+	return violations
+}
+
+// IsAllowedImage returns true if the image belongs to one of the registries, which may be followed
+// by a repository path. Images without registry belong to docker.io. Registry hosts are compared
+// case-insensitively.
+func IsAllowedImage(image string, registries []string) bool {
+	image = normalizeImage(image)
+	for _, registry := range registries {
+		registry = lowerHost(strings.TrimSuffix(registry, "/"))
+		if strings.HasPrefix(image, registry+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeImage qualifies images without registry, such as "ubuntu", with the docker.io registry,
+// and lowercases the registry host of the others
+func normalizeImage(image string) string {
+	first, rest, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || strings.EqualFold(first, "localhost")) {
+		return lowerHost(image)
+	}
+	if !found {
+		return "docker.io/library/" + first
+	}
+	return "docker.io/" + first + "/" + rest
+}
+
+// lowerHost lowercases the registry host of the image reference, since host names are
+// case-insensitive
+func lowerHost(reference string) string {
+	host, path, found := strings.Cut(reference, "/")
+	if !found {
+		return strings.ToLower(reference)
+	}
+	return strings.ToLower(host) + "/" + path
+}
@@ -0,0 +1,350 @@
+package strategy
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/manifestival/manifestival"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/userns"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BuildStrategy type defines methods to apply and delete the ClusterBuildStrategies shipped with
+// the operator
+type BuildStrategy struct {
+	Client   client.Client
+	Logger   logr.Logger
+	Manifest manifestival.Manifest
+	Recorder record.EventRecorder
+
+	// mu guards the Build counts, which are refreshed every buildsCountInterval
+	mu      sync.Mutex
+	counts  map[string]int32
+	counted time.Time
+}
+
+// New creates new instance of BuildStrategy type
+func New(client client.Client, manifest manifestival.Manifest) *BuildStrategy {
+	return &BuildStrategy{
+		Client:   client,
+		Manifest: manifest,
+	}
+}
+
+// Reconcile transforms the build strategy manifests of the catalog and of the strategy bundles
+// according to the OpenShiftBuild spec and applies them. The observed state of every strategy is
+// recorded in the OpenShiftBuild status. Returns true if the ClusterBuildStrategy API is not
+// installed yet and a requeue is required.
+func (bs *BuildStrategy) Reconcile(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) (bool, error) {
+	logger := bs.Logger.WithValues("name", owner.Name)
+
+	installed, err := bs.isInstalled(ctx)
+	if err != nil {
+		return true, err
+	}
+	if !installed {
+		logger.Info("Waiting for the ClusterBuildStrategy API to be installed")
+		return true, nil
+	}
+
+	enabled := EnabledStrategies(owner)
+	counts, err := bs.buildCounts(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	// Every strategy of the catalog is published under its floating name and as a versioned copy.
+	// Strategies managed by another party, such as a GitOps repository, are left untouched.
+	statuses := []openshiftv1alpha1.StrategyStatus{}
+	catalog := []unstructured.Unstructured{}
+	for _, res := range bs.Manifest.Filter(byNames(enabled)).Resources() {
+		for _, object := range withVersionedCopy(res) {
+			status := openshiftv1alpha1.StrategyStatus{
+				Name:    object.GetName(),
+				Source:  common.StrategyCatalogSource,
+				State:   openshiftv1alpha1.StrategyApplied,
+				Version: object.GetLabels()[common.StrategyVersionLabel],
+				Builds:  counts[object.GetName()],
+			}
+			managed, err := bs.isManagedElsewhere(owner, &object)
+			if err != nil {
+				return false, err
+			}
+			if managed {
+				status.State = openshiftv1alpha1.StrategyConflict
+				status.Message = "ClusterBuildStrategy is managed by another party"
+			} else {
+				catalog = append(catalog, object)
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	previous, err := bs.previousVersions(ctx, owner, enabled)
+	if err != nil {
+		logger.Error(err, "listing previous strategy versions")
+		return false, err
+	}
+	for _, object := range previous {
+		statuses = append(statuses, openshiftv1alpha1.StrategyStatus{
+			Name:    object.GetName(),
+			Source:  common.StrategyCatalogSource,
+			State:   openshiftv1alpha1.StrategyApplied,
+			Version: object.GetLabels()[common.StrategyVersionLabel],
+			Builds:  counts[object.GetName()],
+		})
+	}
+
+	bundled, bundleStatuses, err := bs.checkBundles(ctx, owner)
+	if err != nil {
+		logger.Error(err, "reading strategy bundles")
+		return false, err
+	}
+	statuses = append(statuses, bundleStatuses...)
+
+	if err := validateDefaults(strategyDefaults(owner), append(bs.Manifest.Resources(), bundled...)); err != nil {
+		return false, err
+	}
+
+	// Strategies disabled, removed from a bundle, or dropped from the catalog are retired
+	keep := []string{}
+	for _, object := range append(append(slices.Clone(catalog), previous...), bundled...) {
+		keep = append(keep, object.GetName())
+	}
+	retired, err := bs.retire(ctx, owner, keep)
+	if err != nil {
+		logger.Error(err, "removing strategies")
+		return false, err
+	}
+	statuses = append(statuses, retired...)
+
+	manifest, err := manifestival.ManifestFrom(manifestival.Slice(append(catalog, bundled...)),
+		manifestival.UseClient(bs.Manifest.Client))
+	if err != nil {
+		return false, err
+	}
+	manifest, err = manifest.Transform(bs.transformers(owner)...)
+	if err != nil {
+		logger.Error(err, "transforming manifest")
+		return false, err
+	}
+
+	logger.Info("Applying manifests...", "strategies", enabled, "bundles", BundleNames(owner))
+	if err := manifest.Apply(); err != nil {
+		return false, err
+	}
+
+	copies, err := bs.replicate(ctx, owner)
+	if err != nil {
+		logger.Error(err, "replicating namespaced strategies")
+		return false, err
+	}
+	owner.Status.Strategies = append(statuses, copies...)
+	return false, nil
+}
+
+// EnabledStrategies returns the names of the ClusterBuildStrategies of the catalog enabled by the
+// OpenShiftBuild. Strategies without an explicit state fall back to their default state, and the
+// buildah-userns strategy follows the user namespaces state.
+func EnabledStrategies(owner *openshiftv1alpha1.OpenShiftBuild) []string {
+	strategies := &openshiftv1alpha1.BuildStrategies{}
+	if owner.Spec.Shipwright != nil && owner.Spec.Shipwright.Build != nil && owner.Spec.Shipwright.Build.Strategies != nil {
+		strategies = owner.Spec.Shipwright.Build.Strategies
+	}
+
+	catalog := []struct {
+		name     string
+		state    openshiftv1alpha1.State
+		fallback openshiftv1alpha1.State
+	}{
+		{name: "buildah", state: strategies.Buildah, fallback: openshiftv1alpha1.Enabled},
+		{name: "source-to-image", state: strategies.SourceToImage, fallback: openshiftv1alpha1.Enabled},
+		{name: "buildah-cache", state: strategies.BuildahCache, fallback: openshiftv1alpha1.Disabled},
+		{name: "buildah-rootless", state: strategies.BuildahRootless, fallback: openshiftv1alpha1.Disabled},
+	}
+
+	enabled := []string{}
+	for _, entry := range catalog {
+		state := entry.state
+		if state == "" {
+			state = entry.fallback
+		}
+		if state == openshiftv1alpha1.Enabled {
+			enabled = append(enabled, entry.name)
+		}
+	}
+
+	// The user namespace strategy is installed with its SecurityContextConstraints
+	if userns.IsEnabled(owner) {
+		enabled = append(enabled, common.UserNamespaceStrategyName)
+	}
+	return enabled
+}
+
+// byNames is a Manifestival predicate matching the resources with one of the names
+func byNames(names []string) manifestival.Predicate {
+	return func(u *unstructured.Unstructured) bool {
+		return slices.Contains(names, u.GetName())
+	}
+}
+
+// injectLabels is a Manifestival transformer that adds the labels to every resource
+func injectLabels(labels map[string]string) manifestival.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		merged := u.GetLabels()
+		if merged == nil {
+			merged = map[string]string{}
+		}
+		for key, value := range labels {
+			merged[key] = value
+		}
+		u.SetLabels(merged)
+		return nil
+	}
+}
+
+// isManagedElsewhere returns true if the strategy exists and is not controlled by the owner
+func (bs *BuildStrategy) isManagedElsewhere(owner *openshiftv1alpha1.OpenShiftBuild, res *unstructured.Unstructured) (bool, error) {
+	object, err := bs.Manifest.Client.Get(res)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !metav1.IsControlledBy(object, owner), nil
+}
+
+// Delete removes the ClusterBuildStrategies controlled by the owner, which covers the catalog
+// and the strategy bundles, and their namespaced copies. Strategies referenced by Builds are
+// retired according to the removal policy, and the ones kept are released so that they are not
+// garbage collected with the owner.
+func (bs *BuildStrategy) Delete(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	installed, err := bs.isInstalled(ctx)
+	if err != nil || !installed {
+		return err
+	}
+	kept, err := bs.retire(ctx, owner, nil)
+	if err != nil {
+		return err
+	}
+	copies, err := bs.retireCopies(ctx, owner, nil)
+	if err != nil {
+		return err
+	}
+	for _, status := range append(kept, copies...) {
+		if err := bs.release(ctx, owner, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// release removes the owner reference of the owner from the kept ClusterBuildStrategy, or from
+// the namespaced copy when the status has a namespace
+func (bs *BuildStrategy) release(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, status openshiftv1alpha1.StrategyStatus) error {
+	kind := "ClusterBuildStrategy"
+	if status.Namespace != "" {
+		kind = "BuildStrategy"
+	}
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "shipwright.io",
+		Version: "v1alpha1",
+		Kind:    kind,
+	})
+	if err := bs.Client.Get(ctx, client.ObjectKey{Namespace: status.Namespace, Name: status.Name}, object); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	references := slices.DeleteFunc(object.GetOwnerReferences(), func(reference metav1.OwnerReference) bool {
+		return reference.UID == owner.UID
+	})
+	object.SetOwnerReferences(references)
+	bs.Logger.Info("Releasing build strategy referenced by Builds", "namespace", status.Namespace, "name", status.Name)
+	return bs.Client.Update(ctx, object)
+}
+
+// transformers returns the Manifestival transformers to apply on the build strategies
+func (bs *BuildStrategy) transformers(owner *openshiftv1alpha1.OpenShiftBuild) []manifestival.Transformer {
+	transformers := []manifestival.Transformer{
+		manifestival.InjectOwner(owner),
+		injectLabels(map[string]string{common.ManagedByLabel: common.ManagedByLabelValue}),
+		injectParameterDefaults(strategyDefaults(owner)),
+	}
+
+	// The trusted CA bundle and the registries configuration can be mounted in build pods running
+	// in any namespace only when they are distributed with SharedConfigMaps.
+	if owner.Spec.SharedResource != nil && owner.Spec.SharedResource.State == openshiftv1alpha1.Enabled {
+		transformers = append(transformers, common.InjectBuildStrategyVolume(
+			corev1.Volume{
+				Name: common.TrustedCABundleVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					CSI: &corev1.CSIVolumeSource{
+						Driver:   common.SharedResourceCSIDriverName,
+						ReadOnly: ptr.To(true),
+						VolumeAttributes: map[string]string{
+							"sharedConfigMap": common.TrustedCABundleConfigMapName,
+						},
+					},
+				},
+			},
+			corev1.VolumeMount{
+				Name:      common.TrustedCABundleVolumeName,
+				MountPath: filepath.Join(common.TrustedCABundleMountPath, common.TrustedCABundleFileName),
+				SubPath:   common.TrustedCABundleKey,
+				ReadOnly:  true,
+			},
+		))
+		transformers = append(transformers, common.InjectBuildStrategyVolume(
+			corev1.Volume{
+				Name: common.RegistriesVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					CSI: &corev1.CSIVolumeSource{
+						Driver:   common.SharedResourceCSIDriverName,
+						ReadOnly: ptr.To(true),
+						VolumeAttributes: map[string]string{
+							"sharedConfigMap": common.RegistriesConfigMapName,
+						},
+					},
+				},
+			},
+			corev1.VolumeMount{
+				Name:      common.RegistriesVolumeName,
+				MountPath: common.RegistriesMountPath,
+				ReadOnly:  true,
+			},
+			corev1.VolumeMount{
+				Name:      common.RegistriesVolumeName,
+				MountPath: common.RegistriesPolicyMountPath,
+				SubPath:   common.RegistriesPolicyKey,
+				ReadOnly:  true,
+			},
+		))
+	}
+
+	return transformers
+}
+
+// isInstalled returns true if the ClusterBuildStrategy CRD exists on the cluster
+func (bs *BuildStrategy) isInstalled(ctx context.Context) (bool, error) {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	err := bs.Client.Get(ctx, client.ObjectKey{Name: common.ClusterBuildStrategyCRDName}, crd)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,37 @@
+package strategy_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var scheme *runtime.Scheme
+
+func TestStrategy(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Strategy Suite")
+}
+
+var _ = BeforeSuite(func() {
+	scheme = runtime.NewScheme()
+	Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+	Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+	Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	// register the Shipwright builds and build strategies as unstructured objects
+	for _, kind := range []string{"ClusterBuildStrategy", "BuildStrategy", "Build"} {
+		gvk := schema.GroupVersionKind{Group: "shipwright.io", Version: "v1alpha1", Kind: kind}
+		scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		gvk.Kind += "List"
+		scheme.AddKnownTypeWithName(gvk, &unstructured.UnstructuredList{})
+	}
+})
@@ -0,0 +1,769 @@
+package strategy_test
+
+import (
+	"context"
+	"path/filepath"
+
+	manifestivalclient "github.com/manifestival/controller-runtime-client"
+	"github.com/manifestival/manifestival"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/strategy"
+)
+
+var strategyManifestPath = filepath.Join("..", "..", "..", "..", common.ShipwrightBuildStrategyManifestPath)
+
+// getStrategy fetches the named ClusterBuildStrategy
+func getStrategy(ctx context.Context, c client.Client, name string) (*unstructured.Unstructured, error) {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "shipwright.io",
+		Version: "v1alpha1",
+		Kind:    "ClusterBuildStrategy",
+	})
+	err := c.Get(ctx, client.ObjectKey{Name: name}, object)
+	return object, err
+}
+
+// stepMounts returns the names of the volumes mounted by each step of the strategy
+func stepMounts(object *unstructured.Unstructured) [][]string {
+	steps, _, err := unstructured.NestedSlice(object.Object, "spec", common.BuildStrategyStepsField(object))
+	Expect(err).ShouldNot(HaveOccurred())
+	result := [][]string{}
+	for _, step := range steps {
+		mounts, _, err := unstructured.NestedSlice(step.(map[string]interface{}), "volumeMounts")
+		Expect(err).ShouldNot(HaveOccurred())
+		names := []string{}
+		for _, mount := range mounts {
+			names = append(names, mount.(map[string]interface{})["name"].(string))
+		}
+		result = append(result, names)
+	}
+	return result
+}
+
+// bundledStrategy returns the YAML of a ClusterBuildStrategy with a single build step, with the
+// given security context
+func bundledStrategy(name, securityContext string) string {
+	return `apiVersion: shipwright.io/v1alpha1
+kind: ClusterBuildStrategy
+metadata:
+  name: ` + name + `
+spec:
+  buildSteps:
+    - name: build-and-push
+      image: gcr.io/kaniko-project/executor:latest
+      securityContext: {` + securityContext + `}
+`
+}
+
+var _ = Describe("BuildStrategy", Label("shipwright", "strategy"), func() {
+	var (
+		ctx           context.Context
+		fakeClient    client.Client
+		buildStrategy *strategy.BuildStrategy
+		owner         *openshiftv1alpha1.OpenShiftBuild
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+		manifest, err := manifestival.NewManifest(strategyManifestPath,
+			manifestival.UseClient(manifestivalclient.NewClient(fakeClient)),
+		)
+		Expect(err).ShouldNot(HaveOccurred())
+		buildStrategy = strategy.New(fakeClient, manifest)
+		owner = &openshiftv1alpha1.OpenShiftBuild{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: openshiftv1alpha1.GroupVersion.String(),
+				Kind:       "OpenShiftBuild",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: common.OpenShiftBuildResourceName,
+				UID:  uuid.NewUUID(),
+			},
+			Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+				Shipwright: &openshiftv1alpha1.Shipwright{
+					Build: &openshiftv1alpha1.ShipwrightBuild{State: openshiftv1alpha1.Enabled},
+				},
+				SharedResource: &openshiftv1alpha1.SharedResource{State: openshiftv1alpha1.Enabled},
+			},
+		}
+	})
+
+	When("the ClusterBuildStrategy API is not installed", func() {
+		It("should request a requeue without applying the strategies", func() {
+			requeue, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(requeue).To(BeTrue())
+			_, err = getStrategy(ctx, fakeClient, "buildah")
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	When("the ClusterBuildStrategy API is installed", func() {
+		BeforeEach(func() {
+			crd := &apiextensionsv1.CustomResourceDefinition{}
+			crd.SetName(common.ClusterBuildStrategyCRDName)
+			Expect(fakeClient.Create(ctx, crd)).To(Succeed())
+		})
+
+		It("should apply the strategies owned by the OpenShiftBuild", func() {
+			requeue, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(requeue).To(BeFalse())
+			for _, name := range []string{"buildah", "source-to-image"} {
+				object, err := getStrategy(ctx, fakeClient, name)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(metav1.IsControlledBy(object, owner)).To(BeTrue())
+			}
+		})
+
+		It("should not apply the opt-in strategies by default", func() {
+			_, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			for _, name := range []string{"buildah-cache", "buildah-rootless"} {
+				_, err := getStrategy(ctx, fakeClient, name)
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}
+		})
+
+		It("should apply the opt-in strategies when enabled", func() {
+			owner.Spec.Shipwright.Build.Strategies = &openshiftv1alpha1.BuildStrategies{
+				BuildahCache:    openshiftv1alpha1.Enabled,
+				BuildahRootless: openshiftv1alpha1.Enabled,
+			}
+			_, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			for _, name := range []string{"buildah", "source-to-image", "buildah-cache", "buildah-rootless"} {
+				_, err := getStrategy(ctx, fakeClient, name)
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+		})
+
+		It("should apply the user namespace strategy when user namespaces are enabled", func() {
+			owner.Spec.Shipwright.Build.UserNamespaces = &openshiftv1alpha1.UserNamespaces{State: openshiftv1alpha1.Enabled}
+			_, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			for _, name := range []string{common.UserNamespaceStrategyName, strategy.VersionedName(common.UserNamespaceStrategyName, "1.1")} {
+				object, err := getStrategy(ctx, fakeClient, name)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(object.GetAnnotations()).To(HaveKeyWithValue("openshift.io/required-scc", common.UserNamespaceSCCName))
+			}
+
+			owner.Spec.Shipwright.Build.UserNamespaces.State = openshiftv1alpha1.Disabled
+			_, err = buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = getStrategy(ctx, fakeClient, common.UserNamespaceStrategyName)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should remove the strategies once disabled", func() {
+			_, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			owner.Spec.Shipwright.Build.Strategies = &openshiftv1alpha1.BuildStrategies{
+				Buildah:       openshiftv1alpha1.Disabled,
+				SourceToImage: openshiftv1alpha1.Enabled,
+			}
+			_, err = buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = getStrategy(ctx, fakeClient, "buildah")
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			_, err = getStrategy(ctx, fakeClient, "source-to-image")
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should not remove a disabled strategy created by a user", func() {
+			object := &unstructured.Unstructured{}
+			object.SetAPIVersion("shipwright.io/v1alpha1")
+			object.SetKind("ClusterBuildStrategy")
+			object.SetName("buildah-cache")
+			Expect(fakeClient.Create(ctx, object)).To(Succeed())
+			_, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = getStrategy(ctx, fakeClient, "buildah-cache")
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should leave a catalog strategy managed by another party untouched", func() {
+			object := &unstructured.Unstructured{}
+			object.SetAPIVersion("shipwright.io/v1alpha1")
+			object.SetKind("ClusterBuildStrategy")
+			object.SetName("buildah")
+			object.SetLabels(map[string]string{"app.kubernetes.io/managed-by": "argocd"})
+			Expect(fakeClient.Create(ctx, object)).To(Succeed())
+			_, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			object, err = getStrategy(ctx, fakeClient, "buildah")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(object.GetOwnerReferences()).To(BeEmpty())
+			Expect(object.GetLabels()).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "argocd"))
+			Expect(owner.Status.Strategies).To(ContainElement(openshiftv1alpha1.StrategyStatus{
+				Name:    "buildah",
+				Source:  common.StrategyCatalogSource,
+				State:   openshiftv1alpha1.StrategyConflict,
+				Version: "1.1",
+				Message: "ClusterBuildStrategy is managed by another party",
+			}))
+		})
+
+		It("should report the applied catalog strategies", func() {
+			_, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			applied := func(name string) openshiftv1alpha1.StrategyStatus {
+				return openshiftv1alpha1.StrategyStatus{
+					Name:    name,
+					Source:  common.StrategyCatalogSource,
+					State:   openshiftv1alpha1.StrategyApplied,
+					Version: "1.1",
+				}
+			}
+			Expect(owner.Status.Strategies).To(ConsistOf(
+				applied("buildah"), applied("buildah-1-1"), applied("source-to-image"), applied("source-to-image-1-1"),
+			))
+		})
+
+		It("should publish a copy pinned to the version of each strategy", func() {
+			_, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			object, err := getStrategy(ctx, fakeClient, "buildah-1-1")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(metav1.IsControlledBy(object, owner)).To(BeTrue())
+			Expect(object.GetLabels()).To(HaveKeyWithValue(common.StrategyLabel, "buildah"))
+			Expect(object.GetLabels()).To(HaveKeyWithValue(common.StrategyVersionLabel, "1.1"))
+		})
+
+		It("should retain the previous versions after an upgrade", func() {
+			for _, version := range []string{"1.0", "0.9", "0.10"} {
+				object := &unstructured.Unstructured{}
+				object.SetAPIVersion("shipwright.io/v1alpha1")
+				object.SetKind("ClusterBuildStrategy")
+				object.SetName(strategy.VersionedName("buildah", version))
+				object.SetLabels(map[string]string{
+					common.StrategyLabel:        "buildah",
+					common.StrategyVersionLabel: version,
+				})
+				Expect(controllerutil.SetControllerReference(owner, object, scheme)).To(Succeed())
+				Expect(fakeClient.Create(ctx, object)).To(Succeed())
+			}
+			_, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			for _, name := range []string{"buildah-1-1", "buildah-1-0", "buildah-0-10"} {
+				_, err := getStrategy(ctx, fakeClient, name)
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+			_, err = getStrategy(ctx, fakeClient, "buildah-0-9")
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			Expect(owner.Status.Strategies).To(ContainElement(openshiftv1alpha1.StrategyStatus{
+				Name:    "buildah-1-0",
+				Source:  common.StrategyCatalogSource,
+				State:   openshiftv1alpha1.StrategyApplied,
+				Version: "1.0",
+			}))
+		})
+
+		When("strategy defaults are set", func() {
+			// parameter returns the named parameter of the strategy
+			parameter := func(object *unstructured.Unstructured, name string) map[string]interface{} {
+				parameters, _, err := unstructured.NestedSlice(object.Object, "spec", "parameters")
+				Expect(err).ShouldNot(HaveOccurred())
+				for _, item := range parameters {
+					if item.(map[string]interface{})["name"] == name {
+						return item.(map[string]interface{})
+					}
+				}
+				return nil
+			}
+
+			It("should render the defaults into the strategy parameters", func() {
+				owner.Spec.Shipwright.Build.StrategyDefaults = map[string][]openshiftv1alpha1.ParameterDefault{
+					"buildah": {
+						{Name: "storage-driver", Value: ptr.To("overlay")},
+						{Name: "registries-search", Values: []string{"registry.example.com"}},
+					},
+				}
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				object, err := getStrategy(ctx, fakeClient, "buildah")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(parameter(object, "storage-driver")).To(HaveKeyWithValue("default", "overlay"))
+				Expect(parameter(object, "registries-search")).To(HaveKeyWithValue("defaults", []interface{}{"registry.example.com"}))
+				object, err = getStrategy(ctx, fakeClient, "source-to-image")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(parameter(object, "storage-driver")).To(HaveKeyWithValue("default", "vfs"))
+			})
+
+			It("should keep the shipped defaults of the pinned copies", func() {
+				owner.Spec.Shipwright.Build.StrategyDefaults = map[string][]openshiftv1alpha1.ParameterDefault{
+					"buildah": {{Name: "storage-driver", Value: ptr.To("overlay")}},
+				}
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				object, err := getStrategy(ctx, fakeClient, "buildah-1-1")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(parameter(object, "storage-driver")).To(HaveKeyWithValue("default", "vfs"))
+			})
+
+			It("should reject a parameter not declared by the strategy", func() {
+				owner.Spec.Shipwright.Build.StrategyDefaults = map[string][]openshiftv1alpha1.ParameterDefault{
+					"buildah": {{Name: "storage", Value: ptr.To("overlay")}},
+				}
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).To(MatchError("invalid strategyDefaults: strategy buildah has no parameter storage"))
+				_, err = getStrategy(ctx, fakeClient, "buildah")
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+
+			It("should reject a value not matching the parameter type", func() {
+				owner.Spec.Shipwright.Build.StrategyDefaults = map[string][]openshiftv1alpha1.ParameterDefault{
+					"source-to-image": {{Name: "registries-block", Value: ptr.To("docker.io")}},
+				}
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).To(MatchError(ContainSubstring("is an array and requires values")))
+			})
+
+			It("should reject an unknown strategy", func() {
+				owner.Spec.Shipwright.Build.StrategyDefaults = map[string][]openshiftv1alpha1.ParameterDefault{
+					"kaniko": {{Name: "storage-driver", Value: ptr.To("overlay")}},
+				}
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).To(MatchError("invalid strategyDefaults: unknown strategy kaniko"))
+			})
+		})
+
+		When("namespaced strategies are selected", func() {
+			var tenant *corev1.Namespace
+
+			getCopy := func(namespace, name string) (*unstructured.Unstructured, error) {
+				object := &unstructured.Unstructured{}
+				object.SetAPIVersion("shipwright.io/v1alpha1")
+				object.SetKind("BuildStrategy")
+				err := fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, object)
+				return object, err
+			}
+
+			BeforeEach(func() {
+				tenant = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+					Name:   "team-a",
+					Labels: map[string]string{"builds.example.com/tenant": "true"},
+				}}
+				Expect(fakeClient.Create(ctx, tenant)).To(Succeed())
+				Expect(fakeClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}})).To(Succeed())
+				owner.Spec.Shipwright.Build.NamespacedStrategies = &metav1.LabelSelector{
+					MatchLabels: map[string]string{"builds.example.com/tenant": "true"},
+				}
+			})
+
+			It("should copy the managed strategies into the matching namespaces", func() {
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				for _, name := range []string{"buildah", "buildah-1-1", "source-to-image"} {
+					object, err := getCopy("team-a", name)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(metav1.IsControlledBy(object, owner)).To(BeTrue())
+					Expect(object.GetLabels()).To(HaveKeyWithValue(common.ReplicatedFromLabel, name))
+					cluster, err := getStrategy(ctx, fakeClient, name)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(object.Object["spec"]).To(Equal(cluster.Object["spec"]))
+				}
+				_, err = getCopy("team-b", "buildah")
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+
+			It("should copy the annotations propagated to the build pods", func() {
+				owner.Spec.Shipwright.Build.UserNamespaces = &openshiftv1alpha1.UserNamespaces{State: openshiftv1alpha1.Enabled}
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				object, err := getCopy("team-a", common.UserNamespaceStrategyName)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(object.GetAnnotations()).To(HaveKeyWithValue("openshift.io/required-scc", common.UserNamespaceSCCName))
+			})
+
+			It("should correct copies which drifted", func() {
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				object, err := getCopy("team-a", "buildah")
+				Expect(err).ShouldNot(HaveOccurred())
+				object.Object["spec"] = map[string]interface{}{"buildSteps": []interface{}{}}
+				Expect(fakeClient.Update(ctx, object)).To(Succeed())
+				_, err = buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				object, err = getCopy("team-a", "buildah")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(stepMounts(object)).NotTo(BeEmpty())
+			})
+
+			It("should delete the copies once the namespace stops matching", func() {
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				tenant.Labels = nil
+				Expect(fakeClient.Update(ctx, tenant)).To(Succeed())
+				_, err = buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				_, err = getCopy("team-a", "buildah")
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+
+			It("should keep a copy referenced by a Build of its namespace", func() {
+				recorder := record.NewFakeRecorder(10)
+				buildStrategy.Recorder = recorder
+				build := &unstructured.Unstructured{}
+				build.SetAPIVersion("shipwright.io/v1alpha1")
+				build.SetKind("Build")
+				build.SetNamespace("team-a")
+				build.SetName("app")
+				Expect(unstructured.SetNestedStringMap(build.Object, map[string]string{
+					"kind": "BuildStrategy",
+					"name": "buildah",
+				}, "spec", "strategy")).To(Succeed())
+				Expect(fakeClient.Create(ctx, build)).To(Succeed())
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				tenant.Labels = nil
+				Expect(fakeClient.Update(ctx, tenant)).To(Succeed())
+				_, err = buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				object, err := getCopy("team-a", "buildah")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(object.GetAnnotations()).To(HaveKey(common.StrategyDeprecatedAnnotation))
+				_, err = getCopy("team-a", "source-to-image")
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				Expect(owner.Status.Strategies).To(ContainElement(openshiftv1alpha1.StrategyStatus{
+					Name:      "buildah",
+					Namespace: "team-a",
+					Source:    common.StrategyCatalogSource,
+					State:     openshiftv1alpha1.StrategyDeprecated,
+					Version:   "1.1",
+					Builds:    1,
+					Message:   "BuildStrategy is referenced by 1 Builds and is not removed",
+				}))
+				Expect(recorder.Events).To(Receive(HavePrefix("Warning StrategyRemovalBlocked")))
+
+				Expect(buildStrategy.Delete(ctx, owner)).To(Succeed())
+				object, err = getCopy("team-a", "buildah")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(object.GetOwnerReferences()).To(BeEmpty())
+			})
+
+			It("should not modify a BuildStrategy created by a namespace admin", func() {
+				object := &unstructured.Unstructured{}
+				object.SetAPIVersion("shipwright.io/v1alpha1")
+				object.SetKind("BuildStrategy")
+				object.SetNamespace("team-a")
+				object.SetName("buildah")
+				Expect(fakeClient.Create(ctx, object)).To(Succeed())
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				object, err = getCopy("team-a", "buildah")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(object.GetOwnerReferences()).To(BeEmpty())
+				Expect(object.Object).NotTo(HaveKey("spec"))
+			})
+		})
+
+		When("strategy bundles are referenced", func() {
+			var bundle *corev1.ConfigMap
+
+			BeforeEach(func() {
+				owner.Spec.TargetNamespace = "openshift-builds"
+				owner.Spec.Shipwright.Build.Strategies = &openshiftv1alpha1.BuildStrategies{
+					Bundles: []openshiftv1alpha1.StrategyBundle{{ConfigMap: "in-house-strategies"}},
+				}
+				bundle = &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "in-house-strategies", Namespace: "openshift-builds"},
+					Data: map[string]string{
+						"kaniko.yaml": bundledStrategy("kaniko", ""),
+						"others.yaml": bundledStrategy("privileged", "privileged: true") + "\n---\n" +
+							bundledStrategy("buildah", ""),
+					},
+				}
+				Expect(fakeClient.Create(ctx, bundle)).To(Succeed())
+			})
+
+			It("should apply the valid strategies with the same transformers as the catalog", func() {
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				object, err := getStrategy(ctx, fakeClient, "kaniko")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(metav1.IsControlledBy(object, owner)).To(BeTrue())
+				Expect(object.GetLabels()).To(HaveKeyWithValue(common.StrategyBundleLabel, "in-house-strategies"))
+				Expect(object.GetLabels()).To(HaveKeyWithValue(common.ManagedByLabel, common.ManagedByLabelValue))
+				for _, mounts := range stepMounts(object) {
+					Expect(mounts).To(ContainElement(common.TrustedCABundleVolumeName))
+				}
+				Expect(owner.Status.Strategies).To(ContainElement(openshiftv1alpha1.StrategyStatus{
+					Name:   "kaniko",
+					Source: "in-house-strategies",
+					State:  openshiftv1alpha1.StrategyApplied,
+				}))
+			})
+
+			It("should reject strategies breaking the security rules", func() {
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				_, err = getStrategy(ctx, fakeClient, "privileged")
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				Expect(owner.Status.Strategies).To(ContainElement(openshiftv1alpha1.StrategyStatus{
+					Name:    "privileged",
+					Source:  "in-house-strategies",
+					State:   openshiftv1alpha1.StrategyInvalid,
+					Message: "spec.buildSteps[0] must not run privileged",
+				}))
+			})
+
+			It("should reject strategies breaking the strategy policy", func() {
+				owner.Spec.Shipwright.Build.StrategyPolicy = &openshiftv1alpha1.StrategyPolicy{
+					AllowedRegistries:     []string{"registry.redhat.io"},
+					RequireResourceLimits: true,
+				}
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				_, err = getStrategy(ctx, fakeClient, "kaniko")
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				Expect(owner.Status.Strategies).To(ContainElement(openshiftv1alpha1.StrategyStatus{
+					Name:   "kaniko",
+					Source: "in-house-strategies",
+					State:  openshiftv1alpha1.StrategyInvalid,
+					Message: "spec.buildSteps[0] image gcr.io/kaniko-project/executor:latest is not pulled from an allowed registry (registry.redhat.io); " +
+						"spec.buildSteps[0] must set cpu and memory limits",
+				}))
+			})
+
+			It("should not override the strategies of the catalog", func() {
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				object, err := getStrategy(ctx, fakeClient, "buildah")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(object.GetLabels()).NotTo(HaveKey(common.StrategyBundleLabel))
+				Expect(owner.Status.Strategies).To(ContainElement(openshiftv1alpha1.StrategyStatus{
+					Name:    "buildah",
+					Source:  "in-house-strategies",
+					State:   openshiftv1alpha1.StrategyConflict,
+					Message: "ClusterBuildStrategy name is reserved by the catalog",
+				}))
+			})
+
+			It("should delete the strategies removed from the bundle", func() {
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				bundle.Data = map[string]string{}
+				Expect(fakeClient.Update(ctx, bundle)).To(Succeed())
+				_, err = buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				_, err = getStrategy(ctx, fakeClient, "kaniko")
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+
+			It("should report a missing bundle", func() {
+				owner.Spec.Shipwright.Build.Strategies.Bundles = append(owner.Spec.Shipwright.Build.Strategies.Bundles,
+					openshiftv1alpha1.StrategyBundle{ConfigMap: "missing"})
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(owner.Status.Strategies).To(ContainElement(openshiftv1alpha1.StrategyStatus{
+					Source:  "missing",
+					State:   openshiftv1alpha1.StrategyInvalid,
+					Message: "ConfigMap openshift-builds/missing not found",
+				}))
+			})
+
+			It("should delete the bundled strategies with the catalog", func() {
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(buildStrategy.Delete(ctx, owner)).To(Succeed())
+				_, err = getStrategy(ctx, fakeClient, "kaniko")
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
+		When("a disabled strategy is referenced by a Build", func() {
+			var recorder *record.FakeRecorder
+
+			BeforeEach(func() {
+				recorder = record.NewFakeRecorder(10)
+				buildStrategy.Recorder = recorder
+				build := &unstructured.Unstructured{}
+				build.SetAPIVersion("shipwright.io/v1alpha1")
+				build.SetKind("Build")
+				build.SetNamespace("team-a")
+				build.SetName("app")
+				Expect(unstructured.SetNestedStringMap(build.Object, map[string]string{
+					"kind": "ClusterBuildStrategy",
+					"name": "buildah",
+				}, "spec", "strategy")).To(Succeed())
+				Expect(fakeClient.Create(ctx, build)).To(Succeed())
+
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				owner.Spec.Shipwright.Build.Strategies = &openshiftv1alpha1.BuildStrategies{
+					Buildah: openshiftv1alpha1.Disabled,
+				}
+			})
+
+			It("should keep the strategy and warn the Build", func() {
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				object, err := getStrategy(ctx, fakeClient, "buildah")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(object.GetAnnotations()).To(HaveKey(common.StrategyDeprecatedAnnotation))
+				Expect(owner.Status.Strategies).To(ContainElement(openshiftv1alpha1.StrategyStatus{
+					Name:    "buildah",
+					Source:  common.StrategyCatalogSource,
+					State:   openshiftv1alpha1.StrategyDeprecated,
+					Version: "1.1",
+					Builds:  1,
+					Message: "ClusterBuildStrategy is referenced by 1 Builds and is not removed",
+				}))
+				Expect(recorder.Events).To(Receive(HavePrefix("Warning StrategyRemovalBlocked")))
+			})
+
+			It("should remove the strategy at the end of the deprecation period", func() {
+				owner.Spec.Shipwright.Build.Strategies.RemovalPolicy = openshiftv1alpha1.Deprecate
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				_, err = getStrategy(ctx, fakeClient, "buildah")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(recorder.Events).To(Receive(HavePrefix("Warning StrategyDeprecated")))
+
+				owner.Spec.Shipwright.Build.Strategies.DeprecationPeriod = &metav1.Duration{}
+				_, err = buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				_, err = getStrategy(ctx, fakeClient, "buildah")
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				Expect(recorder.Events).To(Receive(HavePrefix("Warning StrategyRemoved")))
+			})
+
+			It("should clear the deprecation once enabled again", func() {
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				owner.Spec.Shipwright.Build.Strategies.Buildah = openshiftv1alpha1.Enabled
+				_, err = buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+				object, err := getStrategy(ctx, fakeClient, "buildah")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(object.GetAnnotations()).NotTo(HaveKey(common.StrategyDeprecatedAnnotation))
+			})
+
+			It("should release the strategy when the OpenShiftBuild is deleted", func() {
+				Expect(buildStrategy.Delete(ctx, owner)).To(Succeed())
+				object, err := getStrategy(ctx, fakeClient, "buildah")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(object.GetOwnerReferences()).To(BeEmpty())
+				_, err = getStrategy(ctx, fakeClient, "source-to-image")
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+
+			It("should keep the strategy when Shipwright Build is disabled", func() {
+				Expect(buildStrategy.Remove(ctx, owner)).To(Succeed())
+				_, err := getStrategy(ctx, fakeClient, "buildah")
+				Expect(err).ShouldNot(HaveOccurred())
+				_, err = getStrategy(ctx, fakeClient, "source-to-image")
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
+		It("should list the Builds by pages and only count them again after the resync interval", func() {
+			lists := 0
+			buildStrategy.Client = interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					if list.GetObjectKind().GroupVersionKind().Kind == "BuildList" {
+						lists++
+						listOptions := &client.ListOptions{}
+						listOptions.ApplyOptions(opts)
+						Expect(listOptions.Limit).To(BeNumerically(">", 0))
+					}
+					return c.List(ctx, list, opts...)
+				},
+			})
+			for i := 0; i < 2; i++ {
+				_, err := buildStrategy.Reconcile(ctx, owner)
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+			Expect(lists).To(Equal(1))
+
+			owner.Spec.Shipwright.Build.Strategies = &openshiftv1alpha1.BuildStrategies{
+				Buildah: openshiftv1alpha1.Disabled,
+			}
+			_, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(lists).To(Equal(2))
+		})
+
+		It("should mount the shared trusted CA bundle in every step", func() {
+			_, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			for _, name := range []string{"buildah", "source-to-image"} {
+				object, err := getStrategy(ctx, fakeClient, name)
+				Expect(err).ShouldNot(HaveOccurred())
+				for _, mounts := range stepMounts(object) {
+					Expect(mounts).To(ContainElement(common.TrustedCABundleVolumeName))
+				}
+				volumes, _, err := unstructured.NestedSlice(object.Object, "spec", "volumes")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(volumes).To(ContainElement(HaveKeyWithValue("csi", HaveKeyWithValue(
+					"volumeAttributes", HaveKeyWithValue("sharedConfigMap", common.TrustedCABundleConfigMapName),
+				))))
+			}
+		})
+
+		It("should mount the cluster registries configuration and policy in every step", func() {
+			_, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			for _, name := range []string{"buildah", "source-to-image"} {
+				object, err := getStrategy(ctx, fakeClient, name)
+				Expect(err).ShouldNot(HaveOccurred())
+				for _, mounts := range stepMounts(object) {
+					registryMounts := []string{}
+					Expect(mounts).To(ContainElement(common.RegistriesVolumeName, &registryMounts))
+					Expect(registryMounts).To(HaveLen(2))
+				}
+			}
+		})
+
+		It("should not mount the trusted CA bundle when SharedResource is disabled", func() {
+			owner.Spec.SharedResource.State = openshiftv1alpha1.Disabled
+			_, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			object, err := getStrategy(ctx, fakeClient, "buildah")
+			Expect(err).ShouldNot(HaveOccurred())
+			for _, mounts := range stepMounts(object) {
+				Expect(mounts).NotTo(ContainElement(common.TrustedCABundleVolumeName))
+			}
+		})
+
+		It("should delete the strategies", func() {
+			_, err := buildStrategy.Reconcile(ctx, owner)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(buildStrategy.Delete(ctx, owner)).To(Succeed())
+			_, err = getStrategy(ctx, fakeClient, "buildah")
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should not delete strategies of the same name managed by another party", func() {
+			object := &unstructured.Unstructured{}
+			object.SetAPIVersion("shipwright.io/v1alpha1")
+			object.SetKind("ClusterBuildStrategy")
+			object.SetName("buildah")
+			Expect(fakeClient.Create(ctx, object)).To(Succeed())
+			Expect(buildStrategy.Delete(ctx, owner)).To(Succeed())
+			_, err := getStrategy(ctx, fakeClient, "buildah")
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+	})
+})
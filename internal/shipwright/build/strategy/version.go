@@ -0,0 +1,112 @@
+package strategy
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultRetainedVersions is the number of previous versions kept for every strategy of the
+// catalog when the OpenShiftBuild does not set it
+const DefaultRetainedVersions = 2
+
+// VersionedName returns the name of the copy of a strategy pinned to the version, for example
+// "buildah-1-1" for version "1.1" of "buildah"
+func VersionedName(name, version string) string {
+	return name + "-" + strings.ReplaceAll(version, ".", "-")
+}
+
+// withVersionedCopy returns the strategy of the catalog, which is the floating alias of its latest
+// version, followed by the copy pinned to its version. Strategies without a version label are
+// returned alone.
+func withVersionedCopy(res unstructured.Unstructured) []unstructured.Unstructured {
+	labels := res.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[common.StrategyLabel] = res.GetName()
+	res.SetLabels(labels)
+
+	version := labels[common.StrategyVersionLabel]
+	if version == "" {
+		return []unstructured.Unstructured{res}
+	}
+	pinned := res.DeepCopy()
+	pinned.SetName(VersionedName(res.GetName(), version))
+	return []unstructured.Unstructured{res, *pinned}
+}
+
+// previousVersions returns the versioned copies of the enabled strategies published by previous
+// operator releases which are retained, newest first. Older copies are left to be retired.
+func (bs *BuildStrategy) previousVersions(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, enabled []string) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "shipwright.io",
+		Version: "v1alpha1",
+		Kind:    "ClusterBuildStrategyList",
+	})
+	if err := bs.Client.List(ctx, list, client.HasLabels{common.StrategyLabel, common.StrategyVersionLabel}); err != nil {
+		return nil, err
+	}
+
+	current := map[string]string{}
+	for _, res := range bs.Manifest.Filter(byNames(enabled)).Resources() {
+		current[res.GetName()] = res.GetLabels()[common.StrategyVersionLabel]
+	}
+
+	versions := map[string][]unstructured.Unstructured{}
+	for _, object := range list.Items {
+		name := object.GetLabels()[common.StrategyLabel]
+		version, ok := current[name]
+		if !ok || object.GetName() == name || object.GetName() == VersionedName(name, version) ||
+			!metav1.IsControlledBy(&object, owner) {
+			continue
+		}
+		versions[name] = append(versions[name], object)
+	}
+
+	retained := []unstructured.Unstructured{}
+	for _, name := range enabled {
+		objects := versions[name]
+		slices.SortFunc(objects, func(a, b unstructured.Unstructured) int {
+			return compareVersions(b.GetLabels()[common.StrategyVersionLabel], a.GetLabels()[common.StrategyVersionLabel])
+		})
+		retained = append(retained, objects[:min(len(objects), retainedVersions(owner))]...)
+	}
+	return retained, nil
+}
+
+// compareVersions compares dotted numeric versions, such as "1.10" and "1.9"
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+// retainedVersions returns the number of previous versions kept for every strategy
+func retainedVersions(owner *openshiftv1alpha1.OpenShiftBuild) int {
+	if owner.Spec.Shipwright == nil || owner.Spec.Shipwright.Build == nil || owner.Spec.Shipwright.Build.Strategies == nil ||
+		owner.Spec.Shipwright.Build.Strategies.RetainedVersions == nil {
+		return DefaultRetainedVersions
+	}
+	return int(*owner.Spec.Shipwright.Build.Strategies.RetainedVersions)
+}
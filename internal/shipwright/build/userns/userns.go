@@ -0,0 +1,216 @@
+package userns
+
+import (
+	"context"
+	"errors"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// buildUserID is the user the buildah-userns strategy runs as inside the user namespace
+const buildUserID int64 = 1000
+
+// requiredSCCAnnotation pins a pod to an SCC. The buildah-userns strategy sets it on its pods.
+const requiredSCCAnnotation = "openshift.io/required-scc"
+
+// requirePodLevel is the userNamespaceLevel of the SCC requiring pods to set hostUsers: false. The
+// field is not part of the vendored SecurityContextConstraints API and is set with a merge patch.
+const requirePodLevel = `{"userNamespaceLevel":"RequirePodLevel"}`
+
+// defaultServiceAccounts are the build service accounts allowed to use the SCC when the
+// OpenShiftBuild lists none
+var defaultServiceAccounts = []string{common.DefaultShareServiceAccountName, common.BuildServiceAccountName}
+
+// UserNamespace type defines methods to create and delete the SecurityContextConstraints allowing
+// builds to run in a user namespace, and its binding to the build service accounts
+type UserNamespace struct {
+	Client client.Client
+}
+
+// New creates new instance of UserNamespace type
+func New(client client.Client) *UserNamespace {
+	return &UserNamespace{
+		Client: client,
+	}
+}
+
+// IsEnabled returns true if the OpenShiftBuild enables builds running in a user namespace
+func IsEnabled(owner *openshiftv1alpha1.OpenShiftBuild) bool {
+	if owner.Spec.Shipwright == nil || owner.Spec.Shipwright.Build == nil ||
+		owner.Spec.Shipwright.Build.UserNamespaces == nil {
+		return false
+	}
+	return owner.Spec.Shipwright.Build.UserNamespaces.State == openshiftv1alpha1.Enabled
+}
+
+// NamespaceSelector returns the selector of the namespaces whose build service accounts may use
+// the SecurityContextConstraints, or nil if user namespaces are disabled or no namespace is selected
+func NamespaceSelector(owner *openshiftv1alpha1.OpenShiftBuild) *metav1.LabelSelector {
+	if !IsEnabled(owner) {
+		return nil
+	}
+	return owner.Spec.Shipwright.Build.UserNamespaces.Namespaces
+}
+
+// CreateOrUpdate creates the SecurityContextConstraints, the ClusterRole allowing its use, and the
+// ClusterRoleBinding granting it to the build service accounts of the selected namespaces. Returns
+// the observed state to record in the OpenShiftBuild status.
+func (u *UserNamespace) CreateOrUpdate(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) (*openshiftv1alpha1.UserNamespacesStatus, error) {
+	selector := NamespaceSelector(owner)
+	if selector == nil {
+		return nil, errors.New("userNamespaces.namespaces is required when user namespaces are enabled")
+	}
+	namespaces, err := common.MatchingNamespaces(ctx, u.Client, selector)
+	if err != nil {
+		return nil, err
+	}
+	status := &openshiftv1alpha1.UserNamespacesStatus{
+		SecurityContextConstraints: common.UserNamespaceSCCName,
+		Namespaces:                 namespaces,
+	}
+
+	subjects := []rbacv1.Subject{}
+	for _, namespace := range namespaces {
+		for _, serviceAccount := range serviceAccounts(owner) {
+			subjects = append(subjects, rbacv1.Subject{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      serviceAccount,
+				Namespace: namespace,
+			})
+		}
+	}
+
+	scc := &securityv1.SecurityContextConstraints{}
+	scc.SetName(common.UserNamespaceSCCName)
+	if _, err := ctrl.CreateOrUpdate(ctx, u.Client, scc, func() error {
+		setSecurityContextConstraints(scc)
+		return ctrl.SetControllerReference(owner, scc, u.Client.Scheme())
+	}); err != nil {
+		return nil, err
+	}
+	if err := u.Client.Patch(ctx, scc, client.RawPatch(types.MergePatchType, []byte(requirePodLevel))); err != nil {
+		return nil, err
+	}
+
+	role := &rbacv1.ClusterRole{}
+	role.SetName(common.UserNamespaceRoleName)
+	if _, err := ctrl.CreateOrUpdate(ctx, u.Client, role, func() error {
+		role.Rules = []rbacv1.PolicyRule{{
+			APIGroups:     []string{securityv1.GroupName},
+			Resources:     []string{"securitycontextconstraints"},
+			ResourceNames: []string{common.UserNamespaceSCCName},
+			Verbs:         []string{"use"},
+		}}
+		return ctrl.SetControllerReference(owner, role, u.Client.Scheme())
+	}); err != nil {
+		return nil, err
+	}
+
+	binding := &rbacv1.ClusterRoleBinding{}
+	binding.SetName(common.UserNamespaceRoleName)
+	if _, err := ctrl.CreateOrUpdate(ctx, u.Client, binding, func() error {
+		binding.RoleRef = rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     common.UserNamespaceRoleName,
+		}
+		binding.Subjects = subjects
+		return ctrl.SetControllerReference(owner, binding, u.Client.Scheme())
+	}); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// Delete deletes the SecurityContextConstraints, its ClusterRole and its ClusterRoleBinding
+func (u *UserNamespace) Delete(ctx context.Context) error {
+	binding := &rbacv1.ClusterRoleBinding{}
+	binding.SetName(common.UserNamespaceRoleName)
+	role := &rbacv1.ClusterRole{}
+	role.SetName(common.UserNamespaceRoleName)
+	scc := &securityv1.SecurityContextConstraints{}
+	scc.SetName(common.UserNamespaceSCCName)
+	for _, object := range []client.Object{binding, role, scc} {
+		if err := u.Client.Delete(ctx, object); common.IgnoreMissing(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serviceAccounts returns the build service accounts allowed to use the SCC
+func serviceAccounts(owner *openshiftv1alpha1.OpenShiftBuild) []string {
+	if accounts := owner.Spec.Shipwright.Build.UserNamespaces.ServiceAccounts; len(accounts) > 0 {
+		return accounts
+	}
+	return defaultServiceAccounts
+}
+
+// IsolatePod runs the pod in a user namespace when it requires the SCC, as the SCC only admits
+// pods setting hostUsers: false, which the strategy API cannot set. Returns true if the pod was
+// changed.
+func IsolatePod(pod *corev1.Pod) bool {
+	if pod.Annotations[requiredSCCAnnotation] != common.UserNamespaceSCCName {
+		return false
+	}
+	if pod.Spec.HostUsers != nil && !*pod.Spec.HostUsers {
+		return false
+	}
+	pod.Spec.HostUsers = ptr.To(false)
+	return true
+}
+
+// setSecurityContextConstraints sets the fields of the SCC used by the buildah-userns strategy. It
+// only differs from restricted-v2 by allowing privilege escalation and the SETUID and SETGID
+// capabilities, which buildah needs to map the users of the image inside the user namespace, and by
+// fixing the user to the one of the strategy.
+func setSecurityContextConstraints(scc *securityv1.SecurityContextConstraints) {
+	scc.Priority = nil
+	scc.AllowPrivilegedContainer = false
+	scc.AllowPrivilegeEscalation = ptr.To(true)
+	scc.DefaultAddCapabilities = nil
+	scc.RequiredDropCapabilities = []corev1.Capability{"KILL", "MKNOD"}
+	scc.AllowedCapabilities = []corev1.Capability{"SETUID", "SETGID"}
+	scc.AllowHostDirVolumePlugin = false
+	scc.Volumes = []securityv1.FSType{
+		securityv1.FSTypeConfigMap,
+		securityv1.FSTypeCSI,
+		securityv1.FSTypeDownwardAPI,
+		securityv1.FSTypeEmptyDir,
+		securityv1.FSTypeEphemeral,
+		securityv1.FSTypePersistentVolumeClaim,
+		securityv1.FSProjected,
+		securityv1.FSTypeSecret,
+	}
+	scc.AllowHostNetwork = false
+	scc.AllowHostPorts = false
+	scc.AllowHostPID = false
+	scc.AllowHostIPC = false
+	scc.SeccompProfiles = []string{"runtime/default"}
+	scc.SELinuxContext = securityv1.SELinuxContextStrategyOptions{
+		Type: securityv1.SELinuxStrategyMustRunAs,
+	}
+	scc.RunAsUser = securityv1.RunAsUserStrategyOptions{
+		Type: securityv1.RunAsUserStrategyMustRunAs,
+		UID:  ptr.To(buildUserID),
+	}
+	scc.SupplementalGroups = securityv1.SupplementalGroupsStrategyOptions{
+		Type: securityv1.SupplementalGroupsStrategyRunAsAny,
+	}
+	scc.FSGroup = securityv1.FSGroupStrategyOptions{
+		Type: securityv1.FSGroupStrategyMustRunAs,
+	}
+	scc.ReadOnlyRootFilesystem = false
+	scc.Users = []string{}
+	scc.Groups = []string{}
+}
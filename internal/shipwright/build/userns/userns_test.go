@@ -0,0 +1,164 @@
+package userns_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	securityv1 "github.com/openshift/api/security/v1"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/userns"
+)
+
+var _ = Describe("UserNamespace", Label("shipwright", "userns"), func() {
+	var (
+		ctx           context.Context
+		fakeClient    client.Client
+		userNamespace *userns.UserNamespace
+		owner         *openshiftv1alpha1.OpenShiftBuild
+	)
+
+	getBinding := func() (*rbacv1.ClusterRoleBinding, error) {
+		object := &rbacv1.ClusterRoleBinding{}
+		err := fakeClient.Get(ctx, client.ObjectKey{Name: common.UserNamespaceRoleName}, object)
+		return object, err
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		owner = &openshiftv1alpha1.OpenShiftBuild{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: common.OpenShiftBuildResourceName,
+				UID:  uuid.NewUUID(),
+			},
+			Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+				Shipwright: &openshiftv1alpha1.Shipwright{
+					Build: &openshiftv1alpha1.ShipwrightBuild{
+						State: openshiftv1alpha1.Enabled,
+						UserNamespaces: &openshiftv1alpha1.UserNamespaces{
+							State: openshiftv1alpha1.Enabled,
+							Namespaces: &metav1.LabelSelector{
+								MatchLabels: map[string]string{"builds.example.com/userns": "true"},
+							},
+						},
+					},
+				},
+			},
+		}
+		tenant := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "tenant",
+			Labels: map[string]string{"builds.example.com/userns": "true"},
+		}}
+		other := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(tenant, other).Build()
+		userNamespace = userns.New(fakeClient)
+	})
+
+	It("should create a SecurityContextConstraints without privileges", func() {
+		status, err := userNamespace.CreateOrUpdate(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(status.SecurityContextConstraints).To(Equal(common.UserNamespaceSCCName))
+
+		scc := &securityv1.SecurityContextConstraints{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Name: common.UserNamespaceSCCName}, scc)).To(Succeed())
+		Expect(metav1.IsControlledBy(scc, owner)).To(BeTrue())
+		Expect(scc.AllowPrivilegedContainer).To(BeFalse())
+		Expect(scc.AllowHostDirVolumePlugin).To(BeFalse())
+		Expect(scc.AllowedCapabilities).To(ConsistOf(corev1.Capability("SETUID"), corev1.Capability("SETGID")))
+		Expect(scc.RunAsUser.Type).To(Equal(securityv1.RunAsUserStrategyMustRunAs))
+		Expect(*scc.RunAsUser.UID).NotTo(BeZero())
+		Expect(scc.Users).To(BeEmpty())
+		Expect(scc.Groups).To(BeEmpty())
+	})
+
+	It("should grant the SecurityContextConstraints to the build service accounts of the selected namespaces", func() {
+		status, err := userNamespace.CreateOrUpdate(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(status.Namespaces).To(ConsistOf("tenant"))
+
+		role := &rbacv1.ClusterRole{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Name: common.UserNamespaceRoleName}, role)).To(Succeed())
+		Expect(role.Rules).To(HaveLen(1))
+		Expect(role.Rules[0].ResourceNames).To(ConsistOf(common.UserNamespaceSCCName))
+		Expect(role.Rules[0].Verbs).To(ConsistOf("use"))
+
+		binding, err := getBinding()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(binding.RoleRef.Name).To(Equal(common.UserNamespaceRoleName))
+		Expect(binding.Subjects).To(ConsistOf(
+			rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "pipeline", Namespace: "tenant"},
+			rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: common.BuildServiceAccountName, Namespace: "tenant"},
+		))
+	})
+
+	It("should only grant the SecurityContextConstraints to the listed service accounts", func() {
+		owner.Spec.Shipwright.Build.UserNamespaces.ServiceAccounts = []string{"builder"}
+		_, err := userNamespace.CreateOrUpdate(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		binding, err := getBinding()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(binding.Subjects).To(ConsistOf(
+			rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "builder", Namespace: "tenant"},
+		))
+	})
+
+	It("should require a namespace selector", func() {
+		owner.Spec.Shipwright.Build.UserNamespaces.Namespaces = nil
+		_, err := userNamespace.CreateOrUpdate(ctx, owner)
+		Expect(err).To(MatchError(ContainSubstring("userNamespaces.namespaces is required")))
+		_, err = getBinding()
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should require the pods to run in a user namespace", func() {
+		patches := []string{}
+		fakeClient = interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, object client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				data, err := patch.Data(object)
+				Expect(err).ShouldNot(HaveOccurred())
+				patches = append(patches, string(data))
+				return nil
+			},
+		})
+		userNamespace = userns.New(fakeClient)
+		_, err := userNamespace.CreateOrUpdate(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(patches).To(ConsistOf(`{"userNamespaceLevel":"RequirePodLevel"}`))
+
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{"openshift.io/required-scc": common.UserNamespaceSCCName},
+		}}
+		Expect(userns.IsolatePod(pod)).To(BeTrue())
+		Expect(pod.Spec.HostUsers).To(HaveValue(BeFalse()))
+		Expect(userns.IsolatePod(pod)).To(BeFalse())
+		Expect(userns.IsolatePod(&corev1.Pod{})).To(BeFalse())
+	})
+
+	It("should delete the SecurityContextConstraints and its binding", func() {
+		_, err := userNamespace.CreateOrUpdate(ctx, owner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(userNamespace.Delete(ctx)).To(Succeed())
+
+		scc := &securityv1.SecurityContextConstraints{}
+		err = fakeClient.Get(ctx, client.ObjectKey{Name: common.UserNamespaceSCCName}, scc)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		_, err = getBinding()
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should ignore the missing SecurityContextConstraints API on deletion", func() {
+		fakeClient = fake.NewClientBuilder().Build()
+		Expect(userns.New(fakeClient).Delete(ctx)).To(Succeed())
+	})
+})
@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/buildnamespace"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/defaults"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/queue"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// BuildWebhookPath is the path serving the build and build run mutating webhook
+const BuildWebhookPath = "/mutate-shipwright-io-build"
+
+//+kubebuilder:webhook:path=/mutate-shipwright-io-build,mutating=true,failurePolicy=fail,sideEffects=None,groups=shipwright.io,resources=builds;buildruns,verbs=create;update,versions=v1alpha1;v1beta1,name=mbuild.operator.openshift.io,admissionReviewVersions=v1
+
+// BuildDefaulter applies the build defaults and overrides of the OpenShiftBuild to Builds and
+// BuildRuns. It also sets the push secret of the Builds whose output targets the internal registry,
+// in the namespaces of a BuildNamespaceProfile providing internal registry credentials, and extends
+// the timeout of the BuildRuns by the queue timeout while the BuildRun concurrency limits are enabled.
+type BuildDefaulter struct {
+	// Client reads the OpenShiftBuild, the namespaces and the profiles from the cache of the manager,
+	// and the Builds from the API server
+	Client client.Reader
+}
+
+var _ admission.Handler = &BuildDefaulter{}
+
+// SetupBuildWebhookWithManager registers the build and build run mutating webhook. The builds are
+// handled as unstructured objects, since the operator does not depend on the Shipwright Build API.
+func SetupBuildWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(BuildWebhookPath, &webhook.Admission{
+		Handler: &BuildDefaulter{
+			Client: mgr.GetClient(),
+		},
+	})
+	return nil
+}
+
+// Handle applies the build defaults when the object is created, and the overrides whenever it is
+// created or updated, so that users may remove defaulted values. It also sets the push secret of the
+// build when its output targets the internal registry and it does not set one, and extends the
+// timeout of the created BuildRuns which may be queued.
+func (d *BuildDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if common.IsPlatformNamespace(req.Namespace) {
+		return admission.Allowed("")
+	}
+	object := &unstructured.Unstructured{}
+	if err := object.UnmarshalJSON(req.Object.Raw); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	owner := &openshiftv1alpha1.OpenShiftBuild{}
+	err := d.Client.Get(ctx, client.ObjectKey{Name: common.OpenShiftBuildResourceName}, owner)
+	if client.IgnoreNotFound(err) != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if buildDefaults := owner.Spec.BuildDefaults; err == nil && buildDefaults != nil {
+		if req.Operation != admissionv1.Create {
+			buildDefaults = &openshiftv1alpha1.BuildDefaults{Overrides: buildDefaults.Overrides}
+		}
+		if err := defaults.Apply(object, buildDefaults); err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+	}
+
+	if object.GetKind() == "Build" {
+		if err := d.defaultPushSecret(ctx, req.Namespace, object); err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+	}
+	if object.GetKind() == "BuildRun" && req.Operation == admissionv1.Create && queue.IsEnabled(owner) {
+		if err := d.extendTimeout(ctx, req.Namespace, object, owner); err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+	}
+
+	mutated, err := json.Marshal(object.Object)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, mutated)
+}
+
+// defaultPushSecret sets the push secret of the build when its output targets the internal registry
+// and it does not set one
+func (d *BuildDefaulter) defaultPushSecret(ctx context.Context, namespace string, object *unstructured.Unstructured) error {
+	image, _, _ := unstructured.NestedString(object.Object, "spec", "output", "image")
+	if !strings.HasPrefix(image, common.InternalRegistryHost+"/") {
+		return nil
+	}
+	// the push secret was named credentials before v1beta1
+	pushSecretField := []string{"spec", "output", "pushSecret"}
+	if object.GroupVersionKind().Version == "v1alpha1" {
+		pushSecretField = []string{"spec", "output", "credentials", "name"}
+	}
+	if pushSecret, _, _ := unstructured.NestedString(object.Object, pushSecretField...); pushSecret != "" {
+		return nil
+	}
+
+	profile, err := d.namespaceProfile(ctx, namespace)
+	if err != nil || profile == nil || !buildnamespace.IsInternalRegistryEnabled(profile) {
+		return err
+	}
+	return unstructured.SetNestedField(object.Object, buildnamespace.PushSecretName(profile), pushSecretField...)
+}
+
+// extendTimeout adds the queue timeout to the timeout of the BuildRun, unless it runs a Build which
+// does not exist yet, whose timeout is unknown
+func (d *BuildDefaulter) extendTimeout(ctx context.Context, namespace string, buildRun *unstructured.Unstructured, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	if timeout, _, _ := unstructured.NestedString(buildRun.Object, "spec", "timeout"); timeout != "" {
+		return queue.ExtendTimeout(buildRun, "", owner)
+	}
+	timeout, found, err := d.buildTimeout(ctx, namespace, buildRun)
+	if err != nil || !found {
+		return err
+	}
+	return queue.ExtendTimeout(buildRun, timeout, owner)
+}
+
+// buildTimeout returns the timeout of the Build embedded in or referenced by the BuildRun, and
+// false if the referenced Build is not found
+func (d *BuildDefaulter) buildTimeout(ctx context.Context, namespace string, buildRun *unstructured.Unstructured) (string, bool, error) {
+	// the Build was embedded in buildSpec and referenced by buildRef before v1beta1
+	specField, nameField := []string{"spec", "build", "spec"}, []string{"spec", "build", "name"}
+	if buildRun.GroupVersionKind().Version == "v1alpha1" {
+		specField, nameField = []string{"spec", "buildSpec"}, []string{"spec", "buildRef", "name"}
+	}
+	if spec, found, _ := unstructured.NestedMap(buildRun.Object, specField...); found {
+		timeout, _, _ := unstructured.NestedString(spec, "timeout")
+		return timeout, true, nil
+	}
+	name, _, _ := unstructured.NestedString(buildRun.Object, nameField...)
+	if name == "" {
+		return "", false, nil
+	}
+
+	build := &unstructured.Unstructured{}
+	build.SetGroupVersionKind(buildRun.GroupVersionKind().GroupVersion().WithKind("Build"))
+	if err := d.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, build); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	timeout, _, _ := unstructured.NestedString(build.Object, "spec", "timeout")
+	return timeout, true, nil
+}
+
+// namespaceProfile returns the BuildNamespaceProfile which onboarded the namespace, or nil
+func (d *BuildDefaulter) namespaceProfile(ctx context.Context, name string) (*openshiftv1alpha1.BuildNamespaceProfile, error) {
+	namespace := &corev1.Namespace{}
+	if err := d.Client.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	profileName := namespace.Labels[common.BuildNamespaceProfileLabel]
+	if profileName == "" {
+		return nil, nil
+	}
+	profile := &openshiftv1alpha1.BuildNamespaceProfile{}
+	if err := d.Client.Get(ctx, client.ObjectKey{Name: profileName}, profile); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return profile, nil
+}
@@ -0,0 +1,204 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/redhat-openshift-builds/operator/internal/webhook"
+)
+
+var _ = Describe("BuildDefaulter", Label("webhook", "build"), func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		defaulter  *webhook.BuildDefaulter
+		profile    *openshiftv1alpha1.BuildNamespaceProfile
+		apiVersion string
+		operation  admissionv1.Operation
+		output     map[string]interface{}
+	)
+
+	handle := func() admission.Response {
+		object := map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       "Build",
+			"metadata":   map[string]interface{}{"name": "sample", "namespace": "tenant"},
+			"spec":       map[string]interface{}{"output": output},
+		}
+		raw, err := json.Marshal(object)
+		Expect(err).ShouldNot(HaveOccurred())
+		return defaulter.Handle(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			Namespace: "tenant",
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+	}
+
+	handleBuildRun := func(spec map[string]interface{}) admission.Response {
+		object := map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       "BuildRun",
+			"metadata":   map[string]interface{}{"name": "sample-run", "namespace": "tenant"},
+			"spec":       spec,
+		}
+		raw, err := json.Marshal(object)
+		Expect(err).ShouldNot(HaveOccurred())
+		return defaulter.Handle(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			Namespace: "tenant",
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		scheme.AddKnownTypeWithName(schema.GroupVersionKind{Group: "shipwright.io", Version: "v1beta1", Kind: "Build"}, &unstructured.Unstructured{})
+		profile = &openshiftv1alpha1.BuildNamespaceProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "team"},
+			Spec: openshiftv1alpha1.BuildNamespaceProfileSpec{
+				InternalRegistry: &openshiftv1alpha1.InternalRegistry{State: openshiftv1alpha1.Enabled},
+			},
+		}
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "tenant",
+			Labels: map[string]string{common.BuildNamespaceProfileLabel: "team"},
+		}}
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(profile, namespace).Build()
+		defaulter = &webhook.BuildDefaulter{Client: fakeClient}
+		apiVersion = "shipwright.io/v1beta1"
+		operation = admissionv1.Create
+		output = map[string]interface{}{
+			"image": common.InternalRegistryHost + "/tenant/sample:latest",
+		}
+	})
+
+	It("should default the push secret of builds targeting the internal registry", func() {
+		response := handle()
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Patches).To(HaveLen(1))
+		Expect(response.Patches[0].Path).To(Equal("/spec/output/pushSecret"))
+		Expect(response.Patches[0].Value).To(Equal("shipwright-builder-registry-push"))
+	})
+
+	It("should default the credentials of v1alpha1 builds", func() {
+		apiVersion = "shipwright.io/v1alpha1"
+		response := handle()
+		Expect(response.Patches).To(HaveLen(1))
+		Expect(response.Patches[0].Path).To(Equal("/spec/output/credentials"))
+		Expect(response.Patches[0].Value).To(Equal(map[string]interface{}{"name": "shipwright-builder-registry-push"}))
+	})
+
+	It("should keep the push secret set by the build", func() {
+		output["pushSecret"] = "my-secret"
+		response := handle()
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Patches).To(BeEmpty())
+	})
+
+	It("should not mutate builds targeting other registries", func() {
+		output["image"] = "quay.io/team/sample:latest"
+		Expect(handle().Patches).To(BeEmpty())
+	})
+
+	It("should apply the build defaults of the OpenShiftBuild", func() {
+		owner := &openshiftv1alpha1.OpenShiftBuild{
+			ObjectMeta: metav1.ObjectMeta{Name: common.OpenShiftBuildResourceName},
+			Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+				BuildDefaults: &openshiftv1alpha1.BuildDefaults{
+					ImageLabels: map[string]string{"vendor": "acme"},
+				},
+			},
+		}
+		Expect(fakeClient.Create(ctx, owner)).To(Succeed())
+		output["image"] = "quay.io/team/sample:latest"
+
+		response := handle()
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Patches).To(HaveLen(1))
+		Expect(response.Patches[0].Path).To(Equal("/spec/output/labels"))
+		Expect(response.Patches[0].Value).To(Equal(map[string]interface{}{"vendor": "acme"}))
+	})
+
+	It("should only apply the build overrides on update", func() {
+		owner := &openshiftv1alpha1.OpenShiftBuild{
+			ObjectMeta: metav1.ObjectMeta{Name: common.OpenShiftBuildResourceName},
+			Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+				BuildDefaults: &openshiftv1alpha1.BuildDefaults{
+					ImageLabels: map[string]string{"vendor": "acme"},
+					Overrides: &openshiftv1alpha1.BuildOverrides{
+						ImageLabels: map[string]string{"cluster": "production"},
+					},
+				},
+			},
+		}
+		Expect(fakeClient.Create(ctx, owner)).To(Succeed())
+		output["image"] = "quay.io/team/sample:latest"
+		operation = admissionv1.Update
+
+		response := handle()
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Patches).To(HaveLen(1))
+		Expect(response.Patches[0].Path).To(Equal("/spec/output/labels"))
+		Expect(response.Patches[0].Value).To(Equal(map[string]interface{}{"cluster": "production"}))
+	})
+
+	It("should not mutate builds when the internal registry is disabled", func() {
+		profile.Spec.InternalRegistry.State = openshiftv1alpha1.Disabled
+		Expect(fakeClient.Update(ctx, profile)).To(Succeed())
+		Expect(handle().Patches).To(BeEmpty())
+	})
+
+	It("should extend the timeout of the BuildRuns by the queue timeout while the limits are enabled", func() {
+		owner := &openshiftv1alpha1.OpenShiftBuild{
+			ObjectMeta: metav1.ObjectMeta{Name: common.OpenShiftBuildResourceName},
+			Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+				Shipwright: &openshiftv1alpha1.Shipwright{
+					Build: &openshiftv1alpha1.ShipwrightBuild{
+						Concurrency: &openshiftv1alpha1.BuildRunConcurrency{State: openshiftv1alpha1.Enabled},
+					},
+				},
+			},
+		}
+		Expect(fakeClient.Create(ctx, owner)).To(Succeed())
+		build := &unstructured.Unstructured{}
+		build.SetAPIVersion(apiVersion)
+		build.SetKind("Build")
+		build.SetNamespace("tenant")
+		build.SetName("sample")
+		Expect(unstructured.SetNestedField(build.Object, "10m", "spec", "timeout")).To(Succeed())
+		Expect(fakeClient.Create(ctx, build)).To(Succeed())
+
+		response := handleBuildRun(map[string]interface{}{"build": map[string]interface{}{"name": "sample"}})
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Patches).To(HaveLen(1))
+		Expect(response.Patches[0].Path).To(Equal("/spec/timeout"))
+		Expect(response.Patches[0].Value).To(Equal("1h10m0s"))
+
+		response = handleBuildRun(map[string]interface{}{"timeout": "5m", "build": map[string]interface{}{"name": "sample"}})
+		Expect(response.Patches).To(HaveLen(1))
+		Expect(response.Patches[0].Value).To(Equal("1h5m0s"))
+
+		// the timeout of a Build created after its BuildRun is unknown
+		Expect(handleBuildRun(map[string]interface{}{"build": map[string]interface{}{"name": "missing"}}).Patches).To(BeEmpty())
+
+		operation = admissionv1.Update
+		Expect(handleBuildRun(map[string]interface{}{"timeout": "5m"}).Patches).To(BeEmpty())
+	})
+})
@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/output"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// BuildOutputWebhookPath is the path serving the build and build run validating webhook
+const BuildOutputWebhookPath = "/validate-shipwright-io-build"
+
+//+kubebuilder:webhook:path=/validate-shipwright-io-build,mutating=false,failurePolicy=fail,sideEffects=None,groups=shipwright.io,resources=builds;buildruns,verbs=create;update,versions=v1alpha1;v1beta1,name=vbuild.operator.openshift.io,admissionReviewVersions=v1
+
+// BuildOutputValidator rejects Builds and BuildRuns whose output image is not pushed to one of the
+// registries allowed by the output policy of the OpenShiftBuild
+type BuildOutputValidator struct {
+	// Client reads the OpenShiftBuild and the namespaces from the cache of the manager. Builds are
+	// unstructured, so they are read from the API server.
+	Client client.Reader
+}
+
+var _ admission.Handler = &BuildOutputValidator{}
+
+// SetupBuildOutputWebhookWithManager registers the build and build run validating webhook
+func SetupBuildOutputWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(BuildOutputWebhookPath, &webhook.Admission{
+		Handler: &BuildOutputValidator{
+			Client: mgr.GetClient(),
+		},
+	})
+	return nil
+}
+
+// Handle allows the Build or BuildRun if its output images are pushed to allowed registries. A
+// BuildRun which neither overrides nor embeds its output is checked against the output of the Build
+// it references. Updates which keep the output images, and objects being deleted, are allowed, so
+// that objects created before the policy can still be managed.
+func (v *BuildOutputValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if common.IsPlatformNamespace(req.Namespace) {
+		return admission.Allowed("")
+	}
+	object := &unstructured.Unstructured{}
+	if err := object.UnmarshalJSON(req.Object.Raw); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if !object.GetDeletionTimestamp().IsZero() {
+		return admission.Allowed("")
+	}
+	images := output.Images(object)
+	if req.Operation == admissionv1.Update {
+		old := &unstructured.Unstructured{}
+		if err := old.UnmarshalJSON(req.OldObject.Raw); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if maps.Equal(images, output.Images(old)) {
+			return admission.Allowed("")
+		}
+	}
+
+	owner := &openshiftv1alpha1.OpenShiftBuild{}
+	if err := v.Client.Get(ctx, client.ObjectKey{Name: common.OpenShiftBuildResourceName}, owner); err != nil {
+		if apierrors.IsNotFound(err) {
+			return admission.Allowed("")
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	namespace := &corev1.Namespace{}
+	if err := v.Client.Get(ctx, client.ObjectKey{Name: req.Namespace}, namespace); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	registries, err := output.AllowedRegistries(output.Policy(owner), namespace)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if registries == nil {
+		return admission.Allowed("")
+	}
+
+	if object.GetKind() == "BuildRun" && len(images) == 0 {
+		if images, err = v.buildImages(ctx, object); err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+	}
+	if violations := output.Violations(images, registries); len(violations) > 0 {
+		return admission.Denied(fmt.Sprintf("%s %s violates the build output policy: %s",
+			object.GetKind(), object.GetName(), strings.Join(violations, "; ")))
+	}
+	return admission.Allowed("")
+}
+
+// buildImages returns the output images of the Build referenced by the BuildRun. A missing Build
+// has no images, since the BuildRun fails without it.
+func (v *BuildOutputValidator) buildImages(ctx context.Context, buildRun *unstructured.Unstructured) (map[string]string, error) {
+	name := output.BuildName(buildRun)
+	if name == "" {
+		return nil, nil
+	}
+	build := &unstructured.Unstructured{}
+	build.SetGroupVersionKind(buildRun.GroupVersionKind().GroupVersion().WithKind("Build"))
+	err := v.Client.Get(ctx, client.ObjectKey{Namespace: buildRun.GetNamespace(), Name: name}, build)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	images := map[string]string{}
+	for path, image := range output.Images(build) {
+		images["Build "+name+" "+path] = image
+	}
+	return images, nil
+}
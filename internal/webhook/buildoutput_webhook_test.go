@@ -0,0 +1,143 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/redhat-openshift-builds/operator/internal/webhook"
+)
+
+var _ = Describe("BuildOutputValidator", Label("webhook", "output"), func() {
+	var (
+		ctx       context.Context
+		objects   []client.Object
+		namespace string
+		validator *webhook.BuildOutputValidator
+	)
+
+	newObject := func(kind string, spec map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "shipwright.io/v1beta1",
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": "sample", "namespace": "tenant"},
+			"spec":       spec,
+		}
+	}
+
+	outputSpec := func(image string) map[string]interface{} {
+		return map[string]interface{}{"output": map[string]interface{}{"image": image}}
+	}
+
+	handle := func(operation admissionv1.Operation, object, old map[string]interface{}) admission.Response {
+		scheme := runtime.NewScheme()
+		Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		buildKind := schema.GroupVersionKind{Group: "shipwright.io", Version: "v1beta1", Kind: "Build"}
+		scheme.AddKnownTypeWithName(buildKind, &unstructured.Unstructured{})
+		validator = &webhook.BuildOutputValidator{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		}
+
+		request := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			Namespace: namespace,
+		}}
+		raw, err := json.Marshal(object)
+		Expect(err).ShouldNot(HaveOccurred())
+		request.Object = runtime.RawExtension{Raw: raw}
+		if old != nil {
+			raw, err = json.Marshal(old)
+			Expect(err).ShouldNot(HaveOccurred())
+			request.OldObject = runtime.RawExtension{Raw: raw}
+		}
+		return validator.Handle(ctx, request)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = "tenant"
+		owner := &openshiftv1alpha1.OpenShiftBuild{
+			ObjectMeta: metav1.ObjectMeta{Name: common.OpenShiftBuildResourceName},
+			Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+				Shipwright: &openshiftv1alpha1.Shipwright{
+					Build: &openshiftv1alpha1.ShipwrightBuild{
+						State: openshiftv1alpha1.Enabled,
+						OutputPolicy: &openshiftv1alpha1.BuildOutputPolicy{
+							AllowedRegistries: []string{"quay.io/acme"},
+							Exceptions: []openshiftv1alpha1.BuildOutputPolicyException{{
+								NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "oss"}},
+								AllowedRegistries: []string{"ghcr.io/acme"},
+							}},
+						},
+					},
+				},
+			},
+		}
+		objects = []client.Object{owner, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}}}
+	})
+
+	It("should allow Builds pushing to an allowed registry", func() {
+		response := handle(admissionv1.Create, newObject("Build", outputSpec("quay.io/acme/sample")), nil)
+		Expect(response.Allowed).To(BeTrue())
+	})
+
+	It("should reject Builds and BuildRuns pushing to other registries", func() {
+		response := handle(admissionv1.Create, newObject("Build", outputSpec("docker.io/someone/sample")), nil)
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Message).To(ContainSubstring("spec.output.image docker.io/someone/sample is not pushed to an allowed registry"))
+
+		response = handle(admissionv1.Create, newObject("BuildRun", outputSpec("someone/sample")), nil)
+		Expect(response.Allowed).To(BeFalse())
+	})
+
+	It("should admit the objects of platform namespaces", func() {
+		namespace = "openshift-config"
+		response := handle(admissionv1.Create, newObject("Build", outputSpec("docker.io/someone/sample")), nil)
+		Expect(response.Allowed).To(BeTrue())
+	})
+
+	It("should allow the registries of the exceptions matching the namespace", func() {
+		objects[1].SetLabels(map[string]string{"team": "oss"})
+		response := handle(admissionv1.Create, newObject("Build", outputSpec("ghcr.io/acme/sample")), nil)
+		Expect(response.Allowed).To(BeTrue())
+	})
+
+	It("should check BuildRuns against the output of the referenced Build", func() {
+		build := &unstructured.Unstructured{Object: newObject("Build", outputSpec("docker.io/someone/sample"))}
+		objects = append(objects, build)
+		buildRun := newObject("BuildRun", map[string]interface{}{"build": map[string]interface{}{"name": "sample"}})
+		response := handle(admissionv1.Create, buildRun, nil)
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Message).To(ContainSubstring("Build sample spec.output.image"))
+
+		buildRun["spec"].(map[string]interface{})["output"] = map[string]interface{}{"image": "quay.io/acme/sample"}
+		response = handle(admissionv1.Create, buildRun, nil)
+		Expect(response.Allowed).To(BeTrue())
+	})
+
+	It("should allow updates keeping the output image", func() {
+		build := newObject("Build", outputSpec("docker.io/someone/sample"))
+		old := newObject("Build", outputSpec("docker.io/someone/sample"))
+		build["metadata"].(map[string]interface{})["labels"] = map[string]interface{}{"app": "sample"}
+		response := handle(admissionv1.Update, build, old)
+		Expect(response.Allowed).To(BeTrue())
+
+		old = newObject("Build", outputSpec("quay.io/acme/sample"))
+		response = handle(admissionv1.Update, build, old)
+		Expect(response.Allowed).To(BeFalse())
+	})
+})
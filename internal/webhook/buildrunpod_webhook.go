@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/queue"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/userns"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// BuildRunPodWebhookPath is the path serving the build pod mutating webhook
+const BuildRunPodWebhookPath = "/mutate-v1-pod-buildrun"
+
+//+kubebuilder:webhook:path=/mutate-v1-pod-buildrun,mutating=true,failurePolicy=fail,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mbuildrunpod.operator.openshift.io,admissionReviewVersions=v1
+
+// BuildRunPodGate queues the pods of new BuildRuns when the OpenShiftBuild limits the number of
+// running BuildRuns. Queued pods are released by the BuildRun queue controller. The pods of the
+// buildah-userns strategy are run in a user namespace. The webhook fails closed, so that build pods
+// never bypass the limits; it is restricted to the pods of BuildRuns outside the platform namespaces.
+type BuildRunPodGate struct {
+	Client client.Reader
+}
+
+var _ admission.Handler = &BuildRunPodGate{}
+
+// SetupBuildRunPodWebhookWithManager registers the build pod mutating webhook
+func SetupBuildRunPodWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(BuildRunPodWebhookPath, &webhook.Admission{
+		Handler: &BuildRunPodGate{
+			Client: mgr.GetClient(),
+		},
+	})
+	return nil
+}
+
+// Handle adds the scheduling gate of the operator to the pods of BuildRuns, and runs the ones
+// requiring the user namespace SCC in a user namespace. Other pods are admitted unchanged.
+func (g *BuildRunPodGate) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if common.IsPlatformNamespace(req.Namespace) {
+		return admission.Allowed("")
+	}
+	pod := &corev1.Pod{}
+	if err := json.Unmarshal(req.Object.Raw, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if !queue.IsBuildPod(pod) {
+		return admission.Allowed("")
+	}
+	mutated := userns.IsolatePod(pod)
+
+	owner := &openshiftv1alpha1.OpenShiftBuild{}
+	err := g.Client.Get(ctx, client.ObjectKey{Name: common.OpenShiftBuildResourceName}, owner)
+	if client.IgnoreNotFound(err) != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if err == nil && queue.IsEnabled(owner) {
+		queue.Gate(pod)
+		mutated = true
+	}
+	if !mutated {
+		return admission.Allowed("")
+	}
+
+	gated, err := json.Marshal(pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, gated)
+}
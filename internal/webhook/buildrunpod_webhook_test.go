@@ -0,0 +1,97 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/redhat-openshift-builds/operator/internal/webhook"
+)
+
+var _ = Describe("BuildRunPodGate", Label("webhook", "queue"), func() {
+	var (
+		ctx   context.Context
+		owner *openshiftv1alpha1.OpenShiftBuild
+		pod   *corev1.Pod
+	)
+
+	handle := func() admission.Response {
+		scheme := runtime.NewScheme()
+		Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+		gate := &webhook.BuildRunPodGate{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(owner).Build()}
+		raw, err := json.Marshal(pod)
+		Expect(err).ShouldNot(HaveOccurred())
+		return gate.Handle(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Namespace: pod.Namespace,
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		owner = &openshiftv1alpha1.OpenShiftBuild{
+			ObjectMeta: metav1.ObjectMeta{Name: common.OpenShiftBuildResourceName},
+			Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+				Shipwright: &openshiftv1alpha1.Shipwright{
+					Build: &openshiftv1alpha1.ShipwrightBuild{
+						State:       openshiftv1alpha1.Enabled,
+						Concurrency: &openshiftv1alpha1.BuildRunConcurrency{State: openshiftv1alpha1.Enabled},
+					},
+				},
+			},
+		}
+		pod = &corev1.Pod{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "sample-buildrun-pod",
+				Namespace: "tenant",
+				Labels:    map[string]string{common.BuildRunNameLabel: "sample-buildrun"},
+			},
+		}
+	})
+
+	It("should queue the pods of BuildRuns", func() {
+		response := handle()
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Patches).To(HaveLen(1))
+		Expect(response.Patches[0].Path).To(Equal("/spec/schedulingGates"))
+		Expect(response.Patches[0].Value).To(Equal([]interface{}{
+			map[string]interface{}{"name": common.BuildRunSchedulingGate},
+		}))
+	})
+
+	It("should admit other pods unchanged", func() {
+		pod.Labels = nil
+		response := handle()
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Patches).To(BeEmpty())
+	})
+
+	It("should admit the pods of BuildRuns unchanged when the limits are disabled", func() {
+		owner.Spec.Shipwright.Build.Concurrency.State = openshiftv1alpha1.Disabled
+		response := handle()
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Patches).To(BeEmpty())
+	})
+
+	It("should run the pods requiring the user namespace SCC in a user namespace", func() {
+		owner.Spec.Shipwright.Build.Concurrency.State = openshiftv1alpha1.Disabled
+		pod.Annotations = map[string]string{"openshift.io/required-scc": common.UserNamespaceSCCName}
+		response := handle()
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Patches).To(HaveLen(1))
+		Expect(response.Patches[0].Path).To(Equal("/spec/hostUsers"))
+		Expect(response.Patches[0].Value).To(BeFalse())
+	})
+})
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build/strategy"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// BuildStrategyWebhookPath is the path serving the build strategy validating webhook
+const BuildStrategyWebhookPath = "/validate-shipwright-io-buildstrategy"
+
+//+kubebuilder:webhook:path=/validate-shipwright-io-buildstrategy,mutating=false,failurePolicy=fail,sideEffects=None,groups=shipwright.io,resources=buildstrategies;clusterbuildstrategies,verbs=create;update,versions=v1alpha1;v1beta1,name=vbuildstrategy.operator.openshift.io,admissionReviewVersions=v1
+
+// BuildStrategyValidator rejects ClusterBuildStrategy and BuildStrategy objects violating the
+// strategy security policy of the OpenShiftBuild
+type BuildStrategyValidator struct {
+	Client client.Reader
+	// OperatorUsername is the user of the operator, whose strategies are trusted
+	OperatorUsername string
+}
+
+var _ admission.Handler = &BuildStrategyValidator{}
+
+// SetupBuildStrategyWebhookWithManager registers the build strategy validating webhook. The
+// strategies are handled as unstructured objects, since the operator does not depend on the
+// Shipwright Build API.
+func SetupBuildStrategyWebhookWithManager(mgr ctrl.Manager, operatorUsername string) error {
+	mgr.GetWebhookServer().Register(BuildStrategyWebhookPath, &webhook.Admission{
+		Handler: &BuildStrategyValidator{
+			Client:           mgr.GetAPIReader(),
+			OperatorUsername: operatorUsername,
+		},
+	})
+	return nil
+}
+
+// OperatorUsername returns the user name of the service account of the operator
+func OperatorUsername(namespace, serviceAccount string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccount)
+}
+
+// Handle allows the strategy if it was submitted by the operator or complies with the policy
+func (v *BuildStrategyValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if common.IsPlatformNamespace(req.Namespace) {
+		return admission.Allowed("")
+	}
+	if v.OperatorUsername != "" && req.UserInfo.Username == v.OperatorUsername {
+		return admission.Allowed("")
+	}
+
+	object := &unstructured.Unstructured{}
+	if err := object.UnmarshalJSON(req.Object.Raw); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	owner := &openshiftv1alpha1.OpenShiftBuild{}
+	err := v.Client.Get(ctx, client.ObjectKey{Name: common.OpenShiftBuildResourceName}, owner)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if apierrors.IsNotFound(err) {
+		owner = nil
+	}
+
+	if violations := strategy.PolicyViolations(object, strategy.Policy(owner)); len(violations) > 0 {
+		return admission.Denied(fmt.Sprintf("%s %s violates the build strategy security policy: %s",
+			object.GetKind(), object.GetName(), strings.Join(violations, "; ")))
+	}
+	return admission.Allowed("")
+}
@@ -0,0 +1,164 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/redhat-openshift-builds/operator/internal/webhook"
+)
+
+var _ = Describe("BuildStrategyValidator", Label("webhook", "strategy"), func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		validator  *webhook.BuildStrategyValidator
+		step       map[string]interface{}
+		volumes    []interface{}
+		username   string
+	)
+
+	handle := func() admission.Response {
+		object := map[string]interface{}{
+			"apiVersion": "shipwright.io/v1beta1",
+			"kind":       "BuildStrategy",
+			"metadata":   map[string]interface{}{"name": "kaniko", "namespace": "team-a"},
+			"spec": map[string]interface{}{
+				"steps":   []interface{}{step},
+				"volumes": volumes,
+			},
+		}
+		raw, err := json.Marshal(object)
+		Expect(err).ShouldNot(HaveOccurred())
+		return validator.Handle(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+			UserInfo:  authenticationv1.UserInfo{Username: username},
+		}})
+	}
+
+	setPolicy := func(policy *openshiftv1alpha1.StrategyPolicy) {
+		owner := &openshiftv1alpha1.OpenShiftBuild{
+			ObjectMeta: metav1.ObjectMeta{Name: common.OpenShiftBuildResourceName},
+			Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+				Shipwright: &openshiftv1alpha1.Shipwright{
+					Build: &openshiftv1alpha1.ShipwrightBuild{StrategyPolicy: policy},
+				},
+			},
+		}
+		Expect(fakeClient.Create(ctx, owner)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+		validator = &webhook.BuildStrategyValidator{
+			Client:           fakeClient,
+			OperatorUsername: webhook.OperatorUsername("openshift-builds", "openshift-builds-operator"),
+		}
+		step = map[string]interface{}{
+			"name":  "build-and-push",
+			"image": "gcr.io/kaniko-project/executor:latest",
+		}
+		volumes = nil
+		username = "developer"
+	})
+
+	When("there is no OpenShiftBuild", func() {
+		It("should allow a strategy following the default policy", func() {
+			Expect(handle().Allowed).To(BeTrue())
+		})
+
+		It("should reject privileged steps", func() {
+			step["securityContext"] = map[string]interface{}{"privileged": true}
+			response := handle()
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(Equal("BuildStrategy kaniko violates the build strategy security policy: " +
+				"spec.steps[0] must not run privileged"))
+		})
+
+		It("should reject host path volumes", func() {
+			volumes = []interface{}{map[string]interface{}{"name": "host", "hostPath": map[string]interface{}{"path": "/"}}}
+			response := handle()
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(ContainSubstring("spec.volumes[0] must not mount a host path"))
+		})
+
+		It("should reject capabilities which are not allowed", func() {
+			step["securityContext"] = map[string]interface{}{
+				"capabilities": map[string]interface{}{"add": []interface{}{"SETFCAP", "SYS_ADMIN"}},
+			}
+			response := handle()
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(HaveSuffix("spec.steps[0] must not add capability SYS_ADMIN"))
+		})
+
+		It("should allow the strategies of the operator", func() {
+			step["securityContext"] = map[string]interface{}{"privileged": true}
+			username = "system:serviceaccount:openshift-builds:openshift-builds-operator"
+			Expect(handle().Allowed).To(BeTrue())
+		})
+	})
+
+	When("the OpenShiftBuild sets a strategy policy", func() {
+		BeforeEach(func() {
+			setPolicy(&openshiftv1alpha1.StrategyPolicy{
+				AllowedRegistries:     []string{"registry.redhat.io", "quay.io/buildah"},
+				AllowedCapabilities:   []string{"SETFCAP", "SETUID"},
+				RequireResourceLimits: true,
+			})
+			step["resources"] = map[string]interface{}{
+				"limits": map[string]interface{}{"cpu": "1", "memory": "1Gi"},
+			}
+		})
+
+		It("should allow images of the allowed registries", func() {
+			step["image"] = "quay.io/buildah/stable:latest"
+			step["securityContext"] = map[string]interface{}{
+				"capabilities": map[string]interface{}{"add": []interface{}{"CAP_SETUID"}},
+			}
+			Expect(handle().Allowed).To(BeTrue())
+		})
+
+		It("should reject images of other registries", func() {
+			step["image"] = "quay.io/buildahx/stable:latest"
+			response := handle()
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(ContainSubstring(
+				"spec.steps[0] image quay.io/buildahx/stable:latest is not pulled from an allowed registry (registry.redhat.io, quay.io/buildah)"))
+		})
+
+		It("should reject images without registry", func() {
+			step["image"] = "ubuntu"
+			Expect(handle().Allowed).To(BeFalse())
+		})
+
+		It("should reject images set from a parameter", func() {
+			step["image"] = "$(params.builder-image)"
+			response := handle()
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(ContainSubstring("must not be set from a parameter"))
+		})
+
+		It("should reject steps without resource limits", func() {
+			step["image"] = "registry.redhat.io/ubi9/buildah:latest"
+			step["resources"] = map[string]interface{}{"limits": map[string]interface{}{"cpu": "1"}}
+			response := handle()
+			Expect(response.Allowed).To(BeFalse())
+			Expect(response.Result.Message).To(HaveSuffix("spec.steps[0] must set cpu and memory limits"))
+		})
+	})
+})
@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/validate-operator-shipwright-io-v1alpha1-shipwrightbuild,mutating=false,failurePolicy=fail,sideEffects=None,groups=operator.shipwright.io,resources=shipwrightbuilds,verbs=create,versions=v1alpha1,name=vshipwrightbuild.operator.openshift.io,admissionReviewVersions=v1
+
+// ShipwrightBuildValidator rejects ShipwrightBuild objects which are not controlled by the
+// OpenShiftBuild, as they would compete with the Shipwright Build release managed by the operator
+type ShipwrightBuildValidator struct{}
+
+var _ admission.CustomValidator = &ShipwrightBuildValidator{}
+
+// SetupShipwrightBuildWebhookWithManager registers the ShipwrightBuild validating webhook
+func SetupShipwrightBuildWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&shipwrightv1alpha1.ShipwrightBuild{}).
+		WithValidator(&ShipwrightBuildValidator{}).
+		Complete()
+}
+
+// ValidateCreate rejects the ShipwrightBuild unless it is controlled by an OpenShiftBuild
+func (v *ShipwrightBuildValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	object, ok := obj.(*shipwrightv1alpha1.ShipwrightBuild)
+	if !ok {
+		return nil, fmt.Errorf("expected a ShipwrightBuild but got %T", obj)
+	}
+	owner := &metav1.OwnerReference{
+		APIVersion: openshiftv1alpha1.GroupVersion.String(),
+		Kind:       "OpenShiftBuild",
+	}
+	if !common.IsControlledBy(object, owner) {
+		return nil, fmt.Errorf("ShipwrightBuild must be controlled by the OpenShiftBuild %q: "+
+			"Shipwright Build is configured through spec.shipwright.build of the OpenShiftBuild",
+			common.OpenShiftBuildResourceName)
+	}
+	return nil, nil
+}
+
+// ValidateUpdate allows all updates, so that existing ShipwrightBuilds can be adopted or removed
+func (v *ShipwrightBuildValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateDelete allows all deletions
+func (v *ShipwrightBuildValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,74 @@
+package webhook_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/utils/ptr"
+
+	"github.com/redhat-openshift-builds/operator/internal/webhook"
+)
+
+var _ = Describe("ShipwrightBuildValidator", Label("webhook", "shipwright"), func() {
+	var (
+		ctx       context.Context
+		validator *webhook.ShipwrightBuildValidator
+		object    *shipwrightv1alpha1.ShipwrightBuild
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		validator = &webhook.ShipwrightBuildValidator{}
+		object = &shipwrightv1alpha1.ShipwrightBuild{}
+		object.SetGenerateName(common.OpenShiftBuildResourceName + "-")
+	})
+
+	When("the ShipwrightBuild is controlled by the OpenShiftBuild", func() {
+		It("should allow the creation", func() {
+			object.SetOwnerReferences([]metav1.OwnerReference{{
+				APIVersion: openshiftv1alpha1.GroupVersion.String(),
+				Kind:       "OpenShiftBuild",
+				Name:       common.OpenShiftBuildResourceName,
+				UID:        uuid.NewUUID(),
+				Controller: ptr.To(true),
+			}})
+			_, err := validator.ValidateCreate(ctx, object)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	When("the ShipwrightBuild has no controller", func() {
+		It("should reject the creation", func() {
+			_, err := validator.ValidateCreate(ctx, object)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	When("the ShipwrightBuild is only owned by the OpenShiftBuild", func() {
+		It("should reject the creation", func() {
+			object.SetOwnerReferences([]metav1.OwnerReference{{
+				APIVersion: openshiftv1alpha1.GroupVersion.String(),
+				Kind:       "OpenShiftBuild",
+				Name:       common.OpenShiftBuildResourceName,
+				UID:        uuid.NewUUID(),
+			}})
+			_, err := validator.ValidateCreate(ctx, object)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	When("an existing ShipwrightBuild is updated or deleted", func() {
+		It("should allow the request", func() {
+			_, err := validator.ValidateUpdate(ctx, object, object)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = validator.ValidateDelete(ctx, object)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+	})
+})